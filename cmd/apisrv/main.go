@@ -1,36 +1,34 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/linkuha/test-golang-rest-orders-api/config"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/app"
+	"os"
 )
 
 func main() {
-	flags := initFlags()
-	cfg := config.InitConfig(flags)
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	printConfig := flags.Bool("print-config", false, "print the configuration with redacted secrets and exit")
 
-	app.Run(cfg)
-}
+	cfg, err := config.Load(flags, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-func initFlags() config.AppFlags {
-	flags := config.AppFlags{}
-	flag.Var(
-		&flags.LogDir,
-		"logdir",
-		fmt.Sprintf("Path to log directory (default: %s, can be replaced by APP_LOG_DIR env)", config.DefaultLogDir),
-	)
-	flag.Var(
-		&flags.LogLevel,
-		"loglevel",
-		fmt.Sprintf("Log level (default: %s)", config.DefaultLogLevel),
-	)
-	flag.Var(
-		&flags.ConfigPath,
-		"config",
-		fmt.Sprintf("Path to config (default: %s, can be replaced by APP_CONFIG_PATH env)", config.DefaultConfigPath),
-	)
-	flag.Parse()
-	return flags
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	app.Run(cfg)
 }
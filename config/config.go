@@ -1,227 +1,193 @@
 package config
 
 import (
-	"github.com/ilyakaznacheev/cleanenv"
-	"github.com/spf13/viper"
-	"os"
-	"path/filepath"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
 )
 
 const (
-	Version           = "1.0.0beta"
-	DefaultLogDir     = "/var/log"
-	DefaultConfigPath = "./config/config.yml"
-	DefaultLogLevel   = "info"
+	EnvDevelopment = "development"
+	EnvTesting     = "testing"
+	EnvProduction  = "production"
 )
 
-type AppFlags struct {
-	LogDir     stringFlag
-	LogLevel   stringFlag
-	ConfigPath stringFlag
-}
-
-type EnvParams struct {
-	FileLoaded  bool
-	Env         string `mapstructure:"APP_ENV" env:"APP_ENV"`
-	LogDir      string `mapstructure:"APP_LOG_DIR" env:"APP_LOG_DIR"`
-	LogLevel    string `mapstructure:"APP_LOG_LEVEL" env:"APP_LOG_LEVEL"`
-	Port        string `mapstructure:"PORT" env:"PORT"`
-	Host        string `mapstructure:"API_HOST" env:"API_HOST"`
-	PgHost      string `mapstructure:"POSTGRES_HOST" env:"POSTGRES_HOST"`
-	PgPort      string `mapstructure:"POSTGRES_PORT" env:"POSTGRES_PORT"`
-	PgUser      string `mapstructure:"POSTGRES_USER" env:"POSTGRES_USER"`
-	PgPassword  string `mapstructure:"POSTGRES_PASSWORD" env:"POSTGRES_PASSWORD"`
-	PgDB        string `mapstructure:"POSTGRES_DB" env:"POSTGRES_DB"`
-	DatabaseURL string `mapstructure:"DATABASE_URL" env:"DATABASE_URL"`
-}
-
-type FileParams struct {
-	FileLoaded bool
-	Test       string `mapstructure:"test_string"`
-}
-
-type MergedParams struct {
-	LogInFile  bool
-	LogDir     string
-	LogLevel   string
-	ConfigPath string
-}
-
+// Config of the application. Every value is taken from, in the order of precedence:
+// the flag (-server.port), the environment (PORT, the .env file too), the config file (server.port) and the default.
+//
+// Tags of leaves: yaml - the key in the file, the flag is the path of keys; env - the variable;
+// default - the value without others; flag - the short alias of the flag; secret - the value is redacted in dumps.
 type Config struct {
-	EnvParams        EnvParams
-	FileParams       FileParams
-	ConfigFileLoaded bool
-	flags            AppFlags
-	Merged           MergedParams
+	App      AppConfig      `yaml:"app"`
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Log      LogConfig      `yaml:"log"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	Features FeaturesConfig `yaml:"features"`
+
+	// Sources are the files, which were loaded.
+	Sources Sources `yaml:"-"`
+}
+
+type Sources struct {
+	EnvFile    string
+	ConfigFile string
+}
+
+type AppConfig struct {
+	// Env - development, testing or production.
+	Env string `yaml:"env" env:"APP_ENV" default:"development"`
+	// ShutdownDrainDelay - readiness fails this time before the server stops, load balancers take the instance out.
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port" env:"PORT" default:"80"`
+	// Host is the address of API in Swagger.
+	Host string `yaml:"host" env:"API_HOST"`
+	// InternalAddr of the listener with status, metrics, pprof and probes; they are on the public port without it.
+	InternalAddr string    `yaml:"internal_addr" env:"INTERNAL_ADDR"`
+	H2C          bool      `yaml:"h2c" env:"HTTP_H2C"`
+	TLS          TLSConfig `yaml:"tls"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" default:"10s"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" default:"5s"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" default:"2m"`
+	// RequestTimeout - the deadline of request processing, LongRequestTimeout is for import, export and images.
+	RequestTimeout     time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT" default:"5s"`
+	LongRequestTimeout time.Duration `yaml:"long_request_timeout" env:"LONG_REQUEST_TIMEOUT" default:"1m"`
+	ReadinessTimeout   time.Duration `yaml:"readiness_timeout" env:"READINESS_TIMEOUT" default:"2s"`
+}
+
+// TLSConfig of the public listener, it's plain HTTP behind the gateway without the certificate.
+type TLSConfig struct {
+	CertFile     string   `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile      string   `yaml:"key_file" env:"TLS_KEY_FILE"`
+	ClientCAFile string   `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	MinVersion   string   `yaml:"min_version" env:"TLS_MIN_VERSION" default:"1.2"`
+	CipherSuites []string `yaml:"cipher_suites" env:"TLS_CIPHER_SUITES"`
+}
+
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+type DatabaseConfig struct {
+	// Driver of repositories - postgres or memory (lost on restart, for local runs without database).
+	Driver string `yaml:"driver" env:"REPOSITORY_DRIVER" default:"postgres"`
+	// URL is used instead of the separate parameters, if it's set.
+	URL      string `yaml:"url" env:"DATABASE_URL" secret:"true"`
+	Host     string `yaml:"host" env:"POSTGRES_HOST" default:"localhost"`
+	Port     string `yaml:"port" env:"POSTGRES_PORT" default:"5432"`
+	User     string `yaml:"user" env:"POSTGRES_USER"`
+	Password string `yaml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"POSTGRES_DB"`
+
+	// MaxOpenConns - 0 is unlimited. MaxIdleConns are kept open between requests.
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"10"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+	// StatementTimeout - the server cancels longer queries, 0 is the setting of the server.
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	// ApplicationName is shown in pg_stat_activity.
+	ApplicationName string `yaml:"application_name" env:"DB_APPLICATION_NAME" default:"orders-api"`
+	// ConnectAttempts on start, the pause between them begins with ConnectBackoff and doubles.
+	ConnectAttempts int           `yaml:"connect_attempts" env:"DB_CONNECT_ATTEMPTS" default:"10"`
+	ConnectBackoff  time.Duration `yaml:"connect_backoff" env:"DB_CONNECT_BACKOFF" default:"1s"`
+	// MonitorInterval - the connection is checked in background, readiness fails while it's lost.
+	MonitorInterval time.Duration `yaml:"monitor_interval" env:"DB_MONITOR_INTERVAL" default:"10s"`
+}
+
+// DSN - URL form, it's understood by the driver and by migrations.
+func (c DatabaseConfig) DSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, c.Port),
+		Path:     "/" + c.Name,
+		RawQuery: "sslmode=disable",
+	}
+	return u.String()
 }
 
-type stringFlag struct {
-	set   bool
-	value string
+type AuthConfig struct {
+	// AdminUserIDs - users, who may see the status of the application on the public port.
+	AdminUserIDs []string `yaml:"admin_user_ids" env:"ADMIN_USER_IDS"`
+	// TokenSigningKey of access tokens, the built-in development key is used without it.
+	TokenSigningKey string        `yaml:"token_signing_key" env:"AUTH_TOKEN_SIGNING_KEY" secret:"true"`
+	TokenTTL        time.Duration `yaml:"token_ttl" env:"AUTH_TOKEN_TTL" default:"12h"`
 }
 
-type boolFlag struct {
-	set   bool
-	value bool
+type LogConfig struct {
+	// Level - trace, debug, info, warn or error.
+	Level string `yaml:"level" env:"APP_LOG_LEVEL" flag:"loglevel" default:"info"`
+	// Format of stdout - json or console (human readable).
+	Format string `yaml:"format" env:"APP_LOG_FORMAT" default:"json"`
+	// Dir of main.log, if the file is enabled.
+	Dir  string        `yaml:"dir" env:"APP_LOG_DIR" flag:"logdir" default:"/var/log"`
+	File LogFileConfig `yaml:"file"`
 }
 
-func (sf *stringFlag) Set(x string) error {
-	sf.value = x
-	sf.set = true
-	return nil
+type LogFileConfig struct {
+	Enabled bool   `yaml:"enabled" env:"LOG_IN_FILE_ENABLED"`
+	Format  string `yaml:"format" env:"APP_LOG_FILE_FORMAT" default:"json"`
+	// MaxSize in megabytes and RotateEvery rotate the file, MaxBackups and MaxAge in days remove old ones; 0 - disabled.
+	MaxSize     int           `yaml:"max_size" env:"APP_LOG_FILE_MAX_SIZE" default:"100"`
+	MaxBackups  int           `yaml:"max_backups" env:"APP_LOG_FILE_MAX_BACKUPS"`
+	MaxAge      int           `yaml:"max_age" env:"APP_LOG_FILE_MAX_AGE"`
+	RotateEvery time.Duration `yaml:"rotate_every" env:"APP_LOG_FILE_ROTATE_EVERY"`
+	Compress    bool          `yaml:"compress" env:"APP_LOG_FILE_COMPRESS"`
 }
 
-func (sf *stringFlag) String() string {
-	return sf.value
+type StorageConfig struct {
+	// Driver of uploaded files - local (served by the application under PublicURL) or s3.
+	Driver    string `yaml:"driver" env:"STORAGE_DRIVER" default:"local"`
+	LocalDir  string `yaml:"local_dir" env:"STORAGE_LOCAL_DIR" default:"./media"`
+	PublicURL string `yaml:"public_url" env:"STORAGE_PUBLIC_URL" default:"/media"`
+	// UploadMaxSize and ImportMaxSize (file of products) in bytes.
+	UploadMaxSize int64    `yaml:"upload_max_size" env:"UPLOAD_MAX_SIZE" default:"5242880"`
+	ImportMaxSize int64    `yaml:"import_max_size" env:"IMPORT_MAX_SIZE" default:"10485760"`
+	S3            S3Config `yaml:"s3"`
 }
 
-func (sf *boolFlag) Set(x bool) error {
-	sf.value = x
-	sf.set = true
-	return nil
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region    string `yaml:"region" env:"S3_REGION"`
+	Bucket    string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKey string `yaml:"access_key" env:"S3_ACCESS_KEY" secret:"true"`
+	SecretKey string `yaml:"secret_key" env:"S3_SECRET_KEY" secret:"true"`
 }
 
-func (sf *boolFlag) String() string {
-	if sf.value == true {
-		return "true"
-	}
-	return "false"
-}
-
-func InitConfig(flags AppFlags) *Config {
-	currDir := getCurrentDir()
-	configPath := getConfigPath(currDir, flags)
-
-	env, _ := loadEnvFile(currDir)
-	yaml, _ := loadYmlFile(configPath)
-	cfg := &Config{
-		EnvParams:  env,
-		FileParams: yaml,
-		flags:      flags,
-		Merged: MergedParams{
-			LogInFile:  getLogInFile(),
-			LogLevel:   getLogLevel(env, flags),
-			LogDir:     getLogDir(env, flags),
-			ConfigPath: configPath,
-		},
-	}
-
-	return cfg
+type TracingConfig struct {
+	// Exporter - none, stdout (for local runs) or otlp (OTLP/HTTP collector).
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER" default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" default:"orders-api"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG" default:"1"`
 }
 
-func getCurrentDir() string {
-	var dir string
-	currentPath, err := os.Getwd()
-	if err != nil {
-		panic("Cant read current path")
-	}
-	dir = filepath.Dir(currentPath)
-	return dir
+type FeaturesConfig struct {
+	DisableSwagger bool `yaml:"disable_swagger" env:"DISABLE_SWAGGER_HTTP_HANDLER"`
+	// IdempotencyTTL - how long responses are replayed for retries with the same Idempotency-Key.
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env:"IDEMPOTENCY_TTL" default:"24h"`
 }
 
-func loadEnvFile(dir string) (config EnvParams, err error) {
-	viper.AddConfigPath(dir)
-	viper.SetConfigFile(".env")
-
-	viper.AutomaticEnv()
-	viper.AllowEmptyEnv(true)
-
-	readErr := viper.ReadInConfig()
-
-	if err = viper.Unmarshal(&config); err != nil {
-		// if key not present in .env file - then it will not be loaded from environment to struct
-		panic("Fail mapping configuration parameters (env)")
-	}
-	if readErr != nil {
-		// its': config == (EnvParams{})
-		// try load all from natural environment
-		// we don't want to use viper.Get("APP_ENV") because you need to remember all the variables
-		// we need the config's IDE autocompleting
-		if err := cleanenv.ReadEnv(&config); err != nil {
-			panic("Fail mapping env params structure: " + err.Error())
-		}
-	} else {
-		config.FileLoaded = true
-	}
-
-	return config, nil
+// Config is printed with redacted secrets, it may go to logs as a whole.
+func (c Config) String() string {
+	return fmt.Sprintf("%+v", plainConfig(Redact(c)))
 }
 
-func loadYmlFile(configPath string) (config FileParams, err error) {
-	viper.SetConfigFile(configPath)
-
-	viper.SetDefault("test_string", "qwerty")
-
-	readErr := viper.ReadInConfig()
-
-	if err = viper.Unmarshal(&config); err != nil {
-		panic("Fail mapping configuration parameters (yaml)")
-	}
-	if readErr == nil {
-		config.FileLoaded = true
-	}
-
-	return config, nil
+func (c Config) GoString() string {
+	return "config.Config" + strings.TrimPrefix(fmt.Sprintf("%#v", plainConfig(Redact(c))), "config.plainConfig")
 }
 
-func getConfigPath(currDir string, flags AppFlags) string {
-	var configPath string
-	if os.Getenv("APP_CONFIG_PATH") != "" {
-		configPath = os.Getenv("APP_CONFIG_PATH")
-	} else {
-		if flags.ConfigPath.set == true {
-			configPath = flags.ConfigPath.value
-		} else {
-			configPath = filepath.Join(currDir, DefaultConfigPath)
-		}
-	}
-	return configPath
-}
-
-func getLogDir(env EnvParams, flags AppFlags) string {
-	var logDir string
-	if env.LogDir != "" {
-		logDir = env.LogDir
-	} else {
-		if flags.LogDir.set == true {
-			logDir = flags.LogDir.value
-		} else {
-			logDir = DefaultLogDir
-		}
-	}
-	return logDir
-}
-
-func getLogInFile() bool {
-	switch os.Getenv("LOG_IN_FILE_ENABLED") {
-	case "":
-		fallthrough
-	case "false":
-		fallthrough
-	case "0":
-		fallthrough
-	case "no":
-		return false
-	case "1":
-		fallthrough
-	case "true":
-		fallthrough
-	case "yes":
-		return true
-	}
-	return false
-}
-
-func getLogLevel(env EnvParams, flags AppFlags) string {
-	var logLevel string
-	if env.LogLevel != "" {
-		logLevel = env.LogLevel
-	} else {
-		if flags.LogLevel.set == true {
-			logLevel = flags.LogLevel.value
-		} else {
-			logLevel = DefaultLogLevel
-		}
-	}
-	return logLevel
-}
+// plainConfig - Config without Stringer, fmt would call it again.
+type plainConfig Config
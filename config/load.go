@@ -0,0 +1,289 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultConfigPath = "config/config.yml"
+	DefaultEnvPath    = ".env"
+	// ConfigPathEnv replaces the default path of the config file, the flag -config replaces both.
+	ConfigPathEnv = "APP_CONFIG_PATH"
+)
+
+// Load registers flags of the config in fs, parses args and loads the config from all sources.
+// The error is *InvalidError, if values are invalid, or flag.ErrHelp for -help.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg := &Config{}
+	leaves := collectLeaves(reflect.ValueOf(cfg).Elem(), "")
+
+	configPath := fs.String("config", "", fmt.Sprintf("path to the config file (default %s, env %s)", DefaultConfigPath, ConfigPathEnv))
+	for _, l := range leaves {
+		fs.Var(l, l.path, l.usage())
+		if l.alias != "" {
+			fs.Var(l, l.alias, "alias of -"+l.path)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	invalid := &InvalidError{}
+	for _, l := range leaves {
+		if err := l.setDefault(); err != nil {
+			// the mistake of the developer
+			panic(fmt.Sprintf("config: default of %s: %s", l.path, err))
+		}
+	}
+
+	env, err := readEnvFile(DefaultEnvPath)
+	if err != nil {
+		return nil, err
+	}
+	if env != nil {
+		cfg.Sources.EnvFile = DefaultEnvPath
+	}
+	// empty variables are placeholders (e.g. in .env or docker compose), they don't replace other sources
+	lookupEnv := func(key string) string {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+		return env[key]
+	}
+
+	path := *configPath
+	if path == "" {
+		path = lookupEnv(ConfigPathEnv)
+	}
+	required := path != ""
+	if !required {
+		path = DefaultConfigPath
+	}
+	loaded, err := readConfigFile(cfg, path, required)
+	if err != nil {
+		return nil, err
+	}
+	if loaded {
+		cfg.Sources.ConfigFile = path
+	}
+
+	for _, l := range leaves {
+		if v := lookupEnv(l.env); l.env != "" && v != "" {
+			if err := l.parse(v); err != nil {
+				invalid.add(l.path, l.env, err)
+			}
+		}
+	}
+	for _, l := range leaves {
+		if l.flagValue != nil {
+			if err := l.parse(*l.flagValue); err != nil {
+				invalid.add("-"+l.path, "", err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		invalid.addValidation(err, reflect.TypeOf(*cfg), "", "")
+	}
+	if len(invalid.Fields) > 0 {
+		sort.SliceStable(invalid.Fields, func(i, j int) bool { return invalid.Fields[i].Path < invalid.Fields[j].Path })
+		return nil, invalid
+	}
+	return cfg, nil
+}
+
+// Print the config as the file with redacted secrets.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(Redact(c)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// readEnvFile - variables of the process replace them. The file is optional, nil without it.
+func readEnvFile(path string) (map[string]string, error) {
+	env, err := godotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return env, nil
+}
+
+// readConfigFile - unknown keys are errors, they are typos usually. The required file must exist.
+func readConfigFile(cfg *Config, path string, required bool) (bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// leaf is the field with value, it's the flag too.
+type leaf struct {
+	value     reflect.Value
+	path      string
+	env       string
+	alias     string
+	def       string
+	flagValue *string
+}
+
+func collectLeaves(v reflect.Value, path string) []*leaf {
+	var res []*leaf
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "-" {
+			continue
+		}
+		fieldPath := joinPath(path, name)
+		if field.Type.Kind() == reflect.Struct {
+			res = append(res, collectLeaves(v.Field(i), fieldPath)...)
+			continue
+		}
+		res = append(res, &leaf{
+			value: v.Field(i),
+			path:  fieldPath,
+			env:   field.Tag.Get("env"),
+			alias: field.Tag.Get("flag"),
+			def:   field.Tag.Get("default"),
+		})
+	}
+	return res
+}
+
+func (l *leaf) usage() string {
+	var parts []string
+	if l.env != "" {
+		parts = append(parts, "env "+l.env)
+	}
+	if l.def != "" {
+		parts = append(parts, "default "+l.def)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (l *leaf) setDefault() error {
+	if l.def == "" {
+		return nil
+	}
+	return l.parse(l.def)
+}
+
+// String and Set implement flag.Value, the value is applied after the environment.
+func (l *leaf) String() string {
+	if l == nil || l.flagValue == nil {
+		return ""
+	}
+	return *l.flagValue
+}
+
+func (l *leaf) Set(s string) error {
+	l.flagValue = &s
+	return nil
+}
+
+// IsBoolFlag allows -server.h2c without the value.
+func (l *leaf) IsBoolFlag() bool {
+	return l.value.Kind() == reflect.Bool
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (l *leaf) parse(s string) error {
+	v := l.value
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.New("must be a duration, e.g. 5s or 1m")
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := parseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		// comma separated
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// parseBool - yes and no are allowed too, they were used by LOG_IN_FILE_ENABLED.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, errors.New("must be a boolean: true or false")
+}
+
+func yamlName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"github.com/stretchr/testify/require"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// isolate runs the test in the empty directory without variables of the config.
+func isolate(t *testing.T) string {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	// empty variables are ignored
+	for _, l := range collectLeaves(reflect.ValueOf(&Config{}).Elem(), "") {
+		if l.env != "" {
+			t.Setenv(l.env, "")
+		}
+	}
+	t.Setenv(ConfigPathEnv, "")
+	return dir
+}
+
+// withDatabase - user and name of the database have no defaults.
+func withDatabase(t *testing.T) {
+	t.Setenv("POSTGRES_USER", "app")
+	t.Setenv("POSTGRES_DB", "orders")
+}
+
+func load(args ...string) (*Config, error) {
+	return Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+func writeFile(t *testing.T, name, content string) {
+	require.NoError(t, os.MkdirAll("config", 0755))
+	require.NoError(t, os.WriteFile(name, []byte(content), 0644))
+}
+
+func TestLoadDefaults(t *testing.T) {
+	isolate(t)
+	withDatabase(t)
+
+	cfg, err := load()
+	require.NoError(t, err)
+	require.Equal(t, Sources{}, cfg.Sources)
+	require.Equal(t, EnvDevelopment, cfg.App.Env)
+	require.Equal(t, "80", cfg.Server.Port)
+	require.Equal(t, 5*time.Second, cfg.Server.RequestTimeout)
+	require.Equal(t, "info", cfg.Log.Level)
+	require.Equal(t, "/var/log", cfg.Log.Dir)
+	require.Equal(t, int64(5<<20), cfg.Storage.UploadMaxSize)
+	require.Equal(t, int64(10<<20), cfg.Storage.ImportMaxSize)
+	require.Equal(t, 1.0, cfg.Tracing.SampleRatio)
+	require.Equal(t, "postgres://app:@localhost:5432/orders?sslmode=disable", cfg.Database.DSN())
+
+	t.Setenv("POSTGRES_USER", "")
+	_, err = load()
+	require.ErrorContains(t, err, "database.user (POSTGRES_USER): cannot be blank")
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	isolate(t)
+	withDatabase(t)
+	writeFile(t, DefaultConfigPath, `
+server:
+  port: "4000"
+  request_timeout: 7s
+log:
+  level: debug
+  format: console
+auth:
+  admin_user_ids: [c401f9dc-1e68-4b44-82d9-3a93b09e3fe7]
+`)
+	writeFile(t, DefaultEnvPath, "PORT=5000\nAPP_LOG_LEVEL=warn\nLOG_IN_FILE_ENABLED=yes\nAPP_LOG_DIR=\n")
+	t.Setenv("PORT", "6000")
+
+	cfg, err := load("-loglevel", "error")
+	require.NoError(t, err)
+	require.Equal(t, Sources{EnvFile: DefaultEnvPath, ConfigFile: DefaultConfigPath}, cfg.Sources)
+	require.Equal(t, "6000", cfg.Server.Port, "the process replaces .env")
+	require.Equal(t, 7*time.Second, cfg.Server.RequestTimeout, "the file replaces the default")
+	require.Equal(t, "error", cfg.Log.Level, "the flag replaces all")
+	require.Equal(t, "console", cfg.Log.Format)
+	require.True(t, cfg.Log.File.Enabled)
+	require.Equal(t, "/var/log", cfg.Log.Dir, "the empty variable doesn't replace the default")
+	require.Equal(t, []string{"c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"}, cfg.Auth.AdminUserIDs)
+
+	cfg, err = load("-server.port=7000", "-server.h2c")
+	require.NoError(t, err)
+	require.Equal(t, "7000", cfg.Server.Port)
+	require.True(t, cfg.Server.H2C)
+}
+
+func TestLoadConfigPath(t *testing.T) {
+	isolate(t)
+	writeFile(t, "custom.yml", "app:\n  env: testing\ndatabase:\n  driver: memory\n")
+
+	t.Setenv(ConfigPathEnv, "custom.yml")
+	cfg, err := load()
+	require.NoError(t, err)
+	require.Equal(t, EnvTesting, cfg.App.Env)
+
+	_, err = load("-config", "absent.yml")
+	require.Error(t, err, "the explicit file must exist")
+
+	writeFile(t, "typo.yml", "server:\n  prot: 3000\n")
+	_, err = load("-config", "typo.yml")
+	require.ErrorContains(t, err, "prot")
+}
+
+func TestLoadInvalid(t *testing.T) {
+	isolate(t)
+	withDatabase(t)
+	t.Setenv("PORT", "http")
+	t.Setenv("REQUEST_TIMEOUT", "soon")
+	t.Setenv("APP_LOG_LEVEL", "loud")
+	t.Setenv("ADMIN_USER_IDS", "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7,admin")
+	t.Setenv("STORAGE_DRIVER", "s3")
+	t.Setenv("TLS_KEY_FILE", "tls.key")
+	t.Setenv("TLS_MIN_VERSION", "1.1")
+	t.Setenv("HTTP_H2C", "true")
+	t.Setenv("DB_STATEMENT_TIMEOUT", "500us")
+	t.Setenv("DB_CONNECT_BACKOFF", "0s")
+
+	_, err := load("-server.idle_timeout", "-1s")
+	var invalid *InvalidError
+	require.True(t, errors.As(err, &invalid), err)
+
+	fields := map[string]string{}
+	for _, f := range invalid.Fields {
+		fields[f.Path] = f.Env
+	}
+	require.Equal(t, map[string]string{
+		"server.request_timeout":     "REQUEST_TIMEOUT",
+		"server.port":                "PORT",
+		"server.idle_timeout":        "HTTP_IDLE_TIMEOUT",
+		"server.tls.cert_file":       "TLS_CERT_FILE",
+		"server.tls.min_version":     "TLS_MIN_VERSION",
+		"server.h2c":                 "HTTP_H2C",
+		"log.level":                  "APP_LOG_LEVEL",
+		"auth.admin_user_ids[1]":     "ADMIN_USER_IDS",
+		"storage.s3.endpoint":        "S3_ENDPOINT",
+		"storage.s3.bucket":          "S3_BUCKET",
+		"database.statement_timeout": "DB_STATEMENT_TIMEOUT",
+		"database.connect_backoff":   "DB_CONNECT_BACKOFF",
+	}, fields, err.Error())
+	require.Contains(t, err.Error(), "server.port (PORT): must be a valid port number")
+}
+
+func TestLoadProductionSigningKey(t *testing.T) {
+	isolate(t)
+	withDatabase(t)
+	t.Setenv("APP_ENV", EnvProduction)
+
+	_, err := load()
+	var invalid *InvalidError
+	require.True(t, errors.As(err, &invalid), err)
+	require.Len(t, invalid.Fields, 1)
+	require.Equal(t, "auth.token_signing_key", invalid.Fields[0].Path)
+	require.Equal(t, "AUTH_TOKEN_SIGNING_KEY", invalid.Fields[0].Env)
+
+	t.Setenv("AUTH_TOKEN_SIGNING_KEY", "production-signing-key")
+	_, err = load()
+	require.NoError(t, err)
+
+	// the development key is used out of production
+	t.Setenv("APP_ENV", EnvTesting)
+	t.Setenv("AUTH_TOKEN_SIGNING_KEY", "")
+	_, err = load()
+	require.NoError(t, err)
+}
+
+func TestLoadHelp(t *testing.T) {
+	isolate(t)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&discard{})
+	_, err := Load(fs, []string{"-help"})
+	require.ErrorIs(t, err, flag.ErrHelp)
+	require.NotNil(t, fs.Lookup("server.port"))
+	require.NotNil(t, fs.Lookup("logdir"), "old flags are kept")
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"reflect"
+)
+
+const redacted = "[REDACTED]"
+
+// Redact returns the copy of the struct, non-empty string fields with tag `secret:"true"` are replaced,
+// nested structs are redacted too. Use it for every dump of the configuration.
+func Redact[T any](v T) T {
+	val := reflect.ValueOf(&v).Elem()
+	redactValue(val)
+	return v
+}
+
+func redactValue(v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		if field.Kind() == reflect.Struct {
+			redactValue(field)
+			continue
+		}
+		if t.Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+			field.SetString(redacted)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/srv/httpserver"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	logLevels   = []interface{}{"trace", "debug", "info", "warn", "error"}
+	logFormats  = []interface{}{"json", "console"}
+	nonNegative = validation.Min(time.Duration(0))
+)
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.App),
+		validation.Field(&c.Server),
+		validation.Field(&c.Database),
+		// the development signing key is public, tokens signed by it can be forged
+		validation.Field(&c.Auth, validation.By(func(interface{}) error {
+			return c.Auth.validate(c.App.Env == EnvProduction)
+		})),
+		validation.Field(&c.Log),
+		validation.Field(&c.Storage),
+		validation.Field(&c.Tracing),
+		validation.Field(&c.Features),
+	)
+}
+
+func (c AppConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Env, validation.In(EnvDevelopment, EnvTesting, EnvProduction)),
+		validation.Field(&c.ShutdownDrainDelay, nonNegative),
+	)
+}
+
+func (c ServerConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Port, validation.Required, is.Port),
+		validation.Field(&c.InternalAddr, validation.By(hostPort)),
+		validation.Field(&c.H2C, validation.Empty.When(c.TLS.Enabled()).Error("can't be used with TLS, HTTP/2 is negotiated over it")),
+		validation.Field(&c.TLS),
+		validation.Field(&c.ReadTimeout, nonNegative),
+		validation.Field(&c.ReadHeaderTimeout, nonNegative),
+		validation.Field(&c.IdleTimeout, nonNegative),
+		validation.Field(&c.RequestTimeout, validation.Required),
+		validation.Field(&c.LongRequestTimeout, validation.Required),
+		validation.Field(&c.ReadinessTimeout, validation.Required),
+	)
+}
+
+func (c TLSConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.CertFile, validation.Required.When(c.KeyFile != "").Error("is required with the key")),
+		validation.Field(&c.KeyFile, validation.Required.When(c.CertFile != "").Error("is required with the certificate")),
+		validation.Field(&c.ClientCAFile, validation.Empty.When(!c.Enabled()).Error("requires the certificate")),
+		validation.Field(&c.MinVersion, validation.By(func(value interface{}) error {
+			_, err := httpserver.ParseTLSVersion(value.(string))
+			return err
+		})),
+		validation.Field(&c.CipherSuites, validation.When(len(c.CipherSuites) > 0, validation.By(func(value interface{}) error {
+			_, err := httpserver.ParseCipherSuites(strings.Join(value.([]string), ","))
+			return err
+		}))),
+	)
+}
+
+func (c DatabaseConfig) Validate() error {
+	postgres := c.Driver == "postgres" && c.URL == ""
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Driver, validation.Required, validation.In("postgres", "memory")),
+		validation.Field(&c.URL, validation.By(absoluteURL("postgres", "postgresql"))),
+		validation.Field(&c.Host, validation.Required.When(postgres)),
+		validation.Field(&c.Port, validation.Required.When(postgres), is.Port),
+		validation.Field(&c.User, validation.Required.When(postgres)),
+		validation.Field(&c.Name, validation.Required.When(postgres)),
+		validation.Field(&c.MaxOpenConns, validation.Min(0)),
+		validation.Field(&c.MaxIdleConns, validation.Min(0), validation.When(c.MaxOpenConns > 0,
+			validation.Max(c.MaxOpenConns).Error("must be no greater than max_open_conns"))),
+		validation.Field(&c.ConnMaxLifetime, nonNegative),
+		validation.Field(&c.ConnMaxIdleTime, nonNegative),
+		// the timeout is passed in milliseconds, a shorter one would become 0 - no timeout
+		validation.Field(&c.StatementTimeout, validation.Min(time.Millisecond).Error("must be no less than 1ms")),
+		validation.Field(&c.ConnectAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&c.ConnectBackoff, validation.Required, validation.Min(time.Millisecond).Error("must be no less than 1ms")),
+		validation.Field(&c.MonitorInterval, validation.Required, nonNegative),
+	)
+}
+
+// validate - the signing key is required in production.
+func (c AuthConfig) validate(production bool) error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.AdminUserIDs, validation.Each(is.UUID)),
+		validation.Field(&c.TokenSigningKey, validation.Required.When(production).Error("is required in production"),
+			validation.Length(16, 0)),
+		validation.Field(&c.TokenTTL, validation.Required),
+	)
+}
+
+func (c LogConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Level, validation.In(logLevels...)),
+		validation.Field(&c.Format, validation.In(logFormats...)),
+		validation.Field(&c.Dir, validation.Required.When(c.File.Enabled)),
+		validation.Field(&c.File),
+	)
+}
+
+func (c LogFileConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Format, validation.In(logFormats...)),
+		validation.Field(&c.MaxSize, validation.Min(0)),
+		validation.Field(&c.MaxBackups, validation.Min(0)),
+		validation.Field(&c.MaxAge, validation.Min(0)),
+		validation.Field(&c.RotateEvery, nonNegative),
+	)
+}
+
+func (c StorageConfig) Validate() error {
+	s3 := c.Driver == "s3"
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Driver, validation.Required, validation.In("local", "s3")),
+		validation.Field(&c.LocalDir, validation.Required.When(!s3)),
+		validation.Field(&c.UploadMaxSize, validation.Min(int64(1))),
+		validation.Field(&c.ImportMaxSize, validation.Min(int64(1))),
+		validation.Field(&c.S3, validation.Skip.When(!s3)),
+	)
+}
+
+func (c S3Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Endpoint, validation.Required, validation.By(absoluteURL("http", "https"))),
+		validation.Field(&c.Bucket, validation.Required),
+	)
+}
+
+func (c TracingConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Exporter, validation.In("none", "stdout", "otlp")),
+		validation.Field(&c.Endpoint, validation.By(absoluteURL("http", "https"))),
+		validation.Field(&c.SampleRatio, validation.Min(0.0), validation.Max(1.0)),
+	)
+}
+
+func (c FeaturesConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.IdempotencyTTL, validation.Required),
+	)
+}
+
+func hostPort(value interface{}) error {
+	addr, _ := value.(string)
+	if addr == "" {
+		return nil
+	}
+	if _, port, err := net.SplitHostPort(addr); err != nil || is.Port.Validate(port) != nil {
+		return errors.New("must be host:port")
+	}
+	return nil
+}
+
+// absoluteURL - is.URL doesn't allow hosts without domain, e.g. services of docker compose.
+func absoluteURL(schemes ...string) validation.RuleFunc {
+	return func(value interface{}) error {
+		s, _ := value.(string)
+		if s == "" {
+			return nil
+		}
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			return errors.New("must be a valid URL")
+		}
+		for _, scheme := range schemes {
+			if u.Scheme == scheme {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be a URL with scheme %s", strings.Join(schemes, " or "))
+	}
+}
+
+// InvalidError lists every invalid value of the configuration, it's reported on start at once.
+type InvalidError struct {
+	Fields []FieldError
+}
+
+// FieldError - Path is the key in the file, e.g. server.port; Env is the variable, if the field has it.
+type FieldError struct {
+	Path string
+	Env  string
+	Err  error
+}
+
+func (e *InvalidError) Error() string {
+	lines := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		name := f.Path
+		if f.Env != "" {
+			name += " (" + f.Env + ")"
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", name, f.Err))
+	}
+	return "invalid configuration:\n" + strings.Join(lines, "\n")
+}
+
+func (e *InvalidError) add(path, env string, err error) {
+	e.Fields = append(e.Fields, FieldError{Path: path, Env: env, Err: err})
+}
+
+// addValidation flattens nested errors of ozzo-validation, they are keyed by names of fields.
+func (e *InvalidError) addValidation(err error, t reflect.Type, path, env string) {
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		e.add(path, env, err)
+		return
+	}
+
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if t.Kind() == reflect.Slice {
+			// errors of validation.Each are keyed by indexes
+			e.add(fmt.Sprintf("%s[%s]", path, key), env, errs[key])
+			continue
+		}
+		field, ok := t.FieldByName(key)
+		if !ok {
+			e.add(joinPath(path, key), "", errs[key])
+			continue
+		}
+		e.addValidation(errs[key], field.Type, joinPath(path, yamlName(field)), field.Tag.Get("env"))
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_left_in_stock;
+DROP TABLE IF EXISTS product_stock_movements;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS product_stock_movements (
+    id bigserial NOT NULL PRIMARY KEY,
+    product_id uuid REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    kind varchar(16) NOT NULL,
+    quantity int NOT NULL,
+    balance int NOT NULL,
+    reason varchar NOT NULL DEFAULT '',
+    actor_user_id uuid REFERENCES users(id) ON DELETE SET NULL,
+    order_id uuid REFERENCES user_orders(id) ON DELETE SET NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT chk_stock_movement_kind CHECK (kind IN ('receipt', 'reservation', 'release', 'adjustment', 'return')),
+    CONSTRAINT chk_stock_movement_quantity CHECK (quantity <> 0)
+);
+CREATE INDEX idx_stock_movements_product on product_stock_movements (product_id, id);
+
+-- nothing prevented negative stock before, it's counted as sold out
+UPDATE products SET left_in_stock = 0 WHERE left_in_stock < 0;
+ALTER TABLE products ADD CONSTRAINT chk_products_left_in_stock CHECK (left_in_stock >= 0);
+
+-- opening balances, so the ledger sums up to the stock we already have
+INSERT INTO product_stock_movements (product_id, kind, quantity, balance, reason)
+    SELECT id, 'adjustment', left_in_stock, left_in_stock, 'opening balance' FROM products WHERE left_in_stock > 0;
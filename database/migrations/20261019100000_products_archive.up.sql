@@ -0,0 +1,2 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at timestamp(0) with time zone DEFAULT NULL;
+CREATE INDEX idx_products_not_archived on products (id) WHERE archived_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS product_tags;
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS product_categories;
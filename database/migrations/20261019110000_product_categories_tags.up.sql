@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS product_categories (
+    id uuid NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    parent_id uuid REFERENCES product_categories(id) ON DELETE RESTRICT,
+    name varchar NOT NULL,
+    slug varchar(255) NOT NULL UNIQUE
+);
+CREATE INDEX idx_product_categories_parent on product_categories (parent_id);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id uuid REFERENCES product_categories(id) ON DELETE SET NULL;
+CREATE INDEX idx_products_category on products (category_id);
+
+CREATE TABLE IF NOT EXISTS product_tags (
+    product_id uuid REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    tag varchar(64) NOT NULL,
+    PRIMARY KEY (product_id, tag)
+);
+CREATE INDEX idx_product_tags_tag on product_tags (tag);
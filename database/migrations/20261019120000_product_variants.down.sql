@@ -0,0 +1,18 @@
+-- order lines and stock movements of variants can't be kept without them, the history isn't dropped silently
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM user_order_products WHERE variant_id IS NOT NULL)
+        OR EXISTS (SELECT 1 FROM product_stock_movements WHERE variant_id IS NOT NULL) THEN
+        RAISE EXCEPTION 'variants are ordered or have stock movements, rollback would lose the history';
+    END IF;
+END $$;
+
+ALTER TABLE product_stock_movements DROP COLUMN IF EXISTS variant_id;
+
+DROP INDEX IF EXISTS uq_order_product;
+ALTER TABLE user_order_products DROP CONSTRAINT IF EXISTS FK_userOrderProducts_variantId;
+ALTER TABLE user_order_products DROP COLUMN IF EXISTS variant_id;
+CREATE UNIQUE INDEX uq_order_product on user_order_products (order_id, product_id);
+
+DROP TABLE IF EXISTS product_variant_prices;
+DROP TABLE IF EXISTS product_variants;
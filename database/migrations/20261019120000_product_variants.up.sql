@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id uuid NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    product_id uuid REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    sku varchar(64) NOT NULL UNIQUE,
+    attributes jsonb NOT NULL DEFAULT '{}',
+    left_in_stock int NOT NULL DEFAULT 0,
+    CONSTRAINT chk_product_variants_left_in_stock CHECK (left_in_stock >= 0)
+);
+CREATE INDEX idx_product_variants_product on product_variants (product_id);
+
+CREATE TABLE IF NOT EXISTS product_variant_prices (
+    id bigserial NOT NULL UNIQUE,
+    variant_id uuid REFERENCES product_variants(id) ON DELETE CASCADE NOT NULL,
+    currency char(3) NOT NULL,
+    price numeric(15,6) NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX uq_variant_currency on product_variant_prices (variant_id, currency);
+
+-- order line of simple product has no variant
+ALTER TABLE user_order_products ADD COLUMN IF NOT EXISTS variant_id uuid;
+ALTER TABLE user_order_products ADD CONSTRAINT FK_userOrderProducts_variantId
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) NOT DEFERRABLE INITIALLY IMMEDIATE;
+DROP INDEX IF EXISTS uq_order_product;
+CREATE UNIQUE INDEX uq_order_product on user_order_products (order_id, product_id, COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'));
+
+ALTER TABLE product_stock_movements ADD COLUMN IF NOT EXISTS variant_id uuid REFERENCES product_variants(id) ON DELETE CASCADE;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS product_images (
+    id uuid NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+    product_id uuid REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    storage_key varchar(255) NOT NULL UNIQUE,
+    thumbnail_key varchar(255) NOT NULL,
+    content_type varchar(64) NOT NULL,
+    size bigint NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_product_images_product on product_images (product_id, created_at);
@@ -0,0 +1,13 @@
+-- response is NULL while the first request with the key is in progress
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    key varchar(255) NOT NULL,
+    request_hash char(64) NOT NULL,
+    status_code int,
+    content_type varchar(255),
+    response_body bytea,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    expires_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+CREATE INDEX idx_idempotency_keys_expires on idempotency_keys (expires_at);
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS trg_product_images_version ON product_images;
+DROP TRIGGER IF EXISTS trg_product_prices_version ON product_prices;
+DROP FUNCTION IF EXISTS bump_product_version();
+
+DROP TRIGGER IF EXISTS trg_user_profiles_version ON user_profiles;
+DROP TRIGGER IF EXISTS trg_user_orders_version ON user_orders;
+DROP TRIGGER IF EXISTS trg_products_version ON products;
+DROP FUNCTION IF EXISTS bump_version();
+
+ALTER TABLE user_profiles DROP COLUMN IF EXISTS version;
+ALTER TABLE user_orders DROP COLUMN IF EXISTS version;
+ALTER TABLE products DROP COLUMN IF EXISTS version;
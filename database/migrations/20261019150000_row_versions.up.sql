@@ -0,0 +1,35 @@
+ALTER TABLE products ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE user_orders ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE user_profiles ADD COLUMN version int NOT NULL DEFAULT 1;
+
+-- every update of the row makes a new version, whatever query has changed it
+CREATE OR REPLACE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_version BEFORE UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER trg_user_orders_version BEFORE UPDATE ON user_orders
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER trg_user_profiles_version BEFORE UPDATE ON user_profiles
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+
+-- prices and images are the part of the product representation
+CREATE OR REPLACE FUNCTION bump_product_version() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE products SET version = version WHERE id = OLD.product_id;
+    ELSE
+        UPDATE products SET version = version WHERE id = NEW.product_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_product_prices_version AFTER INSERT OR UPDATE OR DELETE ON product_prices
+    FOR EACH ROW EXECUTE FUNCTION bump_product_version();
+CREATE TRIGGER trg_product_images_version AFTER INSERT OR UPDATE OR DELETE ON product_images
+    FOR EACH ROW EXECUTE FUNCTION bump_product_version();
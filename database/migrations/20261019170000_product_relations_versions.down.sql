@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_product_variant_prices_version ON product_variant_prices;
+DROP FUNCTION IF EXISTS bump_variant_product_version();
+DROP TRIGGER IF EXISTS trg_product_variants_version ON product_variants;
+DROP TRIGGER IF EXISTS trg_product_tags_version ON product_tags;
@@ -0,0 +1,21 @@
+-- tags and variants with their prices and stock are the part of the product representation too
+CREATE TRIGGER trg_product_tags_version AFTER INSERT OR UPDATE OR DELETE ON product_tags
+    FOR EACH ROW EXECUTE FUNCTION bump_product_version();
+CREATE TRIGGER trg_product_variants_version AFTER INSERT OR UPDATE OR DELETE ON product_variants
+    FOR EACH ROW EXECUTE FUNCTION bump_product_version();
+
+CREATE OR REPLACE FUNCTION bump_variant_product_version() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE products SET version = version
+            WHERE id = (SELECT product_id FROM product_variants WHERE id = OLD.variant_id);
+    ELSE
+        UPDATE products SET version = version
+            WHERE id = (SELECT product_id FROM product_variants WHERE id = NEW.variant_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_product_variant_prices_version AFTER INSERT OR UPDATE OR DELETE ON product_variant_prices
+    FOR EACH ROW EXECUTE FUNCTION bump_variant_product_version();
@@ -0,0 +1,37 @@
+// Package migrations keeps SQL migrations in the binary, so it knows the version of schema it works with.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion is the version of the last migration, the database must have at least it.
+func LatestVersion() (int64, error) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, name := range files {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("bad name of migration %q", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad version of migration %q: %w", name, err)
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"github.com/linkuha/test-golang-rest-orders-api/config"
 	v1 "github.com/linkuha/test-golang-rest-orders-api/internal/delivery/httpserver/v1"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/service"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
 	"github.com/linkuha/test-golang-rest-orders-api/pkg/logger"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/metrics"
 	"github.com/linkuha/test-golang-rest-orders-api/pkg/srv/httpserver"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/status"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/tracing"
 	"github.com/rs/zerolog/log"
 	"os"
 	"os/signal"
@@ -15,43 +19,129 @@ import (
 	"time"
 )
 
+const internalWriteTimeout = time.Minute
+
 func Run(cfg *config.Config) {
-	if cfg.Merged.LogInFile {
-		logPath := cfg.Merged.LogDir + "/main.log"
-		logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
-		if err != nil {
-			panic("Failure to open log file " + logPath)
-		}
-		defer logFile.Close()
-		logger.InitLogger(cfg.Merged.LogLevel, logFile)
-	} else {
-		logger.InitLogger(cfg.Merged.LogLevel, nil)
+	logOutput, err := logger.Init(newLoggerConfig(&cfg.Log))
+	if err != nil {
+		panic("Failure to init logger: " + err.Error())
 	}
+	defer logOutput.Close()
+
+	// logrotate has moved the file away
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	go func() {
+		for range hangup {
+			if err := logOutput.Reopen(); err != nil {
+				log.Error().Err(err).Msg("app - can't reopen log file")
+			}
+		}
+	}()
 
 	log.Info().Msg("Starting application...")
 	log.Info().Msgf("Config dump: %#v\n", cfg)
-	if !cfg.EnvParams.FileLoaded {
+	if cfg.Sources.EnvFile == "" {
 		log.Warn().Msg(".env file is not loaded")
 	}
-	if !cfg.FileParams.FileLoaded {
+	if cfg.Sources.ConfigFile == "" {
 		log.Warn().Msg("config file is not loaded")
 	}
 
 	ctx := context.Background()
+	appMetrics := metrics.New()
+
+	shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		Version:     status.Version,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal().Msgf("Can't init tracing: %s", err.Error())
+	}
+	defer func() {
+		// spans of the last requests are still in the batch
+		ctxFlush, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctxFlush); err != nil {
+			log.Error().Msgf("app - teardown - tracing: %s", err.Error())
+		}
+	}()
 
 	// Repository
-	db, err := newDB(&cfg.EnvParams)
+	repos, db, closeRepos, err := newRepository(&cfg.Database)
 	if err != nil {
-		log.Fatal().Msgf("Can't connect to database: %s", err.Error())
+		log.Fatal().Msgf("Can't init repository: %s", err.Error())
+	}
+	defer closeRepos()
+	if db != nil {
+		if err := migrateUp(&cfg.Database); err != nil {
+			log.Fatal().Msgf("Can't apply migrations: %s", err.Error())
+		}
+		appMetrics.RegisterDB(db, "main")
 	}
-	defer db.Close()
 
-	repos := repository.NewRepository(db)
+	ctxMonitor, stopMonitor := context.WithCancel(ctx)
+	defer stopMonitor()
+	checker, err := newHealthChecker(ctxMonitor, db, &cfg.Database, cfg.Server.ReadinessTimeout)
+	if err != nil {
+		log.Fatal().Msgf("Can't init health checks: %s", err.Error())
+	}
+
+	store, err := newStorage(&cfg.Storage)
+	if err != nil {
+		log.Fatal().Msgf("Can't init files storage: %s", err.Error())
+	}
+
+	// Use cases
+	useCases := usecase.NewUseCases(repos, usecase.Deps{
+		Storage:        store,
+		Encryptor:      service.NewPasswordEncryptor(),
+		Tokens:         service.NewAuthTokenGenerator(cfg.Auth.TokenSigningKey, cfg.Auth.TokenTTL),
+		IdempotencyTTL: cfg.Features.IdempotencyTTL,
+	})
+	useCases = usecase.WithTracing(usecase.WithMetrics(useCases, appMetrics), tracing.Tracer())
 
 	// HTTP Server
-	ctrl := v1.NewController(ctx, repos)
+	ctrl := v1.NewController(useCases,
+		v1.Storage(store),
+		v1.UploadMaxSize(cfg.Storage.UploadMaxSize),
+		v1.ImportMaxSize(cfg.Storage.ImportMaxSize),
+		v1.RequestTimeout(cfg.Server.RequestTimeout),
+		v1.LongRequestTimeout(cfg.Server.LongRequestTimeout),
+		v1.Metrics(appMetrics),
+		v1.Health(checker),
+		v1.Admins(cfg.Auth.AdminUserIDs...),
+	)
 	router := ctrl.ConfigureRoutes(cfg)
-	httpSrv := httpserver.New(router, httpserver.Port(cfg.EnvParams.Port))
+	serverOpts, err := publicServerOptions(&cfg.Server)
+	if err != nil {
+		log.Fatal().Msgf("Invalid HTTP server config: %s", err.Error())
+	}
+	httpSrv := httpserver.New(router, append(serverOpts,
+		httpserver.Port(cfg.Server.Port),
+		// the longest request must have time to respond about its deadline
+		httpserver.WriteTimeout(ctrl.MaxRequestTimeout()+5*time.Second),
+	)...)
+	// nil channel of the absent listener never fires
+	var internalNotify <-chan error
+	var internalSrv *httpserver.Server
+	if cfg.Server.InternalAddr != "" {
+		internalSrv = httpserver.New(ctrl.ConfigureInternalRoutes(), append(connectionOptions(&cfg.Server),
+			httpserver.Addr(cfg.Server.InternalAddr),
+			// CPU profile and trace are collected for 30s by default
+			httpserver.WriteTimeout(internalWriteTimeout),
+		)...)
+		internalNotify = internalSrv.Notify()
+		log.Info().Msgf("Internal listener on %s", cfg.Server.InternalAddr)
+	}
+
+	ctxCleanup, stopCleanup := context.WithCancel(ctx)
+	defer stopCleanup()
+	go runCleanup(ctxCleanup, useCases.Idempotency)
 
 	// Waiting signal
 	interrupt := make(chan os.Signal, 1)
@@ -60,8 +150,14 @@ func Run(cfg *config.Config) {
 	select {
 	case s := <-interrupt:
 		log.Info().Msg("app - termination signal: " + s.String())
+		// requests are still served, but load balancers see failing readiness and stop sending new ones
+		checker.Shutdown()
+		log.Info().Msgf("Draining for %s...", cfg.App.ShutdownDrainDelay)
+		time.Sleep(cfg.App.ShutdownDrainDelay)
 	case err = <-httpSrv.Notify():
 		log.Error().Msgf("app - httpServer error or stopped (ErrServerClosed): %s", err.Error())
+	case err = <-internalNotify:
+		log.Error().Msgf("app - internal httpServer error or stopped (ErrServerClosed): %s", err.Error())
 	}
 
 	log.Info().Msg("Graceful shutdown...")
@@ -70,7 +166,13 @@ func Run(cfg *config.Config) {
 
 	err = httpSrv.Shutdown(ctxTeardown)
 	if err != nil {
-		log.Error().Err(fmt.Errorf("app - teardown - httpServer.Shutdown: %w", err))
+		log.Error().Err(fmt.Errorf("app - teardown - httpServer.Shutdown: %w", err)).Send()
+	}
+	// the last, metrics and status are available while the public requests are completed
+	if internalSrv != nil {
+		if err = internalSrv.Shutdown(ctxTeardown); err != nil {
+			log.Error().Err(fmt.Errorf("app - teardown - internal httpServer.Shutdown: %w", err)).Send()
+		}
 	}
 	// unplug from message broker
 	// unplug from service mesh
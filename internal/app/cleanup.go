@@ -0,0 +1,30 @@
+package app
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/idempotency"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const cleanupInterval = time.Hour
+
+// runCleanup periodically removes expired data until ctx is done.
+func runCleanup(ctx context.Context, uc idempotency.UseCase) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := uc.RemoveExpired(ctx)
+			if err != nil {
+				log.Error().Msgf("Can't remove expired idempotency keys: %s", err.Error())
+				continue
+			}
+			log.Debug().Msgf("Removed %d expired idempotency keys", n)
+		}
+	}
+}
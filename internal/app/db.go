@@ -1,39 +1,90 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	_ "github.com/lib/pq"
 	"github.com/linkuha/test-golang-rest-orders-api/config"
 	"github.com/rs/zerolog/log"
+	"net/url"
+	"strconv"
+	"time"
 )
 
-func newDB(cfg *config.EnvParams) (*sql.DB, error) {
-	var connStr string
-	if cfg.DatabaseURL != "" {
-		connStr = cfg.DatabaseURL
-	} else {
-		connStr = fmt.Sprintf("host=%s port=%s user=%s "+
-			"password=%s dbname=%s sslmode=disable",
-			cfg.PgHost, cfg.PgPort, cfg.PgUser, cfg.PgPassword, cfg.PgDB)
-	}
+const (
+	pingTimeout       = 5 * time.Second
+	maxConnectBackoff = 30 * time.Second
+)
 
+func newDB(cfg *config.DatabaseConfig) (*sql.DB, error) {
+	connStr, err := connectionString(cfg)
+	if err != nil {
+		return nil, err
+	}
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	log.Info().Msg("Ping database...")
-	if err = db.Ping(); err != nil {
+	if err = connect(db, cfg.ConnectAttempts, cfg.ConnectBackoff); err != nil {
+		db.Close()
 		return nil, err
 	}
 	q := db.QueryRow("SELECT VERSION()")
 	var ver string
 	err = q.Scan(&ver)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 	log.Info().Msgf("DB version: %s", ver)
 
 	return db, nil
 }
+
+// connectionString - settings of sessions are parameters of the connection, parameters of DATABASE_URL win.
+// Migrations use the plain DSN, long DDL mustn't be cancelled by statement_timeout.
+func connectionString(cfg *config.DatabaseConfig) (string, error) {
+	u, err := url.Parse(cfg.DSN())
+	if err != nil {
+		return "", fmt.Errorf("parse database URL: %w", err)
+	}
+	q := u.Query()
+	if cfg.ApplicationName != "" && !q.Has("application_name") {
+		q.Set("application_name", cfg.ApplicationName)
+	}
+	if cfg.StatementTimeout > 0 && !q.Has("statement_timeout") {
+		q.Set("statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10))
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// connect waits for the database, it may start later than the application, e.g. in docker compose.
+func connect(db *sql.DB, attempts int, backoff time.Duration) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		log.Info().Msgf("Ping database, attempt %d of %d...", attempt, attempts)
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil || attempt == attempts {
+			break
+		}
+
+		log.Warn().Err(err).Msgf("Database isn't available, retry in %s", backoff)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("database isn't available after %d attempts: %w", attempts, err)
+	}
+	return nil
+}
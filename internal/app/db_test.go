@@ -0,0 +1,77 @@
+package app
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestConnectionString(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.DatabaseConfig
+		expected string
+	}{
+		{
+			name:     "parameters are added",
+			cfg:      config.DatabaseConfig{Host: "db", Port: "5432", User: "app", Name: "orders", ApplicationName: "orders-api", StatementTimeout: 30 * time.Second},
+			expected: "postgres://app:@db:5432/orders?application_name=orders-api&sslmode=disable&statement_timeout=30000",
+		},
+		{
+			name:     "no statement timeout",
+			cfg:      config.DatabaseConfig{URL: "postgres://app@db/orders", ApplicationName: "orders-api"},
+			expected: "postgres://app@db/orders?application_name=orders-api",
+		},
+		{
+			name:     "parameters of URL win",
+			cfg:      config.DatabaseConfig{URL: "postgres://app@db/orders?application_name=worker&statement_timeout=5000", ApplicationName: "orders-api", StatementTimeout: time.Minute},
+			expected: "postgres://app@db/orders?application_name=worker&statement_timeout=5000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := connectionString(&tt.cfg)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	errDown := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		failures int
+		attempts int
+		fail     bool
+	}{
+		{name: "available", failures: 0, attempts: 3},
+		{name: "available after retries", failures: 2, attempts: 3},
+		{name: "gives up after the last attempt", failures: 3, attempts: 3, fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+			defer db.Close()
+
+			for i := 0; i < tt.failures; i++ {
+				mock.ExpectPing().WillReturnError(errDown)
+			}
+			if !tt.fail {
+				mock.ExpectPing()
+			}
+
+			err = connect(db, tt.attempts, time.Millisecond)
+			if tt.fail {
+				require.ErrorIs(t, err, errDown)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
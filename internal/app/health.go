@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/database/migrations"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/health"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// newHealthChecker checks the database, if it's used: it's available and has the schema expected by the binary.
+// The connection is monitored in background until ctx is done, probes don't wait for the database.
+func newHealthChecker(ctx context.Context, db *sql.DB, cfg *config.DatabaseConfig, timeout time.Duration) (*health.Checker, error) {
+	checker := health.New(timeout)
+	if db == nil {
+		return checker, nil
+	}
+
+	expected, err := migrations.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	// sql.DB reconnects by itself, the monitor finds out about it before requests do
+	monitor := health.NewMonitor(db.PingContext, cfg.MonitorInterval, timeout, func(err error) {
+		if err != nil {
+			log.Error().Err(err).Msg("Database connection is lost")
+			return
+		}
+		log.Info().Msg("Database connection is restored")
+	})
+	go monitor.Run(ctx)
+
+	checker.Add("database", monitor.Check)
+	checker.Add("migrations", migrationsCheck(db, expected))
+	return checker, nil
+}
+
+// migrationsCheck - the newer schema is fine, it's migrated by the next version of the application during its rollout.
+func migrationsCheck(db *sql.DB, expected int64) health.Check {
+	return func(ctx context.Context) error {
+		var (
+			version int64
+			dirty   bool
+		)
+		err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("migrations are not applied, expected version %d", expected)
+		}
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migration %d is failed, the schema is dirty", version)
+		}
+		if version < expected {
+			return fmt.Errorf("schema version %d is older than expected %d", version, expected)
+		}
+		return nil
+	}
+}
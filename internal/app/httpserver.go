@@ -0,0 +1,46 @@
+package app
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/srv/httpserver"
+	"strings"
+)
+
+// connectionOptions - timeouts of connections, they are the same for both listeners.
+func connectionOptions(cfg *config.ServerConfig) []httpserver.Option {
+	return []httpserver.Option{
+		httpserver.ReadTimeout(cfg.ReadTimeout),
+		httpserver.ReadHeaderTimeout(cfg.ReadHeaderTimeout),
+		httpserver.IdleTimeout(cfg.IdleTimeout),
+	}
+}
+
+// publicServerOptions - TLS is terminated by the application, if the certificate is set, otherwise by the gateway.
+// The config is validated, values are parsed without errors.
+func publicServerOptions(cfg *config.ServerConfig) ([]httpserver.Option, error) {
+	opts := connectionOptions(cfg)
+	if cfg.H2C {
+		opts = append(opts, httpserver.H2C())
+	}
+	if !cfg.TLS.Enabled() {
+		return opts, nil
+	}
+
+	version, err := httpserver.ParseTLSVersion(cfg.TLS.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, httpserver.TLS(cfg.TLS.CertFile, cfg.TLS.KeyFile), httpserver.TLSMinVersion(version))
+
+	if len(cfg.TLS.CipherSuites) > 0 {
+		suites, err := httpserver.ParseCipherSuites(strings.Join(cfg.TLS.CipherSuites, ","))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, httpserver.TLSCipherSuites(suites...))
+	}
+	if cfg.TLS.ClientCAFile != "" {
+		opts = append(opts, httpserver.ClientCA(cfg.TLS.ClientCAFile))
+	}
+	return opts, nil
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/logger"
+	"path/filepath"
+)
+
+// newLoggerConfig - the file is written to the log dir, if it's enabled.
+func newLoggerConfig(cfg *config.LogConfig) logger.Config {
+	lc := logger.Config{
+		Level:  cfg.Level,
+		Format: cfg.Format,
+	}
+	if cfg.File.Enabled {
+		lc.File = logger.FileConfig{
+			Path:        filepath.Join(cfg.Dir, "main.log"),
+			Format:      cfg.File.Format,
+			MaxSize:     cfg.File.MaxSize,
+			MaxBackups:  cfg.File.MaxBackups,
+			MaxAge:      cfg.File.MaxAge,
+			RotateEvery: cfg.File.RotateEvery,
+			Compress:    cfg.File.Compress,
+		}
+	}
+	return lc
+}
@@ -4,58 +4,33 @@ package app
 
 import (
 	"errors"
-	"log"
-	"os"
-	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	// migrate tools
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/rs/zerolog/log"
 )
 
-const (
-	defaultAttempts = 20
-	defaultTimeout  = time.Second
-)
-
-func init() {
-	databaseURL, ok := os.LookupEnv("DATABASE_URL")
-	if !ok || len(databaseURL) == 0 {
-		log.Fatalf("migrate: environment variable not declared: DATABASE_URL")
-	}
-
-	var (
-		attempts = defaultAttempts
-		err      error
-		m        *migrate.Migrate
-	)
-
-	for attempts > 0 {
-		m, err = migrate.New("file://database/migrations", databaseURL)
-		if err == nil {
-			break
-		}
-
-		log.Printf("Migrate: postgres is trying to connect, attempts left: %d", attempts)
-		time.Sleep(defaultTimeout)
-		attempts--
-	}
-
+// migrateUp applies migrations before the start, the build with tag automigrate does it.
+// It's called after newDB, so the database is already available.
+func migrateUp(cfg *config.DatabaseConfig) error {
+	m, err := migrate.New("file://database/migrations", cfg.DSN())
 	if err != nil {
-		log.Fatalf("Migrate: postgres connect error: %s", err)
+		return err
 	}
-
-	err = m.Up()
 	defer m.Close()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		log.Fatalf("Migrate: up error: %s", err)
-	}
 
+	err = m.Up()
 	if errors.Is(err, migrate.ErrNoChange) {
-		log.Printf("Migrate: no change")
-		return
+		log.Info().Msg("Migrate: no change")
+		return nil
+	}
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Migrate: up success")
+	log.Info().Msg("Migrate: up success")
+	return nil
 }
@@ -0,0 +1,12 @@
+//go:build !automigrate
+
+package app
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+)
+
+// migrateUp - migrations are applied by `make migrate-up`, the build without tag automigrate doesn't do it.
+func migrateUp(*config.DatabaseConfig) error {
+	return nil
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/tracing"
+	"github.com/rs/zerolog/log"
+)
+
+// newRepository returns repositories of the configured driver and the function releasing their resources.
+// The database is nil for the memory driver.
+func newRepository(cfg *config.DatabaseConfig) (repository.Repository, *sql.DB, func(), error) {
+	switch cfg.Driver {
+	case "postgres":
+		db, err := newDB(cfg)
+		if err != nil {
+			return repository.Repository{}, nil, nil, err
+		}
+		return repository.NewRepository(dbtx.WithTracing(dbtx.New(db), tracing.Tracer())), db, func() { db.Close() }, nil
+	case "memory":
+		log.Warn().Msg("Repository: in memory, data is lost on restart")
+		return repository.NewMemoryRepository(memdb.New()), nil, func() {}, nil
+	}
+	return repository.Repository{}, nil, nil, fmt.Errorf("unknown repository driver %q", cfg.Driver)
+}
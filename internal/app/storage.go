@@ -0,0 +1,27 @@
+package app
+
+import (
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/storage"
+	"github.com/rs/zerolog/log"
+)
+
+func newStorage(cfg *config.StorageConfig) (storage.Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		log.Info().Msgf("Files storage: local directory %s", cfg.LocalDir)
+		return storage.NewLocal(cfg.LocalDir, cfg.PublicURL)
+	case "s3":
+		log.Info().Msgf("Files storage: s3 bucket %s at %s", cfg.S3.Bucket, cfg.S3.Endpoint)
+		return storage.NewS3(storage.S3Options{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PublicURL: cfg.PublicURL,
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
@@ -3,8 +3,6 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/service"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/user"
 	"net/http"
 )
 
@@ -21,7 +19,7 @@ type signInInput struct {
 // @Produce  json
 // @Param input body signInInput true "account info"
 // @Success 200 {integer} string "id"
-// @Failure 400,404 {object} errorResponse
+// @Failure 400,404,409 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /auth/sign-up [post]
@@ -33,10 +31,9 @@ func (ctrl *Controller) signUp(ctx *gin.Context) {
 		return
 	}
 
-	encryptor := service.NewPasswordEncryptor()
-	uc := user.NewUserUseCase(ctrl.repos.Users, encryptor)
+	uc := ctrl.useCases.Users
 	u := entity.User{Username: input.Username, Password: input.Password}
-	id, err := uc.Create(ctrl.ctx, u)
+	id, err := uc.Create(ctx.Request.Context(), u)
 	if err != nil {
 		newErrorResponse(ctx, err)
 		return
@@ -55,7 +52,7 @@ func (ctrl *Controller) signUp(ctx *gin.Context) {
 // @Produce  json
 // @Param input body signInInput true "credentials"
 // @Success 200 {string} string "token"
-// @Failure 400,404 {object} errorResponse
+// @Failure 400,401,404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /auth/sign-in [post]
@@ -67,16 +64,14 @@ func (ctrl *Controller) signIn(ctx *gin.Context) {
 		return
 	}
 
-	encryptor := service.NewPasswordEncryptor()
-	uc := user.NewUserUseCase(ctrl.repos.Users, encryptor)
-	u, err := uc.GetUserIfCredentialsValid(ctrl.ctx, input.Username, input.Password)
+	uc := ctrl.useCases.Users
+	u, err := uc.GetUserIfCredentialsValid(ctx.Request.Context(), input.Username, input.Password)
 	if err != nil {
 		newErrorResponse(ctx, err)
 		return
 	}
 
-	authTokenGenerator := service.AuthTokenGenerator{}
-	token, err := authTokenGenerator.GenerateToken(u.ID)
+	token, err := ctrl.useCases.Tokens.GenerateToken(u.ID)
 	if err != nil {
 		newErrorResponse(ctx, err)
 		return
@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"net/http"
+)
+
+// @Summary Create category
+// @Security ApiKeyAuth
+// @Tags category
+// @Description Create category, root category has no parent_id
+// @ID category-create
+// @Accept  json
+// @Produce  json
+// @Param input body entity.Category true "category data"
+// @Success 200 {string} string "id"
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /categories [post]
+func (ctrl *Controller) createCategory(c *gin.Context) {
+	var input entity.Category
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, newJSONBindingErrorWrapper(err))
+		return
+	}
+
+	uc := ctrl.useCases.Categories
+	id, err := uc.Create(c.Request.Context(), input)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// @Summary Get all categories
+// @Security ApiKeyAuth
+// @Tags category
+// @Description get all categories (flat list, tree is built by parent_id)
+// @ID category-get-all
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse{data=[]entity.Category}
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /categories [get]
+func (ctrl *Controller) getAllCategories(c *gin.Context) {
+	uc := ctrl.useCases.Categories
+	categories, err := uc.GetAll(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	newDataResponse(c, *categories)
+}
+
+// @Summary Get category
+// @Security ApiKeyAuth
+// @Tags category
+// @Description get category
+// @ID category-get
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Category ID"
+// @Success 200 {object} entity.Category
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /categories/{id} [get]
+func (ctrl *Controller) getCategoryByID(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	uc := ctrl.useCases.Categories
+	res, err := uc.GetByID(c.Request.Context(), id)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Summary Update category
+// @Security ApiKeyAuth
+// @Tags category
+// @Description update category, also moves it in the tree
+// @ID category-update
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Category ID"
+// @Param input body entity.Category true "category data"
+// @Success 200 {object} statusResponse
+// @Failure 400,404,422 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /categories/{id} [put]
+func (ctrl *Controller) updateCategoryByID(c *gin.Context) {
+	var input entity.Category
+
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, newJSONBindingErrorWrapper(err))
+		return
+	}
+	input.ID = id
+
+	uc := ctrl.useCases.Categories
+	if err := uc.Update(c.Request.Context(), input); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{true})
+}
+
+// @Summary Delete category
+// @Security ApiKeyAuth
+// @Tags category
+// @Description delete category without subcategories, its products stay without category
+// @ID category-delete
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Category ID"
+// @Success 200 {object} statusResponse
+// @Failure 400,404,409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /categories/{id} [delete]
+func (ctrl *Controller) deleteCategoryByID(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	uc := ctrl.useCases.Categories
+	if err := uc.Remove(c.Request.Context(), id); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{true})
+}
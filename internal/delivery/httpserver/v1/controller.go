@@ -1,15 +1,126 @@
 package v1
 
 import (
-	"context"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/health"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/metrics"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/storage"
+	"time"
+)
+
+const (
+	defaultUploadMaxSize      = 5 << 20  // 5 MB
+	defaultImportMaxSize      = 10 << 20 // 10 MB
+	defaultRequestTimeout     = 5 * time.Second
+	defaultLongRequestTimeout = time.Minute
 )
 
 type Controller struct {
-	ctx   context.Context
-	repos repository.Repository
+	useCases           usecase.UseCases
+	storage            storage.Storage
+	uploadMaxSize      int64
+	importMaxSize      int64
+	requestTimeout     time.Duration
+	longRequestTimeout time.Duration
+	metrics            *metrics.Metrics
+	health             *health.Checker
+	admins             map[string]struct{}
+}
+
+// Option -.
+type Option func(*Controller)
+
+// Storage of uploaded files, the local one is served by the router. Uploads go through the images use case.
+func Storage(s storage.Storage) Option {
+	return func(ctrl *Controller) {
+		ctrl.storage = s
+	}
+}
+
+// UploadMaxSize limits size of uploaded file in bytes.
+func UploadMaxSize(size int64) Option {
+	return func(ctrl *Controller) {
+		if size > 0 {
+			ctrl.uploadMaxSize = size
+		}
+	}
+}
+
+// ImportMaxSize limits size of imported file of products in bytes.
+func ImportMaxSize(size int64) Option {
+	return func(ctrl *Controller) {
+		if size > 0 {
+			ctrl.importMaxSize = size
+		}
+	}
+}
+
+// RequestTimeout - the deadline of request processing, queries of the request are cancelled after it.
+func RequestTimeout(timeout time.Duration) Option {
+	return func(ctrl *Controller) {
+		if timeout > 0 {
+			ctrl.requestTimeout = timeout
+		}
+	}
+}
+
+// LongRequestTimeout - the deadline of requests with files: import, export and upload of images.
+func LongRequestTimeout(timeout time.Duration) Option {
+	return func(ctrl *Controller) {
+		if timeout > 0 {
+			ctrl.longRequestTimeout = timeout
+		}
+	}
+}
+
+// Metrics of requests, they are served on /metrics. Without it requests aren't measured.
+func Metrics(m *metrics.Metrics) Option {
+	return func(ctrl *Controller) {
+		ctrl.metrics = m
+	}
+}
+
+// Health checks dependencies for the readiness probe. Without it the application is always ready.
+func Health(h *health.Checker) Option {
+	return func(ctrl *Controller) {
+		if h != nil {
+			ctrl.health = h
+		}
+	}
+}
+
+// Admins - IDs of users, who may see the status of the application.
+func Admins(userIDs ...string) Option {
+	return func(ctrl *Controller) {
+		for _, id := range userIDs {
+			ctrl.admins[id] = struct{}{}
+		}
+	}
+}
+
+// NewController - handlers call useCases, which are built once by the application.
+func NewController(useCases usecase.UseCases, opts ...Option) *Controller {
+	ctrl := &Controller{
+		useCases:           useCases,
+		uploadMaxSize:      defaultUploadMaxSize,
+		importMaxSize:      defaultImportMaxSize,
+		requestTimeout:     defaultRequestTimeout,
+		longRequestTimeout: defaultLongRequestTimeout,
+		health:             health.New(0),
+		admins:             map[string]struct{}{},
+	}
+
+	for _, opt := range opts {
+		opt(ctrl)
+	}
+
+	return ctrl
 }
 
-func NewController(ctx context.Context, repos repository.Repository) *Controller {
-	return &Controller{ctx, repos}
+// MaxRequestTimeout is the longest deadline of routes.
+func (ctrl *Controller) MaxRequestTimeout() time.Duration {
+	if ctrl.longRequestTimeout > ctrl.requestTimeout {
+		return ctrl.longRequestTimeout
+	}
+	return ctrl.requestTimeout
 }
@@ -1,10 +1,13 @@
 package v1
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
 	"net/http"
+	"sort"
 )
 
 var (
@@ -18,14 +21,74 @@ const (
 	ErrAuthAPIText            = "invalid API authorization"
 	ErrCredentialsText        = "invalid username or password"
 	ErrInputJSONText          = "bad input json"
+	ErrInputQueryText         = "bad query params"
+	ErrInputFileText          = "bad input file"
 	ErrValidationText         = "validation error"
 	ErrNotFoundText           = "resource is not found"
+	ErrInputIfMatchText       = "bad If-Match header"
+	ErrTimeoutText            = "request is not completed in time, try later"
 )
 
+// Error codes of problem responses. They are the part of API, clients may rely on them,
+// so don't rename them. Every code is described in docs/problems.md.
+const (
+	CodeInternal           = "internal"
+	CodeInvalidOperation   = "invalid_operation"
+	CodeInvalidArgument    = "invalid_argument"
+	CodeMalformedRequest   = "malformed_request"
+	CodeUnavailable        = "unavailable"
+	CodeConflict           = "conflict"
+	CodeAlreadyExists      = "already_exists"
+	CodeNotFound           = "not_found"
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeForbidden          = "forbidden"
+	CodeBrokenReference    = "broken_reference"
+	CodeValidation         = "validation_failed"
+	CodePreconditionFailed = "precondition_failed"
+	CodeFileTooLarge       = "file_too_large"
+	CodeBodyTooLarge       = "body_too_large"
+	CodeTimeout            = "timeout"
+	CodeCanceled           = "canceled"
+)
+
+// errorKind is HTTP representation of the errs code.
+type errorKind struct {
+	Status int
+	Code   string
+}
+
+var errorKinds = map[int]errorKind{
+	errs.Other:              {http.StatusInternalServerError, CodeInternal},
+	errs.InvalidOperation:   {http.StatusMethodNotAllowed, CodeInvalidOperation},
+	errs.InvalidArgument:    {http.StatusBadRequest, CodeInvalidArgument},
+	errs.MalformedRequest:   {http.StatusBadRequest, CodeMalformedRequest},
+	errs.IO:                 {http.StatusServiceUnavailable, CodeUnavailable},
+	errs.Logic:              {http.StatusConflict, CodeConflict},
+	errs.Exist:              {http.StatusConflict, CodeAlreadyExists},
+	errs.NotExist:           {http.StatusNotFound, CodeNotFound},
+	errs.APIAuthorization:   {http.StatusUnauthorized, CodeUnauthorized},
+	errs.UserCredentials:    {http.StatusUnauthorized, CodeInvalidCredentials},
+	errs.NotPermitted:       {http.StatusForbidden, CodeForbidden},
+	errs.Private:            {http.StatusForbidden, CodeForbidden},
+	errs.Internal:           {http.StatusInternalServerError, CodeInternal},
+	errs.BrokenLink:         {http.StatusBadRequest, CodeBrokenReference},
+	errs.Database:           {http.StatusInternalServerError, CodeInternal},
+	errs.DatabaseConnection: {http.StatusServiceUnavailable, CodeUnavailable},
+	errs.RemoteConnection:   {http.StatusServiceUnavailable, CodeUnavailable},
+	errs.Validation:         {http.StatusUnprocessableEntity, CodeValidation},
+	errs.Unanticipated:      {http.StatusInternalServerError, CodeInternal},
+	errs.PreconditionFailed: {http.StatusPreconditionFailed, CodePreconditionFailed},
+	errs.Timeout:            {http.StatusGatewayTimeout, CodeTimeout},
+	errs.Canceled:           {http.StatusServiceUnavailable, CodeCanceled},
+}
+
 type errorHandlingDetails struct {
 	ClientError string
 	DebugError  string
 	Code        int
+	ErrorCode   string
+	Fields      []fieldError
 }
 
 func handleDomainError(e error) errorHandlingDetails {
@@ -33,68 +96,49 @@ func handleDomainError(e error) errorHandlingDetails {
 
 	if errors.Is(e, forbiddenError) {
 		resErr.Code = http.StatusForbidden
+		resErr.ErrorCode = CodeForbidden
 		resErr.ClientError = e.Error()
 		resErr.DebugError = e.Error()
 		return resErr
 	}
 	if errors.Is(e, emptyParameterID) {
 		resErr.Code = http.StatusBadRequest
+		resErr.ErrorCode = CodeInvalidArgument
 		resErr.ClientError = e.Error()
 		resErr.DebugError = e.Error()
 		return resErr
 	}
 
+	resErr.Code = http.StatusInternalServerError
+	resErr.ErrorCode = CodeInternal
+
 	if customErr, ok := e.(errs.CustomErrorWrapper); ok {
 		digErr := customErr.Dig()
 
 		resErr.ClientError = customErr.Message
+		if kind, ok := errorKinds[digErr.Code]; ok {
+			resErr.Code = kind.Status
+			resErr.ErrorCode = kind.Code
+		}
 
 		switch digErr.Code {
-		case errs.Other:
-			resErr.Code = http.StatusInternalServerError
-		case errs.InvalidOperation:
-			resErr.Code = http.StatusMethodNotAllowed
-		case errs.InvalidArgument:
-			resErr.Code = http.StatusBadRequest
-		case errs.MalformedRequest:
-			resErr.Code = http.StatusBadRequest
-		case errs.IO:
-			resErr.Code = http.StatusServiceUnavailable
-		case errs.Logic:
-			resErr.Code = http.StatusConflict
-		case errs.Exist:
-			resErr.Code = http.StatusCreated
+		case errs.Exist, errs.BrokenLink:
+			resErr.ClientError = digErr.Message
+			resErr.Fields = fieldErrors(digErr.Err)
 		case errs.NotExist:
-			resErr.Code = http.StatusNotFound
 			resErr.ClientError = ErrNotFoundText
 		case errs.APIAuthorization:
-			resErr.Code = http.StatusUnauthorized
 			resErr.ClientError = ErrAuthAPIText
 		case errs.UserCredentials:
-			resErr.Code = http.StatusOK
 			resErr.ClientError = ErrCredentialsText
-		case errs.NotPermitted:
-			resErr.Code = http.StatusForbidden
-		case errs.Private:
-			resErr.Code = http.StatusForbidden
-		case errs.Internal:
-			resErr.Code = http.StatusInternalServerError
-		case errs.BrokenLink:
-			resErr.Code = http.StatusBadRequest
-		case errs.Database:
-			resErr.Code = http.StatusInternalServerError
-		case errs.DatabaseConnection:
-			resErr.Code = http.StatusServiceUnavailable
-		case errs.RemoteConnection:
-			resErr.Code = http.StatusServiceUnavailable
 		case errs.Validation:
-			resErr.Code = http.StatusUnprocessableEntity
 			resErr.ClientError = fmt.Sprintf("%s: %s", ErrValidationText, digErr.Error())
-		case errs.Unanticipated:
-			resErr.Code = http.StatusInternalServerError
+			resErr.Fields = fieldErrors(digErr.Err)
 		}
 
 		resErr.DebugError = digErr.Error()
+	} else if e != nil {
+		resErr.DebugError = e.Error()
 	}
 
 	switch resErr.Code {
@@ -102,11 +146,71 @@ func handleDomainError(e error) errorHandlingDetails {
 		resErr.ClientError = ErrServiceInternalText
 	case http.StatusServiceUnavailable:
 		resErr.ClientError = ErrServiceUnavailableText
+	case http.StatusGatewayTimeout:
+		resErr.ClientError = ErrTimeoutText
 	}
 
 	return resErr
 }
 
+// fieldErrors extracts errors of fields from the ozzo-validation errors and violated constraints,
+// nested fields are joined by dots: prices.0.currency.
+func fieldErrors(e error) []fieldError {
+	var res []fieldError
+
+	var ce *errs.ConstraintError
+	if errors.As(e, &ce) {
+		if ce.Field != "" {
+			res = append(res, fieldError{Field: ce.Field, Message: ce.Err.Error()})
+		}
+		return res
+	}
+
+	var ve validation.Errors
+	if errors.As(e, &ve) {
+		collectFieldErrors(&res, "", ve)
+	}
+	return res
+}
+
+func collectFieldErrors(res *[]fieldError, prefix string, ve validation.Errors) {
+	keys := make([]string, 0, len(ve))
+	for k := range ve {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		field := k
+		if prefix != "" {
+			field = prefix + "." + k
+		}
+
+		switch err := ve[k].(type) {
+		case nil:
+		case validation.Errors:
+			collectFieldErrors(res, field, err)
+		case validation.Error:
+			*res = append(*res, fieldError{Field: field, Code: err.Code(), Message: err.Error()})
+		default:
+			*res = append(*res, fieldError{Field: field, Message: err.Error()})
+		}
+	}
+}
+
+// withContextError explains the error by the done context of request: the query is aborted by the deadline
+// or by the client which has gone, whatever the driver returned in this case. The cause is flattened
+// to the text, otherwise its inner code would win.
+func withContextError(ctx context.Context, e error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return errs.NewErrorWrapper(errs.Timeout, errors.New(e.Error()), "request deadline is exceeded")
+	case errors.Is(ctx.Err(), context.Canceled):
+		return errs.NewErrorWrapper(errs.Canceled, errors.New(e.Error()), "request is canceled")
+	}
+	return e
+}
+
 func newJSONBindingErrorWrapper(e error) error {
 	return errs.NewErrorWrapper(errs.MalformedRequest, e, ErrInputJSONText)
 }
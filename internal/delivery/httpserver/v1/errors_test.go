@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestHandleDomainErrorStatuses(t *testing.T) {
+	tests := []struct {
+		code      int
+		status    int
+		errorCode string
+	}{
+		{errs.Other, http.StatusInternalServerError, CodeInternal},
+		{errs.InvalidOperation, http.StatusMethodNotAllowed, CodeInvalidOperation},
+		{errs.InvalidArgument, http.StatusBadRequest, CodeInvalidArgument},
+		{errs.MalformedRequest, http.StatusBadRequest, CodeMalformedRequest},
+		{errs.IO, http.StatusServiceUnavailable, CodeUnavailable},
+		{errs.Logic, http.StatusConflict, CodeConflict},
+		{errs.Exist, http.StatusConflict, CodeAlreadyExists},
+		{errs.NotExist, http.StatusNotFound, CodeNotFound},
+		{errs.APIAuthorization, http.StatusUnauthorized, CodeUnauthorized},
+		{errs.UserCredentials, http.StatusUnauthorized, CodeInvalidCredentials},
+		{errs.NotPermitted, http.StatusForbidden, CodeForbidden},
+		{errs.Private, http.StatusForbidden, CodeForbidden},
+		{errs.Internal, http.StatusInternalServerError, CodeInternal},
+		{errs.BrokenLink, http.StatusBadRequest, CodeBrokenReference},
+		{errs.Database, http.StatusInternalServerError, CodeInternal},
+		{errs.DatabaseConnection, http.StatusServiceUnavailable, CodeUnavailable},
+		{errs.RemoteConnection, http.StatusServiceUnavailable, CodeUnavailable},
+		{errs.Validation, http.StatusUnprocessableEntity, CodeValidation},
+		{errs.Unanticipated, http.StatusInternalServerError, CodeInternal},
+		{errs.PreconditionFailed, http.StatusPreconditionFailed, CodePreconditionFailed},
+		{errs.Timeout, http.StatusGatewayTimeout, CodeTimeout},
+		{errs.Canceled, http.StatusServiceUnavailable, CodeCanceled},
+	}
+	require.Len(t, tests, len(errorKinds), "every errs code must be mapped")
+
+	for _, tt := range tests {
+		t.Run(tt.errorCode, func(t *testing.T) {
+			// the inner code wins
+			err := errs.NewErrorWrapper(errs.Database, errs.NewErrorWrapper(tt.code, errors.New("cause"), "inner"), "outer")
+
+			res := handleDomainError(err)
+			assert.Equal(t, tt.status, res.Code)
+			assert.Equal(t, tt.errorCode, res.ErrorCode)
+			assert.NotEmpty(t, res.ClientError)
+			assert.Equal(t, "cause", res.DebugError)
+		})
+	}
+}
+
+func TestHandleDomainErrorMessages(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		status      int
+		errorCode   string
+		clientError string
+	}{
+		{
+			name:        "forbidden",
+			err:         forbiddenError,
+			status:      http.StatusForbidden,
+			errorCode:   CodeForbidden,
+			clientError: "forbidden",
+		},
+		{
+			name:        "empty id",
+			err:         emptyParameterID,
+			status:      http.StatusBadRequest,
+			errorCode:   CodeInvalidArgument,
+			clientError: "missed identifier param",
+		},
+		{
+			name:        "not wrapped",
+			err:         errors.New("boom"),
+			status:      http.StatusInternalServerError,
+			errorCode:   CodeInternal,
+			clientError: ErrServiceInternalText,
+		},
+		{
+			name:        "credentials",
+			err:         errs.NewErrorWrapper(errs.UserCredentials, errors.New("wrong password"), "sign in"),
+			status:      http.StatusUnauthorized,
+			errorCode:   CodeInvalidCredentials,
+			clientError: ErrCredentialsText,
+		},
+		{
+			name:        "not found",
+			err:         errs.NewErrorWrapper(errs.Database, errs.HandleErrorDB(sql.ErrNoRows), "error from user repo"),
+			status:      http.StatusNotFound,
+			errorCode:   CodeNotFound,
+			clientError: ErrNotFoundText,
+		},
+		{
+			name:        "outer message",
+			err:         errs.NewErrorWrapper(errs.Logic, errs.RecordInUse, "product is used in orders"),
+			status:      http.StatusConflict,
+			errorCode:   CodeConflict,
+			clientError: "product is used in orders",
+		},
+		{
+			name:        "inner message of unique violation",
+			err:         errs.NewErrorWrapper(errs.Database, errs.UniqueViolation("users_username_key", "username"), "error from user repo"),
+			status:      http.StatusConflict,
+			errorCode:   CodeAlreadyExists,
+			clientError: "username is already taken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := handleDomainError(tt.err)
+			assert.Equal(t, tt.status, res.Code)
+			assert.Equal(t, tt.errorCode, res.ErrorCode)
+			assert.Equal(t, tt.clientError, res.ClientError)
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		exp  []fieldError
+	}{
+		{
+			name: "flat",
+			err: validation.Errors{
+				"name":          validation.ErrRequired,
+				"left_in_stock": validation.ErrMinGreaterEqualThanRequired.SetParams(map[string]interface{}{"threshold": 0}),
+				"ok":            nil,
+			},
+			exp: []fieldError{
+				{Field: "left_in_stock", Code: "validation_min_greater_equal_than_required", Message: "must be no less than 0"},
+				{Field: "name", Code: "validation_required", Message: "cannot be blank"},
+			},
+		},
+		{
+			name: "nested",
+			err: validation.Errors{
+				"prices": validation.Errors{
+					"0": validation.Errors{"currency": validation.ErrLengthOutOfRange.SetParams(map[string]interface{}{"min": 3, "max": 3})},
+				},
+				"category_id": errors.New("incorrect UUID"),
+			},
+			exp: []fieldError{
+				{Field: "category_id", Message: "incorrect UUID"},
+				{Field: "prices.0.currency", Code: "validation_length_out_of_range", Message: "the length must be between 3 and 3"},
+			},
+		},
+		{
+			name: "constraint",
+			err:  errs.ForeignKeyViolation("products_category_id_fkey", "category_id"),
+			exp:  []fieldError{{Field: "category_id", Message: errs.BrokenRef.Error()}},
+		},
+		{
+			name: "constraint without field",
+			err:  errs.UniqueViolation("uq", ""),
+		},
+		{
+			name: "other",
+			err:  errors.New("boom"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exp, fieldErrors(tt.err))
+		})
+	}
+}
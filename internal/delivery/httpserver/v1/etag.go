@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Products, orders and profiles are sent with the version of the row in ETag header.
+// If-Match makes the update or removal conditional, If-None-Match allows to skip the body of unchanged resource.
+const (
+	etagHeader        = "ETag"
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+func setETag(c *gin.Context, version int) {
+	c.Header(etagHeader, strconv.Quote(strconv.Itoa(version)))
+}
+
+// parseETag returns the version from "N" or W/"N" tag. Weak tags are accepted for If-None-Match,
+// because proxies weaken the tags of compressed responses.
+func parseETag(tag string) (int, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// ifMatchVersion returns the version expected by client, 0 means the request is unconditional (no header or "*").
+func ifMatchVersion(c *gin.Context) (int, error) {
+	header := strings.TrimSpace(c.GetHeader(ifMatchHeader))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	version, ok := parseETag(header)
+	if !ok {
+		return 0, errs.NewErrorWrapper(errs.MalformedRequest, errors.New("malformed If-Match: "+header), ErrInputIfMatchText)
+	}
+	// If-Match uses the strong comparison (RFC 7232), a weak tag never matches
+	if strings.HasPrefix(header, "W/") {
+		return 0, errs.NewErrorWrapper(errs.PreconditionFailed, errors.New("weak If-Match: "+header), "weak ETag doesn't match in If-Match")
+	}
+	return version, nil
+}
+
+// notModified sets ETag of the resource and responds 304 if client already has this version.
+func notModified(c *gin.Context, version int) bool {
+	setETag(c, version)
+
+	header := c.GetHeader(ifNoneMatchHeader)
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == "*" {
+			c.AbortWithStatus(http.StatusNotModified)
+			return true
+		}
+		if v, ok := parseETag(tag); ok && v == version {
+			c.AbortWithStatus(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
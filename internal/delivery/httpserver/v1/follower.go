@@ -3,8 +3,6 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/service"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/user"
 	"net/http"
 )
 
@@ -40,10 +38,9 @@ func (ctrl *Controller) addFollower(c *gin.Context) {
 		return
 	}
 
-	encryptor := service.NewPasswordEncryptor()
-	uc := user.NewUserUseCase(ctrl.repos.Users, encryptor)
+	uc := ctrl.useCases.Users
 
-	err = uc.AddFollower(ctrl.ctx, input)
+	err = uc.AddFollower(c.Request.Context(), input)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
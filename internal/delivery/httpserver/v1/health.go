@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/health"
+	"net/http"
+)
+
+// livez - the process serves requests, dependencies aren't checked: restart doesn't help them.
+func (ctrl *Controller) livez(c *gin.Context) {
+	c.Status(http.StatusOK)
+}
+
+// readyz - the application can serve requests: dependencies are available and it's not shutting down.
+func (ctrl *Controller) readyz(c *gin.Context) {
+	report := ctrl.health.Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
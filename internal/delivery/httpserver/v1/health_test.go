@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/health"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbes(t *testing.T) {
+	var dbErr error
+	checker := health.New(0)
+	checker.Add("database", func(ctx context.Context) error { return dbErr })
+	r := NewController(usecase.UseCases{}, Health(checker)).ConfigureRoutes(&config.Config{})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, get("/readyz").Code)
+
+	dbErr = errors.New("connection refused")
+	rec := get("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.JSONEq(t, `{"status":"fail","checks":{"database":"connection refused"}}`, rec.Body.String())
+
+	// liveness doesn't depend on the database
+	require.Equal(t, http.StatusOK, get("/livez").Code)
+	require.Equal(t, http.StatusOK, get("/healthz").Code)
+
+	dbErr = nil
+	checker.Shutdown()
+	require.Equal(t, http.StatusServiceUnavailable, get("/readyz").Code)
+	require.Equal(t, http.StatusOK, get("/livez").Code)
+}
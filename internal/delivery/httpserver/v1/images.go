@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"io"
+	"net/http"
+)
+
+// UploadProductImage
+// @Summary Upload product image
+// @Security ApiKeyAuth
+// @Tags product
+// @Description upload JPEG, PNG or GIF image of product, thumbnail is generated
+// @ID product-image-upload
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param file formData file true "image file"
+// @Success 200 {object} entity.ProductImage
+// @Failure 400,404,413,422 {object} errorResponse
+// @Failure 500,503 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/images [post]
+func (ctrl *Controller) UploadProductImage(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	// multipart overhead is small, the exact file size is checked below
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ctrl.uploadMaxSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			newFileTooLargeResponse(c, ctrl.uploadMaxSize)
+			return
+		}
+		newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest, err, ErrInputFileText))
+		return
+	}
+	if fileHeader.Size > ctrl.uploadMaxSize {
+		newFileTooLargeResponse(c, ctrl.uploadMaxSize)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest, err, ErrInputFileText))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest, err, ErrInputFileText))
+		return
+	}
+
+	puc := ctrl.useCases.Products
+	if _, err = puc.GetByID(c.Request.Context(), id); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Images
+	img, err := uc.Upload(c.Request.Context(), id, fileHeader.Header.Get("Content-Type"), data)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, img)
+}
+
+func newFileTooLargeResponse(c *gin.Context, maxSize int64) {
+	newProblemResponse(c, errorHandlingDetails{
+		Code:        http.StatusRequestEntityTooLarge,
+		ErrorCode:   CodeFileTooLarge,
+		ClientError: fmt.Sprintf("%s: file is larger than %d bytes", ErrInputFileText, maxSize),
+	})
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"net/http"
+)
+
+// @Summary Get product stock movements
+// @Security ApiKeyAuth
+// @Tags inventory
+// @Description get inventory ledger of product
+// @ID product-stock-movements
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dataResponse{data=[]entity.StockMovement}
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/stock/movements [get]
+func (ctrl *Controller) getStockMovements(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	puc := ctrl.useCases.Products
+	if _, err := puc.GetByID(c.Request.Context(), id); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Inventory
+	movements, err := uc.GetMovements(c.Request.Context(), id)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	newDataResponse(c, *movements)
+}
+
+// @Summary Adjust product stock
+// @Security ApiKeyAuth
+// @Tags inventory
+// @Description register receipt, return or adjustment (signed quantity) of product stock
+// @ID product-stock-adjust
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param input body entity.StockAdjustmentInput true "movement data"
+// @Param Idempotency-Key header string false "retries with the same key get the first response"
+// @Success 200 {object} entity.StockMovement
+// @Failure 400,404,409,422 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/stock/adjustments [post]
+func (ctrl *Controller) addStockAdjustment(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	var input entity.StockAdjustmentInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, newJSONBindingErrorWrapper(err))
+		return
+	}
+
+	userID, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Inventory
+	m, err := uc.Adjust(c.Request.Context(), id, userID, input)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
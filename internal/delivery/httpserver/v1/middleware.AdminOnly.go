@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+)
+
+// adminOnly goes after userIdentity. Nobody passes it, if admins aren't configured.
+func (ctrl *Controller) adminOnly(c *gin.Context) {
+	userID, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	if _, ok := ctrl.admins[userID]; !ok {
+		newErrorResponse(c, errs.NewErrorWrapper(errs.NotPermitted, errors.New("user is not admin"), "adminOnly failure"))
+	}
+}
@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+
+	// the request context may be done after the handler (deadline, client has gone),
+	// but the key still must be released or completed
+	idempotencyFinishTimeout = 5 * time.Second
+)
+
+// responseRecorder keeps a copy of the response body.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response for retries of the request with the same Idempotency-Key header.
+// Requests without the header are processed as usual. Server errors are not stored, so they can be retried.
+func (ctrl *Controller) Idempotency(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return
+	}
+
+	userID, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	// the body is kept in memory for hashing, it's limited like uploads
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, ctrl.uploadMaxSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			newBodyTooLargeResponse(c, ctrl.uploadMaxSize)
+			return
+		}
+		newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest, err, ErrInputJSONText))
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	hash.Write(body)
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	uc := ctrl.useCases.Idempotency
+	stored, err := uc.Begin(c.Request.Context(), userID, key, requestHash)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+	if stored != nil {
+		c.Header(idempotencyReplayedHeader, "true")
+		c.Data(stored.StatusCode, stored.ContentType, stored.ResponseBody)
+		c.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+
+	finishCtx := func() (context.Context, context.CancelFunc) {
+		return context.WithTimeout(context.Background(), idempotencyFinishTimeout)
+	}
+
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		// handler failed or panicked
+		ctx, cancel := finishCtx()
+		defer cancel()
+		if err := uc.Release(ctx, userID, key); err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Str("idempotency_key", key).Msg("can't release idempotency key")
+		}
+	}()
+
+	c.Next()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		return
+	}
+
+	record := entity.IdempotencyRecord{
+		UserID:       userID,
+		Key:          key,
+		StatusCode:   recorder.Status(),
+		ContentType:  recorder.Header().Get("Content-Type"),
+		ResponseBody: recorder.body.Bytes(),
+	}
+	ctx, cancel := finishCtx()
+	defer cancel()
+	if err = uc.Complete(ctx, &record); err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Str("idempotency_key", key).Msg("can't store idempotent response")
+		return
+	}
+	completed = true
+}
+
+func newBodyTooLargeResponse(c *gin.Context, maxSize int64) {
+	newProblemResponse(c, errorHandlingDetails{
+		Code:        http.StatusRequestEntityTooLarge,
+		ErrorCode:   CodeBodyTooLarge,
+		ClientError: fmt.Sprintf("%s: body is larger than %d bytes", ErrInputJSONText, maxSize),
+	})
+}
@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// logRequest puts the logger of the request into its context, handlers and repositories take it by log.Ctx,
+// so their lines have the request ID. Successful requests of routes from samplers are sampled,
+// nil sampler skips them at all. Failed requests are always logged.
+func (ctrl *Controller) logRequest(samplers map[string]zerolog.Sampler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logCtx := log.Logger.With().Str("request_id", requestid.Get(c))
+		// lines are found by the trace, see traceRequest
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			logCtx = logCtx.Str("trace_id", sc.TraceID().String()).Str("span_id", sc.SpanID().String())
+		}
+		logger := logCtx.Logger()
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+
+		start := time.Now()
+		logger.Debug().
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("client_ip", c.ClientIP()).
+			Msg("request started")
+
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if sampler, ok := samplers[route]; ok && status < 400 {
+			if sampler == nil {
+				return
+			}
+			logger = logger.Sample(sampler)
+		}
+
+		lvl := zerolog.InfoLevel
+		if status >= 500 {
+			lvl = zerolog.ErrorLevel
+		}
+		// userIdentity has put user ID into the logger of the request, this one is before it
+		logger.WithLevel(lvl).
+			Str("method", c.Request.Method).
+			Str("route", route).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Int("bytes", c.Writer.Size()).
+			Str("user_id", c.GetString(userCtx)).
+			Str("client_ip", c.ClientIP()).
+			Msg("request completed")
+	}
+}
@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// logLines collects lines of the global logger, writes may be concurrent.
+type logLines struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *logLines) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *logLines) entries(t *testing.T) []map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var res []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(l.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		res = append(res, entry)
+	}
+	return res
+}
+
+func captureLog(t *testing.T) *logLines {
+	lines := &logLines{}
+	backup := log.Logger
+	log.Logger = zerolog.New(lines).Level(zerolog.InfoLevel)
+	t.Cleanup(func() { log.Logger = backup })
+	return lines
+}
+
+func completed(entries []map[string]interface{}) []map[string]interface{} {
+	var res []map[string]interface{}
+	for _, e := range entries {
+		if e["message"] == "request completed" {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+func TestLogRequestFields(t *testing.T) {
+	lines := captureLog(t)
+
+	r := NewController(usecase.UseCases{}).ConfigureRoutes(&config.Config{})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/orders/6f1e0a3c", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	entries := lines.entries(t)
+	require.Len(t, entries, 2, "the error response and the request")
+
+	requestID := rec.Header().Get("X-Request-ID")
+	for _, e := range entries {
+		require.Equal(t, requestID, e["request_id"])
+	}
+
+	require.Equal(t, "error response", entries[0]["message"])
+	require.Equal(t, CodeUnauthorized, entries[0]["error_code"])
+
+	e := entries[1]
+	require.Equal(t, "request completed", e["message"])
+	require.Equal(t, "GET", e["method"])
+	require.Equal(t, "/v1/orders/:id", e["route"])
+	require.Equal(t, "/v1/orders/6f1e0a3c", e["path"])
+	require.Equal(t, float64(http.StatusUnauthorized), e["status"])
+	require.Equal(t, float64(rec.Body.Len()), e["bytes"])
+	require.Contains(t, e, "latency")
+	require.Contains(t, e, "client_ip")
+}
+
+func TestLogRequestSampling(t *testing.T) {
+	lines := captureLog(t)
+
+	ctrl := NewController(usecase.UseCases{})
+	r := gin.New()
+	r.Use(ctrl.logRequest(map[string]zerolog.Sampler{
+		"/skipped": nil,
+		"/sampled": &zerolog.BasicSampler{N: 3},
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/skipped", ok)
+	r.GET("/sampled", ok)
+	r.GET("/failed", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	for i := 0; i < 6; i++ {
+		for _, path := range []string{"/skipped", "/sampled"} {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/failed", nil))
+
+	routes := map[string]int{}
+	for _, e := range completed(lines.entries(t)) {
+		routes[e["route"].(string)]++
+	}
+	require.Equal(t, map[string]int{"/sampled": 2, "/failed": 1}, routes)
+}
+
+func TestLogRequestConcurrent(t *testing.T) {
+	lines := captureLog(t)
+
+	ctrl := NewController(usecase.UseCases{})
+	r := gin.New()
+	r.Use(requestid.New(), ctrl.logRequest(nil))
+	r.GET("/work", func(c *gin.Context) {
+		log.Ctx(c.Request.Context()).Info().Str("query", c.Query("n")).Msg("working")
+		c.Status(http.StatusOK)
+	})
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := strconv.Itoa(i)
+			req := httptest.NewRequest(http.MethodGet, "/work?n="+id, nil)
+			req.Header.Set("X-Request-ID", "request-"+id)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+		}(i)
+	}
+	wg.Wait()
+
+	// every line of the handler has the ID of its own request
+	working := 0
+	for _, e := range lines.entries(t) {
+		if e["message"] != "working" {
+			continue
+		}
+		working++
+		require.Equal(t, "request-"+e["query"].(string), e["request_id"])
+	}
+	require.Equal(t, n, working)
+}
@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// unmatchedRoute labels requests to unknown paths, their paths aren't used as labels.
+const unmatchedRoute = "unmatched"
+
+func (ctrl *Controller) observeRequest(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	ctrl.metrics.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+}
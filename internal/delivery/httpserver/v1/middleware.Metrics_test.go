@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/metrics"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	ctrl := NewController(usecase.UseCases{}, Metrics(metrics.New()))
+	r := ctrl.ConfigureRoutes(&config.Config{Server: config.ServerConfig{InternalAddr: ":9090"}})
+
+	for _, path := range []string{"/healthz", "/healthz", "/v1/orders/6f1e0a3c", "/no/such/path"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	ctrl.ConfigureInternalRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	require.Contains(t, body, `orders_api_http_requests_total{method="GET",route="/healthz",status="200"} 2`)
+	// the pattern of route is the label, not identifiers from the path
+	require.Contains(t, body, `orders_api_http_requests_total{method="GET",route="/v1/orders/:id",status="401"} 1`)
+	require.Contains(t, body, `orders_api_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	require.Contains(t, body, `orders_api_http_request_duration_seconds_count{method="GET",route="/healthz",status="200"} 2`)
+	require.Contains(t, body, "go_goroutines")
+	require.Contains(t, body, "orders_api_orders_created_total 0")
+}
@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// timeout sets the deadline of the request context, it's propagated to use cases and repositories,
+// so the queries of too long or abandoned requests are cancelled. Routes are matched by the pattern
+// (c.FullPath), others get the default request timeout.
+func (ctrl *Controller) timeout(routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d, ok := routes[c.FullPath()]
+		if !ok {
+			d = ctrl.requestTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
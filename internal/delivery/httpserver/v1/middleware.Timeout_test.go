@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/product"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	timeoutProductID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+	slowQueryDelay   = 2 * time.Second
+)
+
+// newSlowProductsRouter serves the product, whose query is stuck in the database for slowQueryDelay.
+func newSlowProductsRouter(t *testing.T, opts ...Option) (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+		WithArgs(timeoutProductID).
+		WillDelayFor(slowQueryDelay).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(timeoutProductID))
+
+	repos := repository.Repository{Products: product.NewRepository(dbtx.New(db))}
+	ctrl := NewController(usecase.NewUseCases(repos, usecase.Deps{}), opts...)
+
+	r := gin.New()
+	r.Use(ctrl.timeout(nil))
+	r.GET("/products/:id", ctrl.GetProductByID)
+	return r, mock
+}
+
+func TestTimeoutCancelsQuery(t *testing.T) {
+	r, mock := newSlowProductsRouter(t, RequestTimeout(50*time.Millisecond))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/products/"+timeoutProductID, nil)
+
+	start := time.Now()
+	r.ServeHTTP(rec, req)
+
+	require.Less(t, time.Since(start), slowQueryDelay/2, "the query must be cancelled by the deadline")
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	var p errorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	require.Equal(t, CodeTimeout, p.Code)
+	require.Equal(t, ErrTimeoutText, p.Detail)
+}
+
+func TestClientCancelsQuery(t *testing.T) {
+	r, mock := newSlowProductsRouter(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/products/"+timeoutProductID, nil).WithContext(ctx)
+
+	start := time.Now()
+	r.ServeHTTP(rec, req)
+
+	require.Less(t, time.Since(start), slowQueryDelay/2, "the query must be cancelled with the request")
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var p errorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	require.Equal(t, CodeCanceled, p.Code)
+}
+
+func TestTimeoutOfRoute(t *testing.T) {
+	ctrl := NewController(usecase.UseCases{}, RequestTimeout(time.Second))
+
+	r := gin.New()
+	r.Use(ctrl.timeout(map[string]time.Duration{"/slow": time.Hour}))
+	deadline := func(c *gin.Context) {
+		d, ok := c.Request.Context().Deadline()
+		require.True(t, ok)
+		c.String(http.StatusOK, time.Until(d).Round(time.Minute).String())
+	}
+	r.GET("/slow", deadline)
+	r.GET("/fast", deadline)
+
+	for path, exp := range map[string]string{"/slow": "1h0m0s", "/fast": "0s"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, exp, rec.Body.String(), path)
+	}
+}
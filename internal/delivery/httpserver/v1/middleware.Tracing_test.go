@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/product"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTraceRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+		WithArgs(timeoutProductID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(timeoutProductID))
+
+	repos := repository.Repository{Products: product.NewRepository(dbtx.WithTracing(dbtx.New(db), tracing.Tracer()))}
+	useCases := usecase.WithTracing(usecase.NewUseCases(repos, usecase.Deps{}), tracing.Tracer())
+
+	r := gin.New()
+	ctrl := NewController(useCases)
+	r.Use(ctrl.traceRequest)
+	r.GET("/products/:id", ctrl.GetProductByID)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/products/"+timeoutProductID, nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	// spans are ended from the inner to the outer
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	query, call, server := spans[0], spans[1], spans[2]
+
+	require.Equal(t, "GET /products/:id", server.Name())
+	require.Equal(t, trace.SpanKindServer, server.SpanKind())
+	require.Equal(t, traceID, server.SpanContext().TraceID().String(), "the trace of the client is continued")
+	require.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+
+	require.Equal(t, "product.GetByID", call.Name())
+	require.Equal(t, server.SpanContext().SpanID(), call.Parent().SpanID())
+
+	require.Equal(t, "SELECT", query.Name())
+	require.Equal(t, trace.SpanKindClient, query.SpanKind())
+	require.Equal(t, call.SpanContext().SpanID(), query.Parent().SpanID())
+}
+
+func TestTraceRequestWithoutExporter(t *testing.T) {
+	// the application without exporter still passes the trace of the client to logs
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator()) })
+
+	ctrl := NewController(usecase.UseCases{})
+	r := ctrl.ConfigureRoutes(&config.Config{})
+	r.GET("/trace", func(c *gin.Context) {
+		c.String(http.StatusOK, trace.SpanContextFromContext(c.Request.Context()).TraceID().String())
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/trace", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, traceID, rec.Body.String())
+}
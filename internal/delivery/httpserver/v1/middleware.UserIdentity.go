@@ -4,7 +4,7 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/service"
+	"github.com/rs/zerolog/log"
 	"strings"
 )
 
@@ -31,14 +31,16 @@ func (ctrl *Controller) userIdentity(c *gin.Context) {
 		return
 	}
 
-	authTokenGenerator := service.AuthTokenGenerator{}
-	userId, err := authTokenGenerator.ParseToken(headerParts[1])
+	userId, err := ctrl.useCases.Tokens.ParseToken(headerParts[1])
 	if err != nil {
 		newErrorResponse(c, errs.NewErrorWrapper(errs.APIAuthorization, err, "userIdentity failure"))
 		return
 	}
 
 	c.Set(userCtx, userId)
+	// further lines of the request are about the user
+	logger := log.Ctx(c.Request.Context()).With().Str("user_id", userId).Logger()
+	c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
 }
 
 func getUserId(c *gin.Context) (string, error) {
@@ -3,8 +3,6 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/order"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/product"
 	"net/http"
 )
 
@@ -17,8 +15,9 @@ import (
 // @Produce  json
 // @Param input body entity.OrderProductView true "product data"
 // @Param id path string true "Order ID"
+// @Param Idempotency-Key header string false "retries with the same key get the first response"
 // @Success 200 {object} statusResponse
-// @Failure 400,403,404 {object} errorResponse
+// @Failure 400,403,404,409,422 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /orders/{id}/products [post]
@@ -41,8 +40,8 @@ func (ctrl *Controller) addOrderProduct(c *gin.Context) {
 		return
 	}
 
-	ouc := order.NewOrderUseCase(ctrl.repos.Orders)
-	o, err := ouc.GetByID(ctrl.ctx, orderID)
+	ouc := ctrl.useCases.Orders
+	o, err := ouc.GetByID(c.Request.Context(), orderID)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -53,19 +52,28 @@ func (ctrl *Controller) addOrderProduct(c *gin.Context) {
 		return
 	}
 
-	puc := product.NewProductUseCase(ctrl.repos.Products)
-	p, err := puc.GetByID(ctrl.ctx, input.ID)
+	puc := ctrl.useCases.Products
+	p, err := puc.GetByID(c.Request.Context(), input.ID)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
 	}
 
+	var v *entity.Variant
+	if input.VariantID != nil {
+		vuc := ctrl.useCases.Variants
+		if v, err = vuc.GetByID(c.Request.Context(), *input.VariantID); err != nil {
+			newErrorResponse(c, err)
+			return
+		}
+	}
+
 	op := entity.OrderProduct{
 		OrderID:   orderID,
 		ProductID: input.ID,
 		Amount:    input.Amount,
 	}
-	if err = ouc.AddProduct(ctrl.ctx, p, &op); err != nil {
+	if err = ouc.AddProduct(c.Request.Context(), p, v, &op); err != nil {
 		newErrorResponse(c, err)
 		return
 	}
@@ -99,8 +107,8 @@ func (ctrl *Controller) getAllOrderProducts(c *gin.Context) {
 		return
 	}
 
-	uc := order.NewOrderUseCase(ctrl.repos.Orders)
-	o, err := uc.GetByID(ctrl.ctx, id)
+	uc := ctrl.useCases.Orders
+	o, err := uc.GetByID(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -111,7 +119,7 @@ func (ctrl *Controller) getAllOrderProducts(c *gin.Context) {
 		return
 	}
 
-	orders, err := uc.GetAllOrderProducts(ctrl.ctx, o.ID)
+	orders, err := uc.GetAllOrderProducts(c.Request.Context(), o.ID)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -129,6 +137,7 @@ func (ctrl *Controller) getAllOrderProducts(c *gin.Context) {
 // @Produce  json
 // @Param id path string true "Order ID"
 // @Param productID path string true "Product ID"
+// @Param variant_id query string false "Variant ID, if the line is of product variant"
 // @Success 200 {object} statusResponse
 // @Failure 400,404 {object} errorResponse
 // @Failure 500 {object} errorResponse
@@ -148,9 +157,9 @@ func (ctrl *Controller) deleteOrderProduct(c *gin.Context) {
 		return
 	}
 
-	uc := order.NewOrderUseCase(ctrl.repos.Orders)
+	uc := ctrl.useCases.Orders
 
-	o, err := uc.GetByID(ctrl.ctx, id)
+	o, err := uc.GetByID(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -161,7 +170,12 @@ func (ctrl *Controller) deleteOrderProduct(c *gin.Context) {
 		return
 	}
 
-	if err := uc.RemoveProduct(ctrl.ctx, id, productID); err != nil {
+	var variantID *string
+	if v := c.Query("variant_id"); v != "" {
+		variantID = &v
+	}
+
+	if err := uc.RemoveProduct(c.Request.Context(), id, productID, variantID); err != nil {
 		newErrorResponse(c, err)
 		return
 	}
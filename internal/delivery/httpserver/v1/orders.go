@@ -3,20 +3,20 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/order"
 	"net/http"
 )
 
 // @Summary Create order
 // @Security ApiKeyAuth
 // @Tags order
-// @Description Create order
+// @Description Create order, with products it's created only if all of them are reserved
 // @ID order-create
 // @Accept  json
 // @Produce  json
 // @Param input body entity.Order true "order data"
+// @Param Idempotency-Key header string false "retries with the same key get the first response"
 // @Success 200 {string} string "id"
-// @Failure 400,403,404 {object} errorResponse
+// @Failure 400,403,404,409,422 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /orders [post]
@@ -39,8 +39,8 @@ func (ctrl *Controller) createOrder(c *gin.Context) {
 		return
 	}
 
-	uc := order.NewOrderUseCase(ctrl.repos.Orders)
-	id, err := uc.Create(ctrl.ctx, input)
+	uc := ctrl.useCases.Orders
+	id, _, err := uc.Create(c.Request.Context(), input)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -59,7 +59,10 @@ func (ctrl *Controller) createOrder(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Order ID"
+// @Param If-None-Match header string false "ETag of the cached order"
 // @Success 200 {object} entity.Order
+// @Header 200 {string} ETag "order version"
+// @Success 304 "order is not changed"
 // @Failure 400,403,404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
@@ -77,8 +80,8 @@ func (ctrl *Controller) getOrderByID(c *gin.Context) {
 		return
 	}
 
-	uc := order.NewOrderUseCase(ctrl.repos.Orders)
-	o, err := uc.GetByID(ctrl.ctx, id)
+	uc := ctrl.useCases.Orders
+	o, err := uc.GetByID(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -89,6 +92,10 @@ func (ctrl *Controller) getOrderByID(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, o.Version) {
+		return
+	}
+
 	c.JSON(http.StatusOK, o)
 }
 
@@ -111,8 +118,8 @@ func (ctrl *Controller) getAllOrders(c *gin.Context) {
 		return
 	}
 
-	uc := order.NewOrderUseCase(ctrl.repos.Orders)
-	orders, err := uc.GetAllByUserID(ctrl.ctx, userId)
+	uc := ctrl.useCases.Orders
+	orders, err := uc.GetAllByUserID(c.Request.Context(), userId)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -130,8 +137,10 @@ func (ctrl *Controller) getAllOrders(c *gin.Context) {
 // @Produce  json
 // @Param id path string true "Order ID"
 // @Param input body entity.Order true "order updating data"
+// @Param If-Match header string false "ETag of the order version to update"
 // @Success 200 {object} statusResponse
-// @Failure 400,404 {object} errorResponse
+// @Header 200 {string} ETag "new order version"
+// @Failure 400,404,409,412 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /orders/{id} [put]
@@ -156,9 +165,14 @@ func (ctrl *Controller) updateOrderByID(c *gin.Context) {
 	}
 	input.ID = id
 
-	uc := order.NewOrderUseCase(ctrl.repos.Orders)
+	if input.Version, err = ifMatchVersion(c); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Orders
 
-	o, err := uc.GetByID(ctrl.ctx, id)
+	o, err := uc.GetByID(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -169,11 +183,13 @@ func (ctrl *Controller) updateOrderByID(c *gin.Context) {
 		return
 	}
 
-	if err = uc.Update(ctrl.ctx, input); err != nil {
+	version, err := uc.Update(c.Request.Context(), input)
+	if err != nil {
 		newErrorResponse(c, err)
 		return
 	}
 
+	setETag(c, version)
 	c.JSON(http.StatusOK, statusResponse{true})
 }
 
@@ -185,8 +201,9 @@ func (ctrl *Controller) updateOrderByID(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Order ID"
+// @Param If-Match header string false "ETag of the order version to delete"
 // @Success 200 {object} statusResponse
-// @Failure 400,404 {object} errorResponse
+// @Failure 400,404,412 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /orders/{id} [delete]
@@ -203,9 +220,15 @@ func (ctrl *Controller) deleteOrderByID(c *gin.Context) {
 		return
 	}
 
-	uc := order.NewOrderUseCase(ctrl.repos.Orders)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Orders
 
-	o, err := uc.GetByID(ctrl.ctx, id)
+	o, err := uc.GetByID(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -216,7 +239,7 @@ func (ctrl *Controller) deleteOrderByID(c *gin.Context) {
 		return
 	}
 
-	if err = uc.Remove(ctrl.ctx, id); err != nil {
+	if err = uc.Remove(c.Request.Context(), id, version); err != nil {
 		newErrorResponse(c, err)
 		return
 	}
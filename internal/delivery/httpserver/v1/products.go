@@ -3,7 +3,7 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/product"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
 	"net/http"
 )
 
@@ -17,7 +17,7 @@ import (
 // @Produce  json
 // @Param input body entity.Product true "product data"
 // @Success 200 {string} string "id"
-// @Failure 400,404 {object} errorResponse
+// @Failure 400,404,409,422 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /products [post]
@@ -29,8 +29,8 @@ func (ctrl *Controller) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	uc := product.NewProductUseCase(ctrl.repos.Products)
-	id, err := uc.CreateWithPrices(ctrl.ctx, input)
+	uc := ctrl.useCases.Products
+	id, err := uc.CreateWithPrices(c.Request.Context(), input)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -45,12 +45,15 @@ func (ctrl *Controller) CreateProduct(c *gin.Context) {
 // @Summary Get product
 // @Security ApiKeyAuth
 // @Tags product
-// @Description get product
+// @Description get product with prices and images
 // @ID product-get
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Product ID"
+// @Param If-None-Match header string false "ETag of the cached product"
 // @Success 200 {object} entity.Product
+// @Header 200 {string} ETag "product version"
+// @Success 304 "product is not changed"
 // @Failure 400,404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
@@ -62,12 +65,25 @@ func (ctrl *Controller) GetProductByID(c *gin.Context) {
 		return
 	}
 
-	uc := product.NewProductUseCase(ctrl.repos.Products)
-	p, err := uc.GetByID(ctrl.ctx, id)
+	uc := ctrl.useCases.Products
+	p, err := uc.GetByID(c.Request.Context(), id)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	// prices and images change the product version too, so the cached body is still actual
+	if notModified(c, p.Version) {
+		return
+	}
+
+	iuc := ctrl.useCases.Images
+	images, err := iuc.GetAllByProductID(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
 	}
+	p.Images = *images
 
 	c.JSON(http.StatusOK, p)
 }
@@ -75,18 +91,36 @@ func (ctrl *Controller) GetProductByID(c *gin.Context) {
 // @Summary Get all products
 // @Security ApiKeyAuth
 // @Tags product
-// @Description get all products
+// @Description get all products, filtered by category (with subcategories) and tag
 // @ID product-get-all
 // @Accept  json
 // @Produce  json
+// @Param category query string false "Category ID or slug"
+// @Param tag query string false "Tag"
 // @Success 200 {object} dataResponse
 // @Failure 400,404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /products [get]
 func (ctrl *Controller) getAllProducts(c *gin.Context) {
-	uc := product.NewProductUseCase(ctrl.repos.Products)
-	products, err := uc.GetAll(ctrl.ctx)
+	var filter entity.ProductFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest, err, ErrInputQueryText))
+		return
+	}
+
+	if filter.Category != "" {
+		cuc := ctrl.useCases.Categories
+		ids, err := cuc.GetSubtreeIDs(c.Request.Context(), filter.Category)
+		if err != nil {
+			newErrorResponse(c, err)
+			return
+		}
+		filter.CategoryIDs = ids
+	}
+
+	uc := ctrl.useCases.Products
+	products, err := uc.GetAll(c.Request.Context(), filter)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
@@ -95,21 +129,24 @@ func (ctrl *Controller) getAllProducts(c *gin.Context) {
 	newDataResponse(c, *products)
 }
 
+// UpdateProductByID
 // @Summary Update product
 // @Security ApiKeyAuth
 // @Tags product
-// @Description update product (don't updates prices - todo)
+// @Description update product (stock is changed by stock adjustments, don't updates prices - todo)
 // @ID product-update
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Product ID"
 // @Param input body entity.ProductUpdateInput true "product updating data"
+// @Param If-Match header string false "ETag of the product version to update"
 // @Success 200 {object} statusResponse
-// @Failure 400,404 {object} errorResponse
+// @Header 200 {string} ETag "new product version"
+// @Failure 400,404,412 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /products/{id} [put]
-func (ctrl *Controller) updateProductByID(c *gin.Context) {
+func (ctrl *Controller) UpdateProductByID(c *gin.Context) {
 	var input entity.ProductUpdateInput
 
 	id := c.Param("id")
@@ -123,37 +160,111 @@ func (ctrl *Controller) updateProductByID(c *gin.Context) {
 		return
 	}
 
-	uc := product.NewProductUseCase(ctrl.repos.Products)
-	if err := uc.Update(ctrl.ctx, id, input); err != nil {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Products
+	version, err = uc.Update(c.Request.Context(), id, input, version)
+	if err != nil {
 		newErrorResponse(c, err)
 		return
 	}
 
+	setETag(c, version)
 	c.JSON(http.StatusOK, statusResponse{true})
 }
 
+// DeleteProductByID
 // @Summary Delete product
 // @Security ApiKeyAuth
 // @Tags product
-// @Description delete product
+// @Description delete product, which was never ordered (otherwise archive it)
 // @ID product-delete
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Product ID"
+// @Param If-Match header string false "ETag of the product version to delete"
 // @Success 200 {object} statusResponse
-// @Failure 400,404 {object} errorResponse
+// @Failure 400,404,409,412 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /products/{id} [delete]
-func (ctrl *Controller) deleteProductByID(c *gin.Context) {
+func (ctrl *Controller) DeleteProductByID(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Products
+	if err := uc.Remove(c.Request.Context(), id, version); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{true})
+}
+
+// @Summary Archive product
+// @Security ApiKeyAuth
+// @Tags product
+// @Description hide product from listings and ordering, it stays available in orders history
+// @ID product-archive
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} statusResponse
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/archive [post]
+func (ctrl *Controller) archiveProductByID(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	uc := ctrl.useCases.Products
+	if err := uc.Archive(c.Request.Context(), id); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{true})
+}
+
+// @Summary Restore product
+// @Security ApiKeyAuth
+// @Tags product
+// @Description restore archived product
+// @ID product-restore
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} statusResponse
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/restore [post]
+func (ctrl *Controller) restoreProductByID(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
 		newErrorResponse(c, emptyParameterID)
 		return
 	}
 
-	uc := product.NewProductUseCase(ctrl.repos.Products)
-	if err := uc.Remove(ctrl.ctx, id); err != nil {
+	uc := ctrl.useCases.Products
+	if err := uc.Restore(c.Request.Context(), id); err != nil {
 		newErrorResponse(c, err)
 		return
 	}
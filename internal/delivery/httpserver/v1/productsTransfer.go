@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/product"
+	"github.com/rs/zerolog/log"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+var transferContentTypes = map[string]string{
+	product.FormatCSV:    "text/csv",
+	product.FormatNDJSON: "application/x-ndjson",
+}
+
+// ImportProducts
+// @Summary Import products
+// @Security ApiKeyAuth
+// @Tags product
+// @Description import products from CSV (columns name, description, left_in_stock, category_id, price_<CURRENCY>)
+// @Description or JSON Lines (product object per line). Nothing is stored if any row is invalid or storing of any row fails.
+// @ID product-import
+// @Accept  text/csv,application/x-ndjson
+// @Produce  json
+// @Param format query string false "csv or ndjson, by default it's detected by Content-Type"
+// @Param dry_run query bool false "only validate rows"
+// @Success 200 {object} dataResponse{data=entity.ProductImportResult}
+// @Failure 400,413,422 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/import [post]
+func (ctrl *Controller) ImportProducts(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+		for f, ct := range transferContentTypes {
+			if ct == mediaType {
+				format = f
+			}
+		}
+		if mediaType == "application/jsonl" {
+			format = product.FormatNDJSON
+		}
+	}
+	if _, ok := transferContentTypes[format]; !ok {
+		newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest,
+			errors.New("unknown import format"), ErrInputQueryText+": format must be csv or ndjson"))
+		return
+	}
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest, err, ErrInputQueryText))
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, ctrl.importMaxSize)
+	rows, err := product.DecodeImport(body, format)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			newFileTooLargeResponse(c, ctrl.importMaxSize)
+			return
+		}
+		newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest, err, ErrInputFileText+": "+err.Error()))
+		return
+	}
+
+	uc := ctrl.useCases.Products
+	res, err := uc.Import(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	if len(res.Errors) > 0 {
+		newImportProblemResponse(c, res)
+		return
+	}
+	newDataResponse(c, res)
+}
+
+// newImportProblemResponse lists invalid rows in errors of the problem, the field is rows.<line>.
+func newImportProblemResponse(c *gin.Context, res *entity.ProductImportResult) {
+	fields := make([]fieldError, 0, len(res.Errors))
+	for _, e := range res.Errors {
+		fields = append(fields, fieldError{Field: fmt.Sprintf("rows.%d", e.Row), Message: e.Error})
+	}
+	newProblemResponse(c, errorHandlingDetails{
+		Code:        http.StatusUnprocessableEntity,
+		ErrorCode:   CodeValidation,
+		ClientError: fmt.Sprintf("%s: %d of %d rows are invalid, nothing is imported", ErrValidationText, len(res.Errors), res.Total),
+		Fields:      fields,
+	})
+}
+
+// @Summary Export products
+// @Security ApiKeyAuth
+// @Tags product
+// @Description stream all not archived products with prices as CSV or JSON Lines
+// @ID product-export
+// @Produce  text/csv,application/x-ndjson
+// @Param format query string false "csv (default) or ndjson"
+// @Success 200 {string} string "products"
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/export [get]
+func (ctrl *Controller) exportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", product.FormatCSV)
+	contentType, ok := transferContentTypes[format]
+	if !ok {
+		newErrorResponse(c, errs.NewErrorWrapper(errs.MalformedRequest,
+			errors.New("unknown export format"), ErrInputQueryText+": format must be csv or ndjson"))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="products.`+format+`"`)
+
+	uc := ctrl.useCases.Products
+	if err := uc.Export(c.Request.Context(), c.Writer, format); err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Disposition", "")
+			newErrorResponse(c, err)
+			return
+		}
+		// the status is already sent, so the client gets truncated file
+		log.Ctx(c.Request.Context()).Error().Err(err).Str("format", format).Msg("products export is interrupted")
+		c.Abort()
+	}
+}
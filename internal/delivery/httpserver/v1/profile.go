@@ -3,7 +3,6 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
-	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/profile"
 	"net/http"
 )
 
@@ -16,7 +15,7 @@ import (
 // @Produce  json
 // @Param input body entity.Profile true "profile data"
 // @Success 200 {string} string "id"
-// @Failure 400,404 {object} errorResponse
+// @Failure 400,404,409 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /profiles/my [post]
@@ -35,8 +34,8 @@ func (ctrl *Controller) createMyProfile(c *gin.Context) {
 	}
 	input.UserID = userId
 
-	uc := profile.NewProfileUseCase(ctrl.repos.Profiles)
-	if err := uc.Create(ctrl.ctx, input); err != nil {
+	uc := ctrl.useCases.Profiles
+	if err := uc.Create(c.Request.Context(), input); err != nil {
 		newErrorResponse(c, err)
 		return
 	}
@@ -52,7 +51,10 @@ func (ctrl *Controller) createMyProfile(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Profile ID"
+// @Param If-None-Match header string false "ETag of the cached profile"
 // @Success 200 {object} entity.Profile
+// @Header 200 {string} ETag "profile version"
+// @Success 304 "profile is not changed"
 // @Failure 400,404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
@@ -64,12 +66,16 @@ func (ctrl *Controller) getProfile(c *gin.Context) {
 		return
 	}
 
-	p, err := ctrl.repos.Profiles.GetByUserID(ctrl.ctx, id)
+	p, err := ctrl.useCases.Profiles.GetByUserID(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
 	}
 
+	if notModified(c, p.Version) {
+		return
+	}
+
 	c.JSON(http.StatusOK, p)
 }
 
@@ -80,7 +86,10 @@ func (ctrl *Controller) getProfile(c *gin.Context) {
 // @ID profile-get-my
 // @Accept  json
 // @Produce  json
+// @Param If-None-Match header string false "ETag of the cached profile"
 // @Success 200 {object} entity.Profile
+// @Header 200 {string} ETag "profile version"
+// @Success 304 "profile is not changed"
 // @Failure 400,404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
@@ -92,12 +101,16 @@ func (ctrl *Controller) getMyProfile(c *gin.Context) {
 		return
 	}
 
-	p, err := ctrl.repos.Profiles.GetByUserID(ctrl.ctx, userId)
+	p, err := ctrl.useCases.Profiles.GetByUserID(c.Request.Context(), userId)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
 	}
 
+	if notModified(c, p.Version) {
+		return
+	}
+
 	c.JSON(http.StatusOK, p)
 }
 
@@ -110,8 +123,10 @@ func (ctrl *Controller) getMyProfile(c *gin.Context) {
 // @Produce  json
 // @Param id path string true "Profile ID"
 // @Param input body entity.Profile true "profile data"
+// @Param If-Match header string false "ETag of the profile version to update"
 // @Success 200 {object} statusResponse
-// @Failure 400,404 {object} errorResponse
+// @Header 200 {string} ETag "new profile version"
+// @Failure 400,404,412 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /profiles/{id} [put]
@@ -141,11 +156,17 @@ func (ctrl *Controller) updateProfile(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.repos.Profiles.Update(ctrl.ctx, &input)
+	if input.Version, err = ifMatchVersion(c); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	version, err := ctrl.useCases.Profiles.Update(c.Request.Context(), input)
 	if err != nil {
 		newErrorResponse(c, err)
 		return
 	}
 
+	setETag(c, version)
 	c.JSON(http.StatusOK, statusResponse{true})
 }
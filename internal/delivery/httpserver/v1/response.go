@@ -1,14 +1,35 @@
 package v1
 
 import (
+	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"net/http"
 )
 
+// ProblemContentType of error responses, see RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBaseURI of the problem types, the error code is appended to it.
+const ProblemTypeBaseURI = "https://github.com/linkuha/test-golang-rest-orders-api/blob/master/docs/problems.md#"
+
+// errorResponse is the problem details object (RFC 7807) extended by the error code,
+// the request ID and errors of the fields.
 type errorResponse struct {
-	Success bool   `json:"ok"`
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []fieldError `json:"errors,omitempty"`
+}
+
+type fieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code,omitempty"`
 	Message string `json:"message"`
 }
 
@@ -22,7 +43,7 @@ type dataResponse struct {
 }
 
 func newErrorResponse(c *gin.Context, err error) {
-	errDetails := handleDomainError(err)
+	errDetails := handleDomainError(withContextError(c.Request.Context(), err))
 
 	var lvl zerolog.Level
 	switch {
@@ -33,10 +54,32 @@ func newErrorResponse(c *gin.Context, err error) {
 	default:
 		lvl = zerolog.DebugLevel
 	}
-	log.WithLevel(lvl).Msgf("client error: %s", errDetails.ClientError)
-	log.Info().Msgf("internal error: %s", errDetails.DebugError)
+	log.Ctx(c.Request.Context()).WithLevel(lvl).
+		Int("status", errDetails.Code).
+		Str("error_code", errDetails.ErrorCode).
+		Str("client_error", errDetails.ClientError).
+		Str("internal_error", errDetails.DebugError).
+		Msg("error response")
 
-	c.AbortWithStatusJSON(errDetails.Code, errorResponse{Success: false, Message: errDetails.ClientError})
+	newProblemResponse(c, errDetails)
+}
+
+func newProblemResponse(c *gin.Context, details errorHandlingDetails) {
+	if details.Code == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", "Bearer")
+	}
+	// JSON render keeps the content type which is set before
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(details.Code, errorResponse{
+		Type:      ProblemTypeBaseURI + details.ErrorCode,
+		Title:     http.StatusText(details.Code),
+		Status:    details.Code,
+		Detail:    details.ClientError,
+		Instance:  c.Request.URL.Path,
+		Code:      details.ErrorCode,
+		RequestID: requestid.Get(c),
+		Errors:    details.Fields,
+	})
 }
 
 func newDataResponse(c *gin.Context, data interface{}) {
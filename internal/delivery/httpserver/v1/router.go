@@ -5,9 +5,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkuha/test-golang-rest-orders-api/config"
 	docs "github.com/linkuha/test-golang-rest-orders-api/docs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/storage"
+	"github.com/rs/zerolog"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"time"
 )
 
 // ConfigureRoutes -.
@@ -21,24 +25,48 @@ import (
 // @name Authorization
 func (ctrl *Controller) ConfigureRoutes(cfg *config.Config) *gin.Engine {
 	// Swagger
-	docs.SwaggerInfo.Host = cfg.EnvParams.Host
+	docs.SwaggerInfo.Host = cfg.Server.Host
 
 	router := gin.New()
-	router.Use(requestid.New(), ctrl.customLogRequest)
-	//router.Use(gin.LoggerWithWriter(log.Logger, "/status", "/healthz"))
+	router.Use(requestid.New(), ctrl.traceRequest, ctrl.logRequest(operationalSamplers()))
+	if ctrl.metrics != nil {
+		// before Recovery, so panics are counted as 500
+		router.Use(ctrl.observeRequest)
+	}
 	router.Use(gin.Recovery())
+	router.Use(ctrl.timeout(map[string]time.Duration{
+		"/v1/products/import":     ctrl.longRequestTimeout,
+		"/v1/products/export":     ctrl.longRequestTimeout,
+		"/v1/products/:id/images": ctrl.longRequestTimeout,
+	}))
 
-	if cfg.EnvParams.Env == "production" {
+	if cfg.App.Env == config.EnvProduction {
 		gin.SetMode(gin.ReleaseMode)
 	}
-	swaggerHandler := ginSwagger.DisablingWrapHandler(swaggerFiles.Handler, "DISABLE_SWAGGER_HTTP_HANDLER")
-	router.GET("/swagger/*any", swaggerHandler)
+	if !cfg.Features.DisableSwagger {
+		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
 
-	// Load status
-	router.GET("/status", ctrl.status)
+	// Operational endpoints are on the internal listener, if it's configured
+	if cfg.Server.InternalAddr == "" {
+		// Load status and metrics tell too much about the server for everyone
+		router.GET("/status", ctrl.userIdentity, ctrl.adminOnly, ctrl.status)
+		if ctrl.metrics != nil {
+			router.GET("/metrics", ctrl.userIdentity, ctrl.adminOnly, gin.WrapH(ctrl.metrics.Handler()))
+		}
+	}
+
+	// Uploaded files, when they are kept locally
+	if local, ok := ctrl.storage.(*storage.Local); ok {
+		if u, err := url.Parse(local.URL("")); err == nil && u.Path != "/" {
+			router.Static(u.Path, local.Dir())
+		}
+	}
 
-	// K8s probe
-	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	// K8s probes, /healthz is the old name of liveness
+	router.GET("/healthz", ctrl.livez)
+	router.GET("/livez", ctrl.livez)
+	router.GET("/readyz", ctrl.readyz)
 
 	v1 := router.Group("/v1")
 	{
@@ -67,14 +95,40 @@ func (ctrl *Controller) ConfigureRoutes(cfg *config.Config) *gin.Engine {
 			{
 				products.POST("/", ctrl.CreateProduct)
 				products.GET("/", ctrl.getAllProducts)
+				products.POST("/import", ctrl.ImportProducts)
+				products.GET("/export", ctrl.exportProducts)
 				products.GET("/:id", ctrl.GetProductByID)
-				products.PUT("/:id", ctrl.updateProductByID)
-				products.DELETE("/:id", ctrl.deleteProductByID)
+				products.PUT("/:id", ctrl.UpdateProductByID)
+				products.DELETE("/:id", ctrl.DeleteProductByID)
+				products.POST("/:id/archive", ctrl.archiveProductByID)
+				products.POST("/:id/restore", ctrl.restoreProductByID)
+				products.GET("/:id/tags", ctrl.getProductTags)
+				products.PUT("/:id/tags", ctrl.setProductTags)
+				products.POST("/:id/images", ctrl.UploadProductImage)
+				products.POST("/:id/variants", ctrl.createVariant)
+				products.GET("/:id/variants", ctrl.getAllVariants)
+				products.GET("/:id/variants/:variantID", ctrl.getVariantByID)
+				products.PUT("/:id/variants/:variantID", ctrl.updateVariantByID)
+				products.DELETE("/:id/variants/:variantID", ctrl.deleteVariantByID)
+				products.PUT("/:id/variants/:variantID/prices", ctrl.setVariantPrice)
+				products.GET("/:id/stock/movements", ctrl.getStockMovements)
+				products.POST("/:id/stock/adjustments", ctrl.Idempotency, ctrl.addStockAdjustment)
+			}
+
+			categories := api.Group("/categories")
+			{
+				categories.POST("/", ctrl.createCategory)
+				categories.GET("/", ctrl.getAllCategories)
+				categories.GET("/:id", ctrl.getCategoryByID)
+				categories.PUT("/:id", ctrl.updateCategoryByID)
+				categories.DELETE("/:id", ctrl.deleteCategoryByID)
 			}
 
+			api.GET("/tags", ctrl.getAllTags)
+
 			orders := api.Group("/orders")
 			{
-				orders.POST("/", ctrl.createOrder)
+				orders.POST("/", ctrl.Idempotency, ctrl.createOrder)
 				orders.GET("/", ctrl.getAllOrders)
 				orders.GET("/:id", ctrl.getOrderByID)
 				orders.PUT("/:id", ctrl.updateOrderByID)
@@ -82,7 +136,7 @@ func (ctrl *Controller) ConfigureRoutes(cfg *config.Config) *gin.Engine {
 
 				orderProducts := orders.Group(":id/products")
 				{
-					orderProducts.POST("/", ctrl.addOrderProduct)
+					orderProducts.POST("/", ctrl.Idempotency, ctrl.addOrderProduct)
 					orderProducts.GET("/", ctrl.getAllOrderProducts)
 					orderProducts.DELETE("/:productID", ctrl.deleteOrderProduct)
 				}
@@ -92,3 +146,44 @@ func (ctrl *Controller) ConfigureRoutes(cfg *config.Config) *gin.Engine {
 
 	return router
 }
+
+// ConfigureInternalRoutes - operational endpoints for the network of the infrastructure: status, metrics,
+// pprof and probes. They aren't authorized, the listener must not be published.
+func (ctrl *Controller) ConfigureInternalRoutes() *gin.Engine {
+	router := gin.New()
+	router.Use(requestid.New(), ctrl.logRequest(operationalSamplers()), gin.Recovery())
+
+	router.GET("/status", ctrl.status)
+	if ctrl.metrics != nil {
+		router.GET("/metrics", gin.WrapH(ctrl.metrics.Handler()))
+	}
+
+	router.GET("/healthz", ctrl.livez)
+	router.GET("/livez", ctrl.livez)
+	router.GET("/readyz", ctrl.readyz)
+
+	debug := router.Group("/debug/pprof")
+	{
+		debug.GET("/", gin.WrapF(pprof.Index))
+		debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/profile", gin.WrapF(pprof.Profile))
+		debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+		debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/trace", gin.WrapF(pprof.Trace))
+		// heap, goroutine, allocs, block, mutex, threadcreate
+		debug.GET("/:profile", gin.WrapF(pprof.Index))
+	}
+
+	return router
+}
+
+// operationalSamplers - probes and scrapes are frequent, their lines would bury the others.
+func operationalSamplers() map[string]zerolog.Sampler {
+	return map[string]zerolog.Sampler{
+		"/healthz": nil,
+		"/livez":   nil,
+		"/readyz":  nil,
+		"/metrics": nil,
+		"/status":  &zerolog.BasicSampler{N: 10},
+	}
+}
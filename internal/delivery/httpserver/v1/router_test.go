@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/metrics"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInternalRoutes(t *testing.T) {
+	ctrl := NewController(usecase.UseCases{}, Metrics(metrics.New()))
+	public := ctrl.ConfigureRoutes(&config.Config{Server: config.ServerConfig{InternalAddr: ":9090"}})
+	internal := ctrl.ConfigureInternalRoutes()
+
+	get := func(r http.Handler, path string) int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	// operational endpoints aren't authorized on the internal listener
+	for _, path := range []string{"/status", "/metrics", "/livez", "/readyz", "/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/cmdline"} {
+		require.Equal(t, http.StatusOK, get(internal, path), path)
+	}
+
+	for _, path := range []string{"/status", "/metrics", "/debug/pprof/"} {
+		require.Equal(t, http.StatusNotFound, get(public, path), path)
+	}
+	require.Equal(t, http.StatusOK, get(public, "/readyz"), "load balancers check the public port")
+}
+
+func TestOperationalRoutesWithoutInternalListener(t *testing.T) {
+	r := NewController(usecase.UseCases{}, Metrics(metrics.New())).ConfigureRoutes(&config.Config{})
+
+	for _, path := range []string{"/status", "/metrics"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusUnauthorized, rec.Code, path)
+	}
+}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+)
+
+// @Summary Get all tags
+// @Security ApiKeyAuth
+// @Tags tag
+// @Description get all tags used by products
+// @ID tag-get-all
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse{data=[]string}
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /tags [get]
+func (ctrl *Controller) getAllTags(c *gin.Context) {
+	uc := ctrl.useCases.Tags
+	tags, err := uc.GetAll(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	newDataResponse(c, tags)
+}
+
+// @Summary Get product tags
+// @Security ApiKeyAuth
+// @Tags tag
+// @Description get tags of product
+// @ID product-tags-get
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dataResponse{data=[]string}
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/tags [get]
+func (ctrl *Controller) getProductTags(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	puc := ctrl.useCases.Products
+	if _, err := puc.GetByID(c.Request.Context(), id); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Tags
+	tags, err := uc.GetByProductID(c.Request.Context(), id)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	newDataResponse(c, tags)
+}
+
+// @Summary Set product tags
+// @Security ApiKeyAuth
+// @Tags tag
+// @Description replace tags of product
+// @ID product-tags-set
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param input body entity.ProductTagsInput true "tags"
+// @Success 200 {object} dataResponse{data=[]string}
+// @Failure 400,404,422 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/tags [put]
+func (ctrl *Controller) setProductTags(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	var input entity.ProductTagsInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, newJSONBindingErrorWrapper(err))
+		return
+	}
+
+	puc := ctrl.useCases.Products
+	if _, err := puc.GetByID(c.Request.Context(), id); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Tags
+	tags, err := uc.SetForProduct(c.Request.Context(), id, input)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	newDataResponse(c, tags)
+}
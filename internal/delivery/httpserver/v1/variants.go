@@ -0,0 +1,243 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"net/http"
+)
+
+// @Summary Create product variant
+// @Security ApiKeyAuth
+// @Tags variant
+// @Description create variant of product with own SKU, attributes, stock and prices
+// @ID variant-create
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param input body entity.Variant true "variant data"
+// @Success 200 {string} string "id"
+// @Failure 400,404,409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/variants [post]
+func (ctrl *Controller) createVariant(c *gin.Context) {
+	productID := c.Param("id")
+	if productID == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	var input entity.Variant
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, newJSONBindingErrorWrapper(err))
+		return
+	}
+
+	puc := ctrl.useCases.Products
+	if _, err := puc.GetByID(c.Request.Context(), productID); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	input.ProductID = productID
+	uc := ctrl.useCases.Variants
+	id, err := uc.Create(c.Request.Context(), input)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// @Summary Get product variants
+// @Security ApiKeyAuth
+// @Tags variant
+// @Description get all variants of product
+// @ID variant-get-all
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dataResponse{data=[]entity.Variant}
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/variants [get]
+func (ctrl *Controller) getAllVariants(c *gin.Context) {
+	productID := c.Param("id")
+	if productID == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	puc := ctrl.useCases.Products
+	if _, err := puc.GetByID(c.Request.Context(), productID); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	uc := ctrl.useCases.Variants
+	variants, err := uc.GetAllByProductID(c.Request.Context(), productID)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	newDataResponse(c, *variants)
+}
+
+// @Summary Get product variant
+// @Security ApiKeyAuth
+// @Tags variant
+// @Description get variant of product
+// @ID variant-get
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param variantID path string true "Variant ID"
+// @Success 200 {object} entity.Variant
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/variants/{variantID} [get]
+func (ctrl *Controller) getVariantByID(c *gin.Context) {
+	productID := c.Param("id")
+	variantID := c.Param("variantID")
+	if productID == "" || variantID == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	uc := ctrl.useCases.Variants
+	v, err := uc.GetByProductID(c.Request.Context(), productID, variantID)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// @Summary Update product variant
+// @Security ApiKeyAuth
+// @Tags variant
+// @Description update SKU or attributes of variant, stock is changed by adjustments only
+// @ID variant-update
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param variantID path string true "Variant ID"
+// @Param input body entity.VariantUpdateInput true "variant data"
+// @Success 200 {object} statusResponse
+// @Failure 400,404,409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/variants/{variantID} [put]
+func (ctrl *Controller) updateVariantByID(c *gin.Context) {
+	productID := c.Param("id")
+	variantID := c.Param("variantID")
+	if productID == "" || variantID == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	var input entity.VariantUpdateInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, newJSONBindingErrorWrapper(err))
+		return
+	}
+
+	uc := ctrl.useCases.Variants
+	if _, err := uc.GetByProductID(c.Request.Context(), productID, variantID); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	if err := uc.Update(c.Request.Context(), variantID, input); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{true})
+}
+
+// @Summary Set product variant price
+// @Security ApiKeyAuth
+// @Tags variant
+// @Description add price of variant in the currency or replace it
+// @ID variant-price-set
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param variantID path string true "Variant ID"
+// @Param input body entity.Price true "price"
+// @Success 200 {object} statusResponse
+// @Failure 400,404,422 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/variants/{variantID}/prices [put]
+func (ctrl *Controller) setVariantPrice(c *gin.Context) {
+	productID := c.Param("id")
+	variantID := c.Param("variantID")
+	if productID == "" || variantID == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	var input entity.Price
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, newJSONBindingErrorWrapper(err))
+		return
+	}
+
+	uc := ctrl.useCases.Variants
+	if _, err := uc.GetByProductID(c.Request.Context(), productID, variantID); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	if err := uc.SetPrice(c.Request.Context(), variantID, input); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{true})
+}
+
+// @Summary Delete product variant
+// @Security ApiKeyAuth
+// @Tags variant
+// @Description delete variant, which was never ordered
+// @ID variant-delete
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Param variantID path string true "Variant ID"
+// @Success 200 {object} statusResponse
+// @Failure 400,404,409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /products/{id}/variants/{variantID} [delete]
+func (ctrl *Controller) deleteVariantByID(c *gin.Context) {
+	productID := c.Param("id")
+	variantID := c.Param("variantID")
+	if productID == "" || variantID == "" {
+		newErrorResponse(c, emptyParameterID)
+		return
+	}
+
+	uc := ctrl.useCases.Variants
+	if _, err := uc.GetByProductID(c.Request.Context(), productID, variantID); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	if err := uc.Remove(c.Request.Context(), variantID); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{true})
+}
@@ -0,0 +1,126 @@
+package v1_integration_test
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/linkuha/test-golang-rest-orders-api/internal/delivery/httpserver/v1"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	mockProducts "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/product/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetProductNotModified(t *testing.T) {
+	reqID := "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repos := repository.Repository{}
+
+	repoProducts := mockProducts.NewMockRepository(ctrl)
+	repoProducts.EXPECT().Get(gomock.Any(), reqID).
+		Return(&entity.Product{ID: reqID, Name: "milk", Version: 3}, nil).Times(1)
+	repoProducts.EXPECT().GetPrices(gomock.Any(), reqID).Return(&[]entity.Price{}, nil).Times(1)
+
+	repos.Products = repoProducts
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
+
+	r := gin.New()
+	r.GET("/products/:id", handler.GetProductByID)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/products/"+reqID, nil)
+	req.Header.Set("If-None-Match", `"3"`)
+
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	require.Empty(t, rec.Body.String())
+}
+
+func TestUpdateProductIfMatch(t *testing.T) {
+	reqID := "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+	input := `{"name":"milk 2.5%"}`
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		mock     func(repo *mockProducts.MockRepository)
+		wantCode int
+		wantETag string
+	}{
+		{
+			name:    "actual version",
+			ifMatch: `"3"`,
+			mock: func(repo *mockProducts.MockRepository) {
+				repo.EXPECT().Update(gomock.Any(), reqID, gomock.Any(), 3).Return(4, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantETag: `"4"`,
+		},
+		{
+			name:    "unconditional",
+			ifMatch: "",
+			mock: func(repo *mockProducts.MockRepository) {
+				repo.EXPECT().Update(gomock.Any(), reqID, gomock.Any(), 0).Return(4, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantETag: `"4"`,
+		},
+		{
+			name:    "changed by another request",
+			ifMatch: `"2"`,
+			mock: func(repo *mockProducts.MockRepository) {
+				repo.EXPECT().Update(gomock.Any(), reqID, gomock.Any(), 2).
+					Return(0, errs.NewErrorWrapper(errs.PreconditionFailed, errs.VersionChanged, "product is changed")).Times(1)
+			},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "weak tag",
+			ifMatch:  `W/"3"`,
+			mock:     func(repo *mockProducts.MockRepository) {},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "malformed header",
+			ifMatch:  "3",
+			mock:     func(repo *mockProducts.MockRepository) {},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repoProducts := mockProducts.NewMockRepository(ctrl)
+			tt.mock(repoProducts)
+
+			handler := v1.NewController(usecase.NewUseCases(repository.Repository{Products: repoProducts}, usecase.Deps{}))
+
+			r := gin.New()
+			r.PUT("/products/:id", handler.UpdateProductByID)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/products/"+reqID, bytes.NewBufferString(input))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			require.Equal(t, tt.wantETag, rec.Header().Get("ETag"))
+		})
+	}
+}
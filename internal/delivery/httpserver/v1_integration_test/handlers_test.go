@@ -0,0 +1,205 @@
+package v1_integration_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/linkuha/test-golang-rest-orders-api/config"
+	v1 "github.com/linkuha/test-golang-rest-orders-api/internal/delivery/httpserver/v1"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	mockService "github.com/linkuha/test-golang-rest-orders-api/internal/domain/service/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	mockOrders "github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/order/mocks"
+	mockUsers "github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/user/mocks"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Handlers are tested with the whole router and mocked use cases, repositories aren't involved.
+
+const (
+	handlersToken   = "token"
+	handlersUserID  = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+	handlersOrderID = "6f1e0a3c-2b7d-4c8e-9f10-3a5b7c9d1e2f"
+)
+
+func newHandlersRouter(useCases usecase.UseCases) *gin.Engine {
+	return v1.NewController(useCases).ConfigureRoutes(&config.Config{})
+}
+
+func newAuthorizedRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer "+handlersToken)
+	return req
+}
+
+func TestSignIn(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   *entity.User
+		err    error
+		status int
+		body   string
+		code   string
+	}{
+		{
+			name:   "ok",
+			user:   &entity.User{ID: handlersUserID},
+			status: http.StatusOK,
+			body:   `{"token":"` + handlersToken + `"}`,
+		},
+		{
+			name:   "wrong password",
+			err:    errs.NewErrorWrapper(errs.UserCredentials, errors.New("password is wrong"), "credentials are invalid"),
+			status: http.StatusUnauthorized,
+			code:   v1.CodeInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			users := mockUsers.NewMockUseCase(ctrl)
+			users.EXPECT().GetUserIfCredentialsValid(gomock.Any(), "alice", "secret").Return(tt.user, tt.err).Times(1)
+
+			tokens := mockService.NewMockTokenManager(ctrl)
+			if tt.user != nil {
+				tokens.EXPECT().GenerateToken(tt.user.ID).Return(handlersToken, nil).Times(1)
+			}
+
+			r := newHandlersRouter(usecase.UseCases{Users: users, Tokens: tokens})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/sign-in",
+				bytes.NewBufferString(`{"username":"alice","password":"secret"}`)))
+
+			if tt.code != "" {
+				requireProblem(t, rec, tt.status, tt.code, v1.ErrCredentialsText)
+				return
+			}
+			require.Equal(t, tt.status, rec.Code)
+			require.Equal(t, tt.body, rec.Body.String())
+		})
+	}
+}
+
+func TestGetOrderByID(t *testing.T) {
+	tests := []struct {
+		name    string
+		ownerID string
+		status  int
+		code    string
+	}{
+		{
+			name:    "own order",
+			ownerID: handlersUserID,
+			status:  http.StatusOK,
+		},
+		{
+			name:    "order of another user",
+			ownerID: "a9d1b7a0-5d0e-4d8a-9d55-8b8f1b7b0c11",
+			status:  http.StatusForbidden,
+			code:    v1.CodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokens := mockService.NewMockTokenManager(ctrl)
+			tokens.EXPECT().ParseToken(handlersToken).Return(handlersUserID, nil).Times(1)
+
+			orders := mockOrders.NewMockUseCase(ctrl)
+			orders.EXPECT().GetByID(gomock.Any(), handlersOrderID).
+				Return(&entity.Order{ID: handlersOrderID, UserID: tt.ownerID, Number: 1, Version: 2}, nil).Times(1)
+
+			r := newHandlersRouter(usecase.UseCases{Orders: orders, Tokens: tokens})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, newAuthorizedRequest(http.MethodGet, "/v1/orders/"+handlersOrderID))
+
+			if tt.code != "" {
+				requireProblem(t, rec, tt.status, tt.code, "")
+				return
+			}
+			require.Equal(t, tt.status, rec.Code)
+			require.Equal(t, `"2"`, rec.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestInvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := mockService.NewMockTokenManager(ctrl)
+	tokens.EXPECT().ParseToken(handlersToken).Return("", errors.New("token is expired")).Times(1)
+
+	// nothing is called after the failed authorization
+	r := newHandlersRouter(usecase.UseCases{Orders: mockOrders.NewMockUseCase(ctrl), Tokens: tokens})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newAuthorizedRequest(http.MethodGet, "/v1/orders/"+handlersOrderID))
+
+	requireProblem(t, rec, http.StatusUnauthorized, v1.CodeUnauthorized, v1.ErrAuthAPIText)
+	require.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+}
+
+func TestStatus(t *testing.T) {
+	const adminID = "0b3c2a4e-7f1d-4d6a-8e2b-5c9f1a7d3e60"
+
+	tests := []struct {
+		name   string
+		userID string
+		status int
+		code   string
+	}{
+		{
+			name:   "admin",
+			userID: adminID,
+			status: http.StatusOK,
+		},
+		{
+			name:   "not admin",
+			userID: handlersUserID,
+			status: http.StatusForbidden,
+			code:   v1.CodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokens := mockService.NewMockTokenManager(ctrl)
+			tokens.EXPECT().ParseToken(handlersToken).Return(tt.userID, nil).Times(1)
+
+			r := v1.NewController(usecase.UseCases{Tokens: tokens}, v1.Admins(adminID)).ConfigureRoutes(&config.Config{})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, newAuthorizedRequest(http.MethodGet, "/status"))
+
+			if tt.code != "" {
+				requireProblem(t, rec, tt.status, tt.code, "")
+				return
+			}
+			require.Equal(t, tt.status, rec.Code)
+			require.Contains(t, rec.Body.String(), `"Build":{"version":"dev"`)
+		})
+	}
+
+	t.Run("anonymous", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandlersRouter(usecase.UseCases{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+		requireProblem(t, rec, http.StatusUnauthorized, v1.CodeUnauthorized, v1.ErrAuthAPIText)
+	})
+}
@@ -0,0 +1,186 @@
+package v1_integration_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/linkuha/test-golang-rest-orders-api/internal/delivery/httpserver/v1"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	mockIdempotency "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/idempotency/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	idempotencyUserID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+	idempotencyKey    = "a9d1b7a0-5d0e-4d8a-9d55-8b8f1b7b0c11"
+)
+
+// newIdempotentRouter - handler responds with status and counts calls.
+func newIdempotentRouter(repo *mockIdempotency.MockRepository, status int, calls *int, opts ...v1.Option) *gin.Engine {
+	handler := v1.NewController(usecase.NewUseCases(repository.Repository{Idempotency: repo}, usecase.Deps{IdempotencyTTL: time.Hour}), opts...)
+
+	r := gin.New()
+	r.POST("/orders",
+		func(c *gin.Context) { c.Set("userId", idempotencyUserID) },
+		handler.Idempotency,
+		func(c *gin.Context) {
+			*calls++
+			c.JSON(status, map[string]interface{}{"id": "order-1"})
+		},
+	)
+	return r
+}
+
+func newIdempotentRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+	return req
+}
+
+func TestIdempotencyFirstRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockIdempotency.NewMockRepository(ctrl)
+	repo.EXPECT().Reserve(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, rec *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+			require.Equal(t, idempotencyUserID, rec.UserID)
+			require.Equal(t, idempotencyKey, rec.Key)
+			require.WithinDuration(t, time.Now().Add(time.Hour), rec.ExpiresAt, time.Minute)
+			return rec, true, nil
+		}).Times(1)
+	repo.EXPECT().Complete(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, rec *entity.IdempotencyRecord) error {
+			require.Equal(t, http.StatusOK, rec.StatusCode)
+			require.Equal(t, `{"id":"order-1"}`, string(rec.ResponseBody))
+			require.Equal(t, "application/json; charset=utf-8", rec.ContentType)
+			return nil
+		}).Times(1)
+
+	calls := 0
+	r := newIdempotentRouter(repo, http.StatusOK, &calls)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newIdempotentRequest(`{"number":"1"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 1, calls)
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockIdempotency.NewMockRepository(ctrl)
+	repo.EXPECT().Reserve(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, rec *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+			return &entity.IdempotencyRecord{
+				UserID:       rec.UserID,
+				Key:          rec.Key,
+				RequestHash:  rec.RequestHash,
+				StatusCode:   http.StatusOK,
+				ContentType:  "application/json; charset=utf-8",
+				ResponseBody: []byte(`{"id":"order-0"}`),
+			}, false, nil
+		}).Times(1)
+	repo.EXPECT().Complete(gomock.Any(), gomock.Any()).Times(0)
+
+	calls := 0
+	r := newIdempotentRouter(repo, http.StatusOK, &calls)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newIdempotentRequest(`{"number":"1"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"id":"order-0"}`, rec.Body.String())
+	require.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	require.Equal(t, 0, calls)
+}
+
+func TestIdempotencyKeyReuseWithAnotherBody(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockIdempotency.NewMockRepository(ctrl)
+	repo.EXPECT().Reserve(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, rec *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+			return &entity.IdempotencyRecord{
+				UserID:      rec.UserID,
+				Key:         rec.Key,
+				RequestHash: "0000000000000000000000000000000000000000000000000000000000000000",
+				StatusCode:  http.StatusOK,
+			}, false, nil
+		}).Times(1)
+
+	calls := 0
+	r := newIdempotentRouter(repo, http.StatusOK, &calls)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newIdempotentRequest(`{"number":"2"}`))
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.Equal(t, 0, calls)
+}
+
+func TestIdempotencyReleaseOnServerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockIdempotency.NewMockRepository(ctrl)
+	repo.EXPECT().Reserve(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, rec *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+			return rec, true, nil
+		}).Times(1)
+	repo.EXPECT().Complete(gomock.Any(), gomock.Any()).Times(0)
+	repo.EXPECT().Release(gomock.Any(), idempotencyUserID, idempotencyKey).Return(nil).Times(1)
+
+	calls := 0
+	r := newIdempotentRouter(repo, http.StatusServiceUnavailable, &calls)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newIdempotentRequest(`{"number":"1"}`))
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Equal(t, 1, calls)
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockIdempotency.NewMockRepository(ctrl)
+
+	calls := 0
+	r := newIdempotentRouter(repo, http.StatusOK, &calls)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{}`)))
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{}`)))
+
+	require.Equal(t, 2, calls)
+}
+
+func TestIdempotencyBodyTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockIdempotency.NewMockRepository(ctrl)
+	repo.EXPECT().Reserve(gomock.Any(), gomock.Any()).Times(0)
+
+	calls := 0
+	r := newIdempotentRouter(repo, http.StatusOK, &calls, v1.UploadMaxSize(16))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, newIdempotentRequest(`{"number":"1","comment":"too long"}`))
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Contains(t, rec.Body.String(), v1.CodeBodyTooLarge)
+	require.Zero(t, calls)
+}
@@ -0,0 +1,211 @@
+package v1_integration_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	v1 "github.com/linkuha/test-golang-rest-orders-api/internal/delivery/httpserver/v1"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	mockImages "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/image/mocks"
+	mockProducts "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/product/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/storage"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const imageProductID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+
+func newMultipartImage(t *testing.T, contentType string, data []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="file"; filename="image.png"`)
+	h.Set("Content-Type", contentType)
+	part, err := w.CreatePart(h)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return body, w.FormDataContentType()
+}
+
+func newPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func TestGetProductByIDWithImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, err := storage.NewLocal(t.TempDir(), "http://localhost:3000/media")
+	require.NoError(t, err)
+
+	repos := repository.Repository{}
+
+	repoProducts := mockProducts.NewMockRepository(ctrl)
+	repoProducts.EXPECT().Get(gomock.Any(), imageProductID).Return(&entity.Product{ID: imageProductID, Name: "milk", LeftInStock: 1}, nil).Times(1)
+	repoProducts.EXPECT().GetPrices(gomock.Any(), imageProductID).Return(&[]entity.Price{}, nil).Times(1)
+
+	repoImages := mockImages.NewMockRepository(ctrl)
+	repoImages.EXPECT().GetAllByProductID(gomock.Any(), imageProductID).Return(&[]entity.ProductImage{
+		{
+			ID:           "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1",
+			ProductID:    imageProductID,
+			Key:          "products/" + imageProductID + "/a.png",
+			ThumbnailKey: "products/" + imageProductID + "/a_thumb.jpg",
+			ContentType:  "image/png",
+			Size:         100,
+			CreatedAt:    time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}, nil).Times(1)
+
+	repos.Products = repoProducts
+	repos.Images = repoImages
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{Storage: store}), v1.Storage(store))
+
+	r := gin.New()
+	r.GET("/products/:id", handler.GetProductByID)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/products/"+imageProductID, nil)
+	r.ServeHTTP(rec, req)
+
+	expected := `{"id":"` + imageProductID + `","name":"milk","description":"","left_in_stock":1,"prices":[],` +
+		`"images":[{"id":"c401f9dc-1e68-4b44-82d9-3a93b09e3fe1","product_id":"` + imageProductID + `",` +
+		`"content_type":"image/png","size":100,` +
+		`"url":"http://localhost:3000/media/products/` + imageProductID + `/a.png",` +
+		`"thumbnail_url":"http://localhost:3000/media/products/` + imageProductID + `/a_thumb.jpg",` +
+		`"created_at":"2023-01-02T03:04:05Z"}]}`
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, expected, rec.Body.String())
+}
+
+func TestUploadProductImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir, "/media")
+	require.NoError(t, err)
+
+	repos := repository.Repository{}
+
+	repoProducts := mockProducts.NewMockRepository(ctrl)
+	repoProducts.EXPECT().Get(gomock.Any(), imageProductID).Return(&entity.Product{ID: imageProductID, Name: "milk"}, nil).Times(1)
+	repoProducts.EXPECT().GetPrices(gomock.Any(), imageProductID).Return(&[]entity.Price{}, nil).Times(1)
+
+	repoImages := mockImages.NewMockRepository(ctrl)
+	repoImages.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, img *entity.ProductImage) (string, error) {
+		img.ID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1"
+		return img.ID, nil
+	}).Times(1)
+
+	repos.Products = repoProducts
+	repos.Images = repoImages
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{Storage: store}), v1.Storage(store))
+
+	r := gin.New()
+	r.POST("/products/:id/images", handler.UploadProductImage)
+
+	body, contentType := newMultipartImage(t, "image/png", newPNG(t, 800, 400))
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/products/"+imageProductID+"/images", body)
+	req.Header.Set("Content-Type", contentType)
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"url":"/media/products/`+imageProductID+`/`)
+
+	files, err := filepath.Glob(filepath.Join(dir, "products", imageProductID, "*"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	for _, f := range files {
+		if !strings.HasSuffix(f, "_thumb.jpg") {
+			continue
+		}
+		thumb, err := os.Open(f)
+		require.NoError(t, err)
+		cfg, _, err := image.DecodeConfig(thumb)
+		thumb.Close()
+		require.NoError(t, err)
+		require.Equal(t, 320, cfg.Width)
+		require.Equal(t, 160, cfg.Height)
+	}
+}
+
+func TestUploadProductImageErrors(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		data        []byte
+		maxSize     int64
+		code        int
+	}{
+		{
+			name:        "not allowed type",
+			contentType: "application/pdf",
+			data:        []byte("%PDF-1.4"),
+			code:        http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "content mismatch",
+			contentType: "image/jpeg",
+			data:        newPNG(t, 10, 10),
+			code:        http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "too large",
+			contentType: "image/png",
+			data:        newPNG(t, 10, 10),
+			maxSize:     10,
+			code:        http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tCase := range cases {
+		ctrl := gomock.NewController(t)
+		store, err := storage.NewLocal(t.TempDir(), "/media")
+		require.NoError(t, err)
+
+		repoProducts := mockProducts.NewMockRepository(ctrl)
+		repoProducts.EXPECT().Get(gomock.Any(), imageProductID).Return(&entity.Product{ID: imageProductID}, nil).AnyTimes()
+		repoProducts.EXPECT().GetPrices(gomock.Any(), imageProductID).Return(&[]entity.Price{}, nil).AnyTimes()
+
+		repoImages := mockImages.NewMockRepository(ctrl)
+		repoImages.EXPECT().Store(gomock.Any(), gomock.Any()).Times(0)
+
+		repos := repository.Repository{Products: repoProducts, Images: repoImages}
+		handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{Storage: store}), v1.Storage(store), v1.UploadMaxSize(tCase.maxSize))
+
+		r := gin.New()
+		r.POST("/products/:id/images", handler.UploadProductImage)
+
+		body, contentType := newMultipartImage(t, tCase.contentType, tCase.data)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/products/"+imageProductID+"/images", body)
+		req.Header.Set("Content-Type", contentType)
+		r.ServeHTTP(rec, req)
+
+		require.Equal(t, tCase.code, rec.Code, tCase.name)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,39 @@
+package v1_integration_test
+
+import (
+	"encoding/json"
+	v1 "github.com/linkuha/test-golang-rest-orders-api/internal/delivery/httpserver/v1"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+)
+
+type problem struct {
+	Type   string `json:"type"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	Code   string `json:"code"`
+	Errors []struct {
+		Field   string `json:"field"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// requireProblem checks the problem response and returns it for additional checks.
+func requireProblem(t *testing.T, rec *httptest.ResponseRecorder, status int, code, detail string) problem {
+	t.Helper()
+
+	require.Equal(t, status, rec.Code)
+	require.Equal(t, v1.ProblemContentType, rec.Header().Get("Content-Type"))
+
+	var p problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	require.Equal(t, status, p.Status)
+	require.Equal(t, code, p.Code)
+	require.Equal(t, v1.ProblemTypeBaseURI+code, p.Type)
+	if detail != "" {
+		require.Equal(t, detail, p.Detail)
+	}
+	return p
+}
@@ -2,16 +2,18 @@ package v1_integration_test
 
 import (
 	"bytes"
-	"context"
 	"database/sql"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	v1 "github.com/linkuha/test-golang-rest-orders-api/internal/delivery/httpserver/v1"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	mockImages "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/image/mocks"
 	mockProducts "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/product/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
@@ -36,17 +38,19 @@ func TestGetProductByID(t *testing.T) {
 		},
 	}
 
-	ctx := context.Background()
-
 	// Create dummy repos, for don't use other
 	repos := repository.Repository{}
 
 	repoProducts := mockProducts.NewMockRepository(ctrl)
-	repoProducts.EXPECT().Get(ctx, reqID).Return(exp, nil).Times(1)
-	repoProducts.EXPECT().GetPrices(ctx, reqID).Return(expPrices, nil).Times(1)
+	repoProducts.EXPECT().Get(gomock.Any(), reqID).Return(exp, nil).Times(1)
+	repoProducts.EXPECT().GetPrices(gomock.Any(), reqID).Return(expPrices, nil).Times(1)
+
+	repoImages := mockImages.NewMockRepository(ctrl)
+	repoImages.EXPECT().GetAllByProductID(gomock.Any(), reqID).Return(&[]entity.ProductImage{}, nil).Times(1)
 
 	repos.Products = repoProducts
-	handler := v1.NewController(ctx, repos)
+	repos.Images = repoImages
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
 
 	// Init endpoint
 	r := gin.New()
@@ -78,16 +82,14 @@ func TestGetProductServiceError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := context.Background()
-
 	// Create dummy repos, for don't use other
 	repos := repository.Repository{}
 
 	repoProducts := mockProducts.NewMockRepository(ctrl)
-	repoProducts.EXPECT().Get(ctx, reqID).Return(nil, errs.HandleErrorDB(sql.ErrConnDone)).Times(1)
+	repoProducts.EXPECT().Get(gomock.Any(), reqID).Return(nil, errs.HandleErrorDB(sql.ErrConnDone)).Times(1)
 
 	repos.Products = repoProducts
-	handler := v1.NewController(ctx, repos)
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
 
 	// Init endpoint
 	r := gin.New()
@@ -104,13 +106,7 @@ func TestGetProductServiceError(t *testing.T) {
 	// Make request
 	r.ServeHTTP(rec, req)
 
-	data := rec.Body.String()
-
-	expected :=
-		"{\"ok\":false,\"message\":\"" + v1.ErrServiceUnavailableText + "\"}"
-
-	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
-	require.Equal(t, expected, data)
+	requireProblem(t, rec, http.StatusServiceUnavailable, v1.CodeUnavailable, v1.ErrServiceUnavailableText)
 }
 
 func TestCreateProduct(t *testing.T) {
@@ -133,16 +129,14 @@ func TestCreateProduct(t *testing.T) {
 		t.Errorf("Can't encode json request: %s", err.Error())
 	}
 
-	ctx := context.Background()
-
 	// Create dummy repos, for don't use other
 	repos := repository.Repository{}
 
 	repoProducts := mockProducts.NewMockRepository(ctrl)
-	repoProducts.EXPECT().StoreWithPrices(ctx, product).Return(exp, nil).Times(1)
+	repoProducts.EXPECT().StoreWithPrices(gomock.Any(), product).Return(exp, nil).Times(1)
 
 	repos.Products = repoProducts
-	handler := v1.NewController(ctx, repos)
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
 
 	// Init endpoint
 	r := gin.New()
@@ -186,16 +180,14 @@ func TestCreateProductServiceError(t *testing.T) {
 		t.Errorf("Can't encode json request: %s", err.Error())
 	}
 
-	ctx := context.Background()
-
 	// Create dummy repos, for don't use other
 	repos := repository.Repository{}
 
 	repoProducts := mockProducts.NewMockRepository(ctrl)
-	repoProducts.EXPECT().StoreWithPrices(ctx, product).Return("", errs.HandleErrorDB(sql.ErrConnDone)).Times(1)
+	repoProducts.EXPECT().StoreWithPrices(gomock.Any(), product).Return("", errs.HandleErrorDB(sql.ErrConnDone)).Times(1)
 
 	repos.Products = repoProducts
-	handler := v1.NewController(ctx, repos)
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
 
 	// Init endpoint
 	r := gin.New()
@@ -212,13 +204,7 @@ func TestCreateProductServiceError(t *testing.T) {
 	// Make request
 	r.ServeHTTP(rec, req)
 
-	data := rec.Body.String()
-
-	expected :=
-		"{\"ok\":false,\"message\":\"" + v1.ErrServiceUnavailableText + "\"}"
-
-	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
-	require.Equal(t, expected, data)
+	requireProblem(t, rec, http.StatusServiceUnavailable, v1.CodeUnavailable, v1.ErrServiceUnavailableText)
 }
 
 func TestCreateProductBadRequest(t *testing.T) {
@@ -229,7 +215,7 @@ func TestCreateProductBadRequest(t *testing.T) {
 
 	// Create dummy repos, for don't use other
 	repos := repository.Repository{}
-	handler := v1.NewController(context.Background(), repos)
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
 
 	// Init endpoint
 	r := gin.New()
@@ -246,13 +232,7 @@ func TestCreateProductBadRequest(t *testing.T) {
 	// Make request
 	r.ServeHTTP(rec, req)
 
-	data := rec.Body.String()
-
-	expected :=
-		"{\"ok\":false,\"message\":\"" + v1.ErrInputJSONText + "\"}"
-
-	require.Equal(t, http.StatusBadRequest, rec.Code)
-	require.Equal(t, expected, data)
+	requireProblem(t, rec, http.StatusBadRequest, v1.CodeMalformedRequest, v1.ErrInputJSONText)
 }
 
 func TestCreateProductValidationError(t *testing.T) {
@@ -263,7 +243,7 @@ func TestCreateProductValidationError(t *testing.T) {
 
 	// Create dummy repos, for don't use other
 	repos := repository.Repository{}
-	handler := v1.NewController(context.Background(), repos)
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
 
 	// Init endpoint
 	r := gin.New()
@@ -280,10 +260,11 @@ func TestCreateProductValidationError(t *testing.T) {
 	// Make request
 	r.ServeHTTP(rec, req)
 
-	data := rec.Body.String()
-
-	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
-	require.Contains(t, data, v1.ErrValidationText)
+	p := requireProblem(t, rec, http.StatusUnprocessableEntity, v1.CodeValidation, "")
+	require.Contains(t, p.Detail, v1.ErrValidationText)
+	require.Len(t, p.Errors, 1)
+	require.Equal(t, "left_in_stock", p.Errors[0].Field)
+	require.Equal(t, "validation_min_greater_equal_than_required", p.Errors[0].Code)
 }
 
 func TestCreateProductInnerValidationError(t *testing.T) {
@@ -294,7 +275,7 @@ func TestCreateProductInnerValidationError(t *testing.T) {
 
 	// Create dummy repos, for don't use other
 	repos := repository.Repository{}
-	handler := v1.NewController(context.Background(), repos)
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
 
 	// Init endpoint
 	r := gin.New()
@@ -311,8 +292,156 @@ func TestCreateProductInnerValidationError(t *testing.T) {
 	// Make request
 	r.ServeHTTP(rec, req)
 
-	data := rec.Body.String()
+	p := requireProblem(t, rec, http.StatusUnprocessableEntity, v1.CodeValidation, "")
+	require.Contains(t, p.Detail, v1.ErrValidationText)
+	require.Len(t, p.Errors, 1)
+	require.Equal(t, "currency", p.Errors[0].Field)
+}
+
+func TestDeleteProductInUse(t *testing.T) {
+	reqID := "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Create dummy repos, for don't use other
+	repos := repository.Repository{}
+
+	repoProducts := mockProducts.NewMockRepository(ctrl)
+	repoProducts.EXPECT().Remove(gomock.Any(), reqID, 0).
+		Return(errs.NewErrorWrapper(errs.Logic, errs.RecordInUse, "product is used in orders")).Times(1)
+
+	repos.Products = repoProducts
+	handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
+
+	// Init endpoint
+	r := gin.New()
+	r.DELETE("/products/:id", handler.DeleteProductByID)
+
+	// Create request
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(
+		http.MethodDelete,
+		"/products/"+reqID,
+		nil,
+	)
+
+	// Make request
+	r.ServeHTTP(rec, req)
+
+	requireProblem(t, rec, http.StatusConflict, v1.CodeConflict, "product is used in orders, archive it instead")
+}
+
+func TestCreateProductConstraintErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+		field  string
+	}{
+		{
+			name: "duplicate",
+			err: errs.HandleErrorDB(&pq.Error{Code: "23505", Constraint: "products_name_key",
+				Detail: "Key (name)=(milk) already exists."}),
+			status: http.StatusConflict,
+			code:   v1.CodeAlreadyExists,
+			detail: "name is already taken",
+			field:  "name",
+		},
+		{
+			name: "missing category",
+			err: errs.HandleErrorDB(&pq.Error{Code: "23503", Constraint: "products_category_id_fkey",
+				Detail: "Key (category_id)=(c401f9dc-1e68-4b44-82d9-3a93b09e3fe7) is not present in table \"product_categories\"."}),
+			status: http.StatusBadRequest,
+			code:   v1.CodeBrokenReference,
+			detail: "category_id is not found",
+			field:  "category_id",
+		},
+		{
+			name:   "check",
+			err:    errs.HandleErrorDB(&pq.Error{Code: "23514", Constraint: "products_left_in_stock_check"}),
+			status: http.StatusUnprocessableEntity,
+			code:   v1.CodeValidation,
+			detail: v1.ErrValidationText + ": invalid value (products_left_in_stock_check)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repos := repository.Repository{}
+
+			repoProducts := mockProducts.NewMockRepository(ctrl)
+			repoProducts.EXPECT().StoreWithPrices(gomock.Any(), gomock.Any()).Return("", tt.err).Times(1)
+
+			repos.Products = repoProducts
+			handler := v1.NewController(usecase.NewUseCases(repos, usecase.Deps{}))
+
+			r := gin.New()
+			r.POST("/products", handler.CreateProduct)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/products",
+				bytes.NewBufferString(`{"name":"milk","left_in_stock":1,"prices":[{"price":1.05,"currency":"USD"}]}`),
+			)
+
+			r.ServeHTTP(rec, req)
+
+			p := requireProblem(t, rec, tt.status, tt.code, tt.detail)
+			if tt.field != "" {
+				require.Len(t, p.Errors, 1)
+				require.Equal(t, tt.field, p.Errors[0].Field)
+			}
+		})
+	}
+}
+
+func TestImportProductsInvalidRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoProducts := mockProducts.NewMockRepository(ctrl)
+	repoProducts.EXPECT().StoreWithPrices(gomock.Any(), gomock.Any()).Times(0)
+
+	handler := v1.NewController(usecase.NewUseCases(repository.Repository{Products: repoProducts}, usecase.Deps{}))
+
+	r := gin.New()
+	r.POST("/products/import", handler.ImportProducts)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/products/import?format=csv",
+		bytes.NewBufferString("name,left_in_stock,price_USD\nmilk,1,1.05\n,2,1\nbread,3,-1\n"))
+	r.ServeHTTP(rec, req)
+
+	p := requireProblem(t, rec, http.StatusUnprocessableEntity, v1.CodeValidation, "")
+	require.Contains(t, p.Detail, "2 of 3 rows are invalid")
+	require.Len(t, p.Errors, 2)
+	require.Equal(t, "rows.3", p.Errors[0].Field)
+	require.Equal(t, "rows.4", p.Errors[1].Field)
+}
+
+func TestImportProductsTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoProducts := mockProducts.NewMockRepository(ctrl)
+	repoProducts.EXPECT().StoreWithPrices(gomock.Any(), gomock.Any()).Times(0)
+
+	handler := v1.NewController(usecase.NewUseCases(repository.Repository{Products: repoProducts}, usecase.Deps{}), v1.ImportMaxSize(16))
+
+	r := gin.New()
+	r.POST("/products/import", handler.ImportProducts)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/products/import?format=csv",
+		bytes.NewBufferString("name,left_in_stock,price_USD\nmilk,1,1.05\n"))
+	r.ServeHTTP(rec, req)
 
-	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
-	require.Contains(t, data, v1.ErrValidationText)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
 }
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"regexp"
+)
+
+var slugRegexp = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+
+// Category is a node of the categories tree, root categories have no parent.
+type Category struct {
+	ID       string  `json:"id"`
+	ParentID *string `json:"parent_id"`
+	Name     string  `json:"name" binding:"required"`
+	Slug     string  `json:"slug" binding:"required"`
+}
+
+// Validate ...
+func (m *Category) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.ID, is.UUIDv4),
+		validation.Field(&m.ParentID, is.UUIDv4, validation.When(m.ID != "", validation.NotIn(m.ID))),
+		validation.Field(&m.Name, validation.Required),
+		validation.Field(&m.Slug, validation.Required, validation.Length(1, 255), validation.Match(slugRegexp)),
+	)
+}
@@ -0,0 +1,69 @@
+package entity_test
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCategoryValidateOK(t *testing.T) {
+	parentID := "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1"
+	cases := []struct {
+		name string
+		in   *entity.Category
+	}{
+		{
+			name: "root",
+			in:   &entity.Category{Name: "Clothes", Slug: "clothes"},
+		},
+		{
+			name: "child",
+			in: &entity.Category{
+				ID:       "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				ParentID: &parentID,
+				Name:     "T-shirts",
+				Slug:     "t-shirts-2023",
+			},
+		},
+	}
+
+	for _, tCase := range cases {
+		err := tCase.in.Validate()
+		require.NoError(t, err, tCase.name)
+	}
+}
+
+func TestCategoryValidateError(t *testing.T) {
+	selfID := "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+	cases := []struct {
+		name string
+		in   *entity.Category
+	}{
+		{
+			name: "empty_name",
+			in:   &entity.Category{Slug: "clothes"},
+		},
+		{
+			name: "bad_slug",
+			in:   &entity.Category{Name: "Clothes", Slug: "Clothes & shoes"},
+		},
+		{
+			name: "slug_with_trailing_dash",
+			in:   &entity.Category{Name: "Clothes", Slug: "clothes-"},
+		},
+		{
+			name: "own_parent",
+			in: &entity.Category{
+				ID:       selfID,
+				ParentID: &selfID,
+				Name:     "Clothes",
+				Slug:     "clothes",
+			},
+		},
+	}
+
+	for _, tCase := range cases {
+		err := tCase.in.Validate()
+		require.Error(t, err, tCase.name)
+	}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"time"
+)
+
+// IdempotencyRecord - the first response to the request with Idempotency-Key of the user.
+// RequestHash identifies method, path and body of the request. StatusCode is 0 until the response is stored.
+type IdempotencyRecord struct {
+	UserID       string
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// Validate ...
+func (m *IdempotencyRecord) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.UserID, validation.Required, is.UUIDv4),
+		validation.Field(&m.Key, validation.Required, validation.Length(1, 255), is.PrintableASCII),
+		validation.Field(&m.RequestHash, validation.Required, validation.Length(64, 64)),
+	)
+}
+
+// IsCompleted - response of the first request is stored.
+func (m *IdempotencyRecord) IsCompleted() bool {
+	return m.StatusCode != 0
+}
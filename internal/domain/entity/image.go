@@ -0,0 +1,45 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"time"
+)
+
+// ImageContentTypes - allowed types of uploaded images, with file extension for storage keys.
+var ImageContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// ProductImage - uploaded image of product, the files are kept in the storage by keys,
+// URLs are resolved by the storage on reading.
+type ProductImage struct {
+	ID           string    `json:"id"`
+	ProductID    string    `json:"product_id"`
+	Key          string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Validate ...
+func (m *ProductImage) Validate() error {
+	contentTypes := make([]interface{}, 0, len(ImageContentTypes))
+	for ct := range ImageContentTypes {
+		contentTypes = append(contentTypes, ct)
+	}
+
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.ProductID, validation.Required, is.UUIDv4),
+		validation.Field(&m.Key, validation.Required, validation.Length(1, 255)),
+		validation.Field(&m.ThumbnailKey, validation.Required, validation.Length(1, 255)),
+		validation.Field(&m.ContentType, validation.Required, validation.In(contentTypes...)),
+		validation.Field(&m.Size, validation.Required, validation.Min(int64(1))),
+	)
+}
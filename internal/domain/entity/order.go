@@ -6,21 +6,27 @@ import (
 )
 
 type Order struct {
-	ID     string `json:"id"`
-	UserID string `json:"user_id" binding:"required"`
-	Number int    `json:"number" binding:"required"`
+	ID      string `json:"id"`
+	UserID  string `json:"user_id" binding:"required"`
+	Number  int    `json:"number" binding:"required"`
+	Version int    `json:"-"`
+	// Products - lines of the new order, it's created with them and with reserved stock at once.
+	Products []OrderProductView `json:"products,omitempty"`
 }
 
 type OrderProduct struct {
-	ID        int    `json:"id"`
-	OrderID   string `json:"order_id"`
-	ProductID string `json:"product_id"`
-	Amount    int    `json:"amount"`
+	ID        int     `json:"id"`
+	OrderID   string  `json:"order_id"`
+	ProductID string  `json:"product_id"`
+	VariantID *string `json:"variant_id"`
+	Amount    int     `json:"amount"`
 }
 
+// OrderProductView - order line, VariantID is empty for simple products.
 type OrderProductView struct {
-	ID     string `json:"id"`
-	Amount int    `json:"amount"`
+	ID        string  `json:"id"`
+	VariantID *string `json:"variant_id,omitempty"`
+	Amount    int     `json:"amount"`
 }
 
 // Validate ...
@@ -30,6 +36,16 @@ func (m *Order) Validate() error {
 		validation.Field(&m.ID, is.UUIDv4),
 		validation.Field(&m.UserID, validation.Required, is.UUIDv4),
 		validation.Field(&m.Number, validation.Required),
+		validation.Field(&m.Products),
+	)
+}
+
+func (m OrderProductView) Validate() error {
+	return validation.ValidateStruct(
+		&m,
+		validation.Field(&m.ID, validation.Required, is.UUIDv4),
+		validation.Field(&m.VariantID, is.UUIDv4),
+		validation.Field(&m.Amount, validation.Required, validation.Min(1)),
 	)
 }
 
@@ -38,6 +54,7 @@ func (m *OrderProduct) Validate() error {
 		m,
 		validation.Field(&m.OrderID, validation.Required, is.UUIDv4),
 		validation.Field(&m.ProductID, validation.Required, is.UUIDv4),
+		validation.Field(&m.VariantID, is.UUIDv4),
 		validation.Field(&m.Amount, validation.Min(1)),
 	)
 }
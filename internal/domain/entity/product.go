@@ -3,14 +3,19 @@ package entity
 import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"time"
 )
 
 type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	LeftInStock int     `json:"left_in_stock" binding:"required"`
-	Prices      []Price `json:"prices"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name" binding:"required"`
+	Description string         `json:"description"`
+	LeftInStock int            `json:"left_in_stock" binding:"required"`
+	CategoryID  *string        `json:"category_id,omitempty"`
+	Prices      []Price        `json:"prices"`
+	Images      []ProductImage `json:"images,omitempty"`
+	ArchivedAt  *time.Time     `json:"archived_at,omitempty"`
+	Version     int            `json:"-"`
 }
 
 type Price struct {
@@ -33,19 +38,42 @@ func (m *Product) Validate() error {
 		validation.Field(&m.ID, is.UUIDv4),
 		validation.Field(&m.Name, validation.Required),
 		validation.Field(&m.LeftInStock, validation.Min(0)),
+		validation.Field(&m.CategoryID, is.UUIDv4),
 	)
 }
 
+// IsArchived - archived products are hidden from listings and not available for ordering.
+func (m *Product) IsArchived() bool {
+	return m.ArchivedAt != nil
+}
+
 func (m *Price) Validate() error {
 	return validation.ValidateStruct(
 		m,
 		validation.Field(&m.Currency, validation.Required, validation.Length(3, 3)),
-		validation.Field(&m.Price, validation.Required),
+		validation.Field(&m.Price, validation.Required, validation.Min(0.0)),
 	)
 }
 
+// ProductUpdateInput doesn't touch the stock, it changes only through the inventory ledger.
+// Empty CategoryID removes product from the category.
 type ProductUpdateInput struct {
 	Name        *string `json:"name"`
 	Description *string `json:"description"`
-	LeftInStock *bool   `json:"left_in_stock"`
+	CategoryID  *string `json:"category_id"`
+}
+
+func (m *ProductUpdateInput) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.CategoryID, is.UUIDv4),
+	)
+}
+
+// ProductFilter - query params of products listing. Category is ID or slug,
+// products of its subcategories are included (CategoryIDs is resolved subtree).
+type ProductFilter struct {
+	Category    string   `form:"category"`
+	Tag         string   `form:"tag"`
+	CategoryIDs []string `form:"-" swaggerignore:"true"`
 }
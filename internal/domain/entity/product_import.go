@@ -0,0 +1,18 @@
+package entity
+
+// ProductImportRowError - Row is the line number in the import file.
+type ProductImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ProductImportResult - rows are stored only when all of them are valid and it's not a dry run.
+type ProductImportResult struct {
+	DryRun    bool                    `json:"dry_run"`
+	Committed bool                    `json:"committed"`
+	Total     int                     `json:"total"`
+	Valid     int                     `json:"valid"`
+	Imported  int                     `json:"imported"`
+	IDs       []string                `json:"ids"`
+	Errors    []ProductImportRowError `json:"errors"`
+}
@@ -29,10 +29,19 @@ func TestProductValidateError(t *testing.T) {
 		name   string
 		in     *entity.Product
 		expErr error
-	}{}
+	}{
+		{
+			name: "negative_price",
+			in:   &entity.Product{Name: "qwerty", Prices: []entity.Price{{Currency: "USD", Price: -1}}},
+		},
+		{
+			name: "negative_stock",
+			in:   &entity.Product{Name: "qwerty", LeftInStock: -1},
+		},
+	}
 
 	for _, tCase := range cases {
 		err := tCase.in.Validate()
-		require.Error(t, err)
+		require.Error(t, err, tCase.name)
 	}
 }
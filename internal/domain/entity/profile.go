@@ -13,6 +13,7 @@ type Profile struct {
 	FullName   string `json:"full_name"`
 	Sex        string `json:"sex" binding:"required"`
 	Age        int    `json:"age" binding:"required"`
+	Version    int    `json:"-"`
 }
 
 // Validate ...
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"time"
+)
+
+// Kinds of stock movements. Reservations and releases are made by orders,
+// the others can be registered manually as adjustments.
+const (
+	StockReceipt     = "receipt"
+	StockReservation = "reservation"
+	StockRelease     = "release"
+	StockAdjustment  = "adjustment"
+	StockReturn      = "return"
+)
+
+// StockMovement is a row of the inventory ledger. Quantity is a signed delta,
+// Balance is the product stock after the movement was applied.
+type StockMovement struct {
+	ID          int64     `json:"id"`
+	ProductID   string    `json:"product_id"`
+	VariantID   *string   `json:"variant_id,omitempty"`
+	Kind        string    `json:"kind"`
+	Quantity    int       `json:"quantity"`
+	Balance     int       `json:"balance"`
+	Reason      string    `json:"reason"`
+	ActorUserID *string   `json:"actor_user_id"`
+	OrderID     *string   `json:"order_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StockAdjustmentInput - VariantID is set for the stock of product variant.
+type StockAdjustmentInput struct {
+	VariantID *string `json:"variant_id"`
+	Kind      string  `json:"kind" binding:"required"`
+	Quantity  int     `json:"quantity" binding:"required"`
+	Reason    string  `json:"reason"`
+}
+
+// Validate ...
+func (m *StockMovement) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.ProductID, validation.Required, is.UUIDv4),
+		validation.Field(&m.VariantID, is.UUIDv4),
+		validation.Field(&m.Kind, validation.Required,
+			validation.In(StockReceipt, StockReservation, StockRelease, StockAdjustment, StockReturn)),
+		validation.Field(&m.Quantity, validation.Required,
+			validation.When(m.Kind == StockReservation, validation.Max(-1)),
+			validation.When(m.Kind == StockReceipt || m.Kind == StockRelease || m.Kind == StockReturn, validation.Min(1)),
+		),
+		validation.Field(&m.Reason, validation.Required.When(m.Kind == StockAdjustment)),
+		validation.Field(&m.ActorUserID, is.UUIDv4),
+		validation.Field(&m.OrderID, is.UUIDv4),
+	)
+}
+
+func (m *StockAdjustmentInput) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.VariantID, is.UUIDv4),
+		validation.Field(&m.Kind, validation.Required, validation.In(StockReceipt, StockAdjustment, StockReturn)),
+		validation.Field(&m.Quantity, validation.Required),
+	)
+}
@@ -0,0 +1,109 @@
+package entity_test
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStockMovementValidateOK(t *testing.T) {
+	actorID := "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1"
+	cases := []struct {
+		name string
+		in   *entity.StockMovement
+	}{
+		{
+			name: "receipt_without_actor",
+			in: &entity.StockMovement{
+				ProductID: "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:      entity.StockReceipt,
+				Quantity:  10,
+			},
+		},
+		{
+			name: "reservation",
+			in: &entity.StockMovement{
+				ProductID: "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:      entity.StockReservation,
+				Quantity:  -2,
+			},
+		},
+		{
+			name: "negative_adjustment",
+			in: &entity.StockMovement{
+				ProductID:   "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:        entity.StockAdjustment,
+				Quantity:    -3,
+				Reason:      "broken",
+				ActorUserID: &actorID,
+			},
+		},
+	}
+
+	for _, tCase := range cases {
+		err := tCase.in.Validate()
+		require.NoError(t, err, tCase.name)
+	}
+}
+
+func TestStockMovementValidateError(t *testing.T) {
+	badActorID := "c401f9dc-1e68"
+	cases := []struct {
+		name string
+		in   *entity.StockMovement
+	}{
+		{
+			name: "zero_quantity",
+			in: &entity.StockMovement{
+				ProductID: "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:      entity.StockReceipt,
+			},
+		},
+		{
+			name: "negative_receipt",
+			in: &entity.StockMovement{
+				ProductID: "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:      entity.StockReceipt,
+				Quantity:  -1,
+			},
+		},
+		{
+			name: "positive_reservation",
+			in: &entity.StockMovement{
+				ProductID: "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:      entity.StockReservation,
+				Quantity:  1,
+			},
+		},
+		{
+			name: "adjustment_without_reason",
+			in: &entity.StockMovement{
+				ProductID: "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:      entity.StockAdjustment,
+				Quantity:  1,
+			},
+		},
+		{
+			name: "unknown_kind",
+			in: &entity.StockMovement{
+				ProductID: "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:      "theft",
+				Quantity:  -1,
+			},
+		},
+		{
+			name: "bad_actor_id",
+			in: &entity.StockMovement{
+				ProductID:   "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7",
+				Kind:        entity.StockReturn,
+				Quantity:    1,
+				ActorUserID: &badActorID,
+			},
+		},
+	}
+
+	for _, tCase := range cases {
+		err := tCase.in.Validate()
+		require.Error(t, err, tCase.name)
+	}
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"regexp"
+	"strings"
+)
+
+var tagRegexp = regexp.MustCompile(`^[\p{L}\p{N}_ -]+$`)
+
+type ProductTagsInput struct {
+	Tags []string `json:"tags"`
+}
+
+// NormalizeTag - tags are stored trimmed and lowercased, the filter by tag is normalized the same way.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// Normalize trims and lowercases tags and removes duplicates.
+func (m *ProductTagsInput) Normalize() {
+	seen := make(map[string]bool, len(m.Tags))
+	tags := make([]string, 0, len(m.Tags))
+	for _, tag := range m.Tags {
+		tag = NormalizeTag(tag)
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	m.Tags = tags
+}
+
+// Validate ...
+func (m *ProductTagsInput) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.Tags, validation.Each(validation.Required, validation.Length(1, 64), validation.Match(tagRegexp))),
+	)
+}
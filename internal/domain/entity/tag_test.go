@@ -0,0 +1,36 @@
+package entity_test
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestProductTagsNormalize(t *testing.T) {
+	in := entity.ProductTagsInput{Tags: []string{" Summer", "summer", "New arrivals ", "хит"}}
+	in.Normalize()
+
+	require.Equal(t, []string{"summer", "new arrivals", "хит"}, in.Tags)
+	require.NoError(t, in.Validate())
+}
+
+func TestProductTagsValidateError(t *testing.T) {
+	cases := []struct {
+		name string
+		in   *entity.ProductTagsInput
+	}{
+		{
+			name: "empty_tag",
+			in:   &entity.ProductTagsInput{Tags: []string{"summer", ""}},
+		},
+		{
+			name: "bad_symbols",
+			in:   &entity.ProductTagsInput{Tags: []string{"<b>sale</b>"}},
+		},
+	}
+
+	for _, tCase := range cases {
+		err := tCase.in.Validate()
+		require.Error(t, err, tCase.name)
+	}
+}
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"regexp"
+)
+
+var skuRegexp = regexp.MustCompile("^[A-Za-z0-9._-]{1,64}$")
+
+// Variant of product (e.g. size and colour) with own SKU, stock and prices.
+type Variant struct {
+	ID          string            `json:"id"`
+	ProductID   string            `json:"product_id"`
+	SKU         string            `json:"sku" binding:"required"`
+	Attributes  map[string]string `json:"attributes"`
+	LeftInStock int               `json:"left_in_stock"`
+	Prices      []Price           `json:"prices"`
+}
+
+// VariantUpdateInput doesn't touch the stock, it changes only through the inventory ledger.
+type VariantUpdateInput struct {
+	SKU        *string            `json:"sku"`
+	Attributes *map[string]string `json:"attributes"`
+}
+
+// Validate ...
+func (m *Variant) Validate() error {
+	for _, price := range m.Prices {
+		if err := price.Validate(); err != nil {
+			return err
+		}
+	}
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.ID, is.UUIDv4),
+		validation.Field(&m.ProductID, validation.Required, is.UUIDv4),
+		validation.Field(&m.SKU, validation.Required, validation.Match(skuRegexp)),
+		validation.Field(&m.Attributes, validation.Each(validation.Required, validation.Length(1, 255))),
+		validation.Field(&m.LeftInStock, validation.Min(0)),
+	)
+}
+
+func (m *VariantUpdateInput) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.SKU, validation.NilOrNotEmpty, validation.Match(skuRegexp)),
+	)
+}
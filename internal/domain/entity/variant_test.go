@@ -0,0 +1,69 @@
+package entity_test
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const variantProductID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1"
+
+func TestVariantValidateOK(t *testing.T) {
+	cases := []struct {
+		name string
+		in   *entity.Variant
+	}{
+		{
+			name: "minimal",
+			in:   &entity.Variant{ProductID: variantProductID, SKU: "TSHIRT-RED-M"},
+		},
+		{
+			name: "full",
+			in: &entity.Variant{
+				ProductID:   variantProductID,
+				SKU:         "tshirt.blue_xl",
+				Attributes:  map[string]string{"color": "blue", "size": "XL"},
+				LeftInStock: 10,
+				Prices:      []entity.Price{{Currency: "USD", Price: 9.99}},
+			},
+		},
+	}
+
+	for _, tCase := range cases {
+		err := tCase.in.Validate()
+		require.NoError(t, err, tCase.name)
+	}
+}
+
+func TestVariantValidateError(t *testing.T) {
+	cases := []struct {
+		name string
+		in   *entity.Variant
+	}{
+		{
+			name: "without product",
+			in:   &entity.Variant{SKU: "TSHIRT-RED-M"},
+		},
+		{
+			name: "empty sku",
+			in:   &entity.Variant{ProductID: variantProductID},
+		},
+		{
+			name: "sku with spaces",
+			in:   &entity.Variant{ProductID: variantProductID, SKU: "TSHIRT RED"},
+		},
+		{
+			name: "negative stock",
+			in:   &entity.Variant{ProductID: variantProductID, SKU: "TSHIRT-RED-M", LeftInStock: -1},
+		},
+		{
+			name: "empty attribute",
+			in:   &entity.Variant{ProductID: variantProductID, SKU: "TSHIRT-RED-M", Attributes: map[string]string{"color": ""}},
+		},
+	}
+
+	for _, tCase := range cases {
+		err := tCase.in.Validate()
+		require.Error(t, err, tCase.name)
+	}
+}
@@ -1,15 +1,47 @@
 package errs
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
+	"github.com/lib/pq"
+	"regexp"
+	"strings"
 )
 
 var (
 	RecordNotFound = errors.New("record is not found")
+	RecordInUse    = errors.New("record is referenced by other records")
+	VersionChanged = errors.New("record version is changed")
+	RecordExists   = errors.New("record with the same unique value already exists")
+	BrokenRef      = errors.New("referenced record is not found")
+	InvalidValue   = errors.New("invalid value")
 )
 
+// ConstraintError - the statement violates the constraint of the database. Err is RecordExists, BrokenRef,
+// RecordInUse or InvalidValue. Field is the column of the constraint if it's known.
+type ConstraintError struct {
+	Constraint string
+	Field      string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string {
+	res := e.Err.Error()
+	if e.Field != "" {
+		res = e.Field + ": " + res
+	}
+	if e.Constraint != "" {
+		res += " (" + e.Constraint + ")"
+	}
+	return res
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
 func HandleErrorDB(e error) error {
 	if e == nil {
 		return nil
@@ -17,8 +49,87 @@ func HandleErrorDB(e error) error {
 	if errors.Is(e, sql.ErrNoRows) {
 		return NewErrorWrapper(NotExist, RecordNotFound, "not found")
 	}
+	if errors.Is(e, context.DeadlineExceeded) {
+		return NewErrorWrapper(Timeout, e, "query is not completed in time")
+	}
+	if errors.Is(e, context.Canceled) {
+		return NewErrorWrapper(Canceled, e, "query is canceled")
+	}
 	if errors.Is(e, sql.ErrConnDone) || errors.Is(e, driver.ErrBadConn) {
 		return NewErrorWrapper(DatabaseConnection, e, "connection problem")
 	}
+	var pqErr *pq.Error
+	if errors.As(e, &pqErr) {
+		if err := handleErrorPostgres(pqErr); err != nil {
+			return err
+		}
+	}
 	return NewErrorWrapper(Database, e, "db another error")
 }
+
+// keyDetailRegexp - the detail of unique and foreign key violations: Key (user_id, number)=(...) already exists.
+var keyDetailRegexp = regexp.MustCompile(`^Key \((.+?)\)=\(`)
+
+// handleErrorPostgres translates violations of constraints and cancelled queries, other errors are left for the caller.
+func handleErrorPostgres(e *pq.Error) error {
+	field := e.Column
+	if field == "" {
+		if m := keyDetailRegexp.FindStringSubmatch(e.Detail); m != nil {
+			// the leading columns of the composite key are the owner of the row (user_id, product_id)
+			columns := strings.Split(m[1], ", ")
+			field = columns[len(columns)-1]
+		}
+	}
+
+	switch e.Code.Name() {
+	case "query_canceled":
+		// statement_timeout or cancellation of the context
+		return NewErrorWrapper(Timeout, e, "query is not completed in time")
+	case "unique_violation":
+		return UniqueViolation(e.Constraint, field)
+	case "foreign_key_violation":
+		if strings.Contains(e.Detail, "is still referenced") {
+			return InUse(e.Constraint, "record is used by other records")
+		}
+		return ForeignKeyViolation(e.Constraint, field)
+	case "check_violation", "not_null_violation", "string_data_right_truncation", "numeric_value_out_of_range",
+		"invalid_text_representation":
+		return NewErrorWrapper(Validation, &ConstraintError{Constraint: e.Constraint, Field: field, Err: InvalidValue},
+			"invalid value: "+e.Message)
+	}
+	return nil
+}
+
+// HandleRemoveErrorDB - HandleErrorDB for deletes, the record referenced by others is reported with inUseMessage,
+// e.g. by what it's used.
+func HandleRemoveErrorDB(e error, inUseMessage string) error {
+	err := HandleErrorDB(e)
+	var ce *ConstraintError
+	if errors.As(err, &ce) && ce.Err == RecordInUse {
+		return InUse(ce.Constraint, inUseMessage)
+	}
+	return err
+}
+
+// InUse - the record can't be removed, other records refer to it.
+func InUse(constraint, message string) error {
+	return NewErrorWrapper(Logic, &ConstraintError{Constraint: constraint, Err: RecordInUse}, message)
+}
+
+// UniqueViolation - the value of field is taken by another record.
+func UniqueViolation(constraint, field string) error {
+	message := "record already exists"
+	if field != "" {
+		message = field + " is already taken"
+	}
+	return NewErrorWrapper(Exist, &ConstraintError{Constraint: constraint, Field: field, Err: RecordExists}, message)
+}
+
+// ForeignKeyViolation - field refers to the missing record.
+func ForeignKeyViolation(constraint, field string) error {
+	message := "referenced record is not found"
+	if field != "" {
+		message = field + " is not found"
+	}
+	return NewErrorWrapper(BrokenLink, &ConstraintError{Constraint: constraint, Field: field, Err: BrokenRef}, message)
+}
@@ -0,0 +1,153 @@
+package errs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestHandleErrorDB(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		code       int
+		message    string
+		sentinel   error
+		constraint string
+		field      string
+	}{
+		{
+			name:     "no rows",
+			err:      sql.ErrNoRows,
+			code:     NotExist,
+			message:  "not found",
+			sentinel: RecordNotFound,
+		},
+		{
+			name: "duplicate username",
+			err: &pq.Error{Code: "23505", Constraint: "users_username_key",
+				Detail: "Key (username)=(alice) already exists."},
+			code:       Exist,
+			message:    "username is already taken",
+			sentinel:   RecordExists,
+			constraint: "users_username_key",
+			field:      "username",
+		},
+		{
+			name: "duplicate order number of user",
+			err: fmt.Errorf("exec: %w", &pq.Error{Code: "23505", Constraint: "uq_user_orders",
+				Detail: "Key (user_id, number)=(6f1e, 1) already exists."}),
+			code:       Exist,
+			message:    "number is already taken",
+			sentinel:   RecordExists,
+			constraint: "uq_user_orders",
+			field:      "number",
+		},
+		{
+			name: "missing product",
+			err: &pq.Error{Code: "23503", Constraint: "order_products_product_id_fkey",
+				Detail: `Key (product_id)=(6f1e) is not present in table "products".`},
+			code:       BrokenLink,
+			message:    "product_id is not found",
+			sentinel:   BrokenRef,
+			constraint: "order_products_product_id_fkey",
+			field:      "product_id",
+		},
+		{
+			name: "referenced product",
+			err: &pq.Error{Code: "23503", Constraint: "order_products_product_id_fkey",
+				Detail: `Key (id)=(6f1e) is still referenced from table "order_products".`},
+			code:       Logic,
+			message:    "record is used by other records",
+			sentinel:   RecordInUse,
+			constraint: "order_products_product_id_fkey",
+		},
+		{
+			name: "check",
+			err: &pq.Error{Code: "23514", Constraint: "products_left_in_stock_check",
+				Message: `new row for relation "products" violates check constraint "products_left_in_stock_check"`},
+			code:       Validation,
+			message:    `invalid value: new row for relation "products" violates check constraint "products_left_in_stock_check"`,
+			sentinel:   InvalidValue,
+			constraint: "products_left_in_stock_check",
+		},
+		{
+			name:     "bad uuid",
+			err:      &pq.Error{Code: "22P02", Message: `invalid input syntax for type uuid: "abc"`},
+			code:     Validation,
+			message:  `invalid input syntax for type uuid: "abc"`,
+			sentinel: InvalidValue,
+		},
+		{
+			name:    "deadline",
+			err:     fmt.Errorf("query: %w", context.DeadlineExceeded),
+			code:    Timeout,
+			message: "query is not completed in time",
+		},
+		{
+			name:    "canceled",
+			err:     context.Canceled,
+			code:    Canceled,
+			message: "query is canceled",
+		},
+		{
+			name:    "statement timeout",
+			err:     &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"},
+			code:    Timeout,
+			message: "query is not completed in time",
+		},
+		{
+			name:    "other postgres error",
+			err:     &pq.Error{Code: "42P01", Message: `relation "orders" does not exist`},
+			code:    Database,
+			message: "db another error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := HandleErrorDB(tt.err)
+
+			var ew CustomErrorWrapper
+			require.True(t, errors.As(err, &ew))
+			assert.Equal(t, tt.code, ew.Dig().Code)
+			assert.Contains(t, ew.Dig().Message, tt.message)
+
+			if tt.sentinel == nil {
+				return
+			}
+			assert.ErrorIs(t, err, tt.sentinel)
+
+			var ce *ConstraintError
+			if errors.As(err, &ce) {
+				assert.Equal(t, tt.constraint, ce.Constraint)
+				assert.Equal(t, tt.field, ce.Field)
+			}
+		})
+	}
+}
+
+func TestHandleRemoveErrorDB(t *testing.T) {
+	err := HandleRemoveErrorDB(&pq.Error{Code: "23503", Constraint: "order_products_product_id_fkey",
+		Detail: `Key (id)=(6f1e) is still referenced from table "order_products".`}, "product is used in orders")
+
+	var ew CustomErrorWrapper
+	require.True(t, errors.As(err, &ew))
+	assert.Equal(t, Logic, ew.Code)
+	assert.Equal(t, "product is used in orders", ew.Message)
+	assert.ErrorIs(t, err, RecordInUse)
+
+	var ce *ConstraintError
+	require.True(t, errors.As(err, &ce))
+	assert.Equal(t, "order_products_product_id_fkey", ce.Constraint)
+
+	// other errors are handled as usual
+	err = HandleRemoveErrorDB(sql.ErrNoRows, "product is used in orders")
+	require.True(t, errors.As(err, &ew))
+	assert.Equal(t, NotExist, ew.Code)
+}
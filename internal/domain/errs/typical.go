@@ -20,4 +20,7 @@ const (
 	RemoteConnection              // Connection to remote service error.
 	Validation                    // Input validation error.
 	Unanticipated                 // Unanticipated error.
+	PreconditionFailed            // Item was changed since the version known by client.
+	Timeout                       // Operation is not completed before the deadline.
+	Canceled                      // Operation is canceled by the caller.
 )
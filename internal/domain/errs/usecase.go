@@ -1,8 +1,13 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	InvalidPassword = errors.New("invalid password")
 	LogicalError    = errors.New("logical error")
+	// OutOfStock - the amount is greater than left in stock, it's the logical error too.
+	OutOfStock = fmt.Errorf("%w: out of stock", LogicalError)
 )
@@ -0,0 +1,28 @@
+package category
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+)
+
+type Repository interface {
+	Get(ctx context.Context, id string) (*entity.Category, error)
+	GetBySlug(ctx context.Context, slug string) (*entity.Category, error)
+	GetAll(ctx context.Context) (*[]entity.Category, error)
+	// GetSubtreeIDs returns ID of category with IDs of all its descendants.
+	GetSubtreeIDs(ctx context.Context, id string) ([]string, error)
+
+	Store(ctx context.Context, category *entity.Category) (string, error)
+	Update(ctx context.Context, category *entity.Category) error
+	Remove(ctx context.Context, id string) error
+}
+
+func NewRepository(db dbtx.DB) Repository {
+	return newCategoryPostgresRepository(db)
+}
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newCategoryMemoryRepository(db)
+}
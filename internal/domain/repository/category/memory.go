@@ -0,0 +1,141 @@
+package category
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"sort"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newCategoryMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) Get(_ context.Context, id string) (*entity.Category, error) {
+	var category entity.Category
+	err := r.db.Read(func(t *memdb.Tables) error {
+		c, ok := t.Categories[id]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		category = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *memoryRepo) GetBySlug(_ context.Context, slug string) (*entity.Category, error) {
+	var category entity.Category
+	err := r.db.Read(func(t *memdb.Tables) error {
+		c, ok := findBySlug(t, slug)
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		category = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *memoryRepo) GetAll(_ context.Context) (*[]entity.Category, error) {
+	categories := []entity.Category{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for _, c := range t.Categories {
+			categories = append(categories, c)
+		}
+		return nil
+	})
+	// root categories first, like ORDER BY parent_id NULLS FIRST, name
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := categories[i], categories[j]
+		if (a.ParentID == nil) != (b.ParentID == nil) {
+			return a.ParentID == nil
+		}
+		if a.ParentID != nil && *a.ParentID != *b.ParentID {
+			return *a.ParentID < *b.ParentID
+		}
+		return a.Name < b.Name
+	})
+	return &categories, nil
+}
+
+func (r *memoryRepo) GetSubtreeIDs(_ context.Context, id string) ([]string, error) {
+	ids := []string{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		if _, ok := t.Categories[id]; !ok {
+			return nil
+		}
+		ids = append(ids, id)
+		for i := 0; i < len(ids); i++ {
+			for _, c := range t.Categories {
+				if c.ParentID != nil && *c.ParentID == ids[i] {
+					ids = append(ids, c.ID)
+				}
+			}
+		}
+		return nil
+	})
+	return ids, nil
+}
+
+func (r *memoryRepo) Store(_ context.Context, category *entity.Category) (string, error) {
+	id := memdb.NewID()
+	err := r.db.Write(func(t *memdb.Tables) error {
+		if err := t.CheckCategory(category.ParentID, "parent_id"); err != nil {
+			return err
+		}
+		if _, ok := findBySlug(t, category.Slug); ok {
+			return memdb.ErrExists("slug")
+		}
+		t.Categories[id] = entity.Category{ID: id, ParentID: category.ParentID, Name: category.Name, Slug: category.Slug}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *memoryRepo) Update(_ context.Context, category *entity.Category) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		if _, ok := t.Categories[category.ID]; !ok {
+			return memdb.ErrNotFound()
+		}
+		if err := t.CheckCategory(category.ParentID, "parent_id"); err != nil {
+			return err
+		}
+		if c, ok := findBySlug(t, category.Slug); ok && c.ID != category.ID {
+			return memdb.ErrExists("slug")
+		}
+		t.Categories[category.ID] = *category
+		return nil
+	})
+}
+
+func (r *memoryRepo) Remove(_ context.Context, id string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		// subcategories must be moved or removed first
+		return t.DeleteCategory(id)
+	})
+}
+
+func findBySlug(t *memdb.Tables, slug string) (entity.Category, bool) {
+	for _, c := range t.Categories {
+		if c.Slug == slug {
+			return c, true
+		}
+	}
+	return entity.Category{}, false
+}
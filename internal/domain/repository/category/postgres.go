@@ -0,0 +1,143 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/rs/zerolog/log"
+)
+
+const categoriesTableName = "product_categories"
+
+type repo struct {
+	db dbtx.DB
+}
+
+func newCategoryPostgresRepository(d dbtx.DB) Repository {
+	return &repo{
+		db: d,
+	}
+}
+
+func (r *repo) Get(ctx context.Context, id string) (*entity.Category, error) {
+	query := fmt.Sprintf("SELECT id, parent_id, name, slug FROM %s WHERE id = $1", categoriesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	row := r.db.QueryRowContext(ctx, query, id)
+	category := entity.Category{}
+
+	if err := row.Scan(&category.ID, &category.ParentID, &category.Name, &category.Slug); err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+	return &category, nil
+}
+
+func (r *repo) GetBySlug(ctx context.Context, slug string) (*entity.Category, error) {
+	query := fmt.Sprintf("SELECT id, parent_id, name, slug FROM %s WHERE slug = $1", categoriesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	row := r.db.QueryRowContext(ctx, query, slug)
+	category := entity.Category{}
+
+	if err := row.Scan(&category.ID, &category.ParentID, &category.Name, &category.Slug); err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+	return &category, nil
+}
+
+func (r *repo) GetAll(ctx context.Context) (*[]entity.Category, error) {
+	query := fmt.Sprintf("SELECT id, parent_id, name, slug FROM %s ORDER BY parent_id NULLS FIRST, name", categoriesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+	defer rows.Close()
+
+	categories := []entity.Category{}
+	for rows.Next() {
+		c := entity.Category{}
+		if err = rows.Scan(&c.ID, &c.ParentID, &c.Name, &c.Slug); err != nil {
+			return nil, errs.HandleErrorDB(err)
+		}
+		categories = append(categories, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+
+	return &categories, nil
+}
+
+func (r *repo) GetSubtreeIDs(ctx context.Context, id string) ([]string, error) {
+	query := fmt.Sprintf(`WITH RECURSIVE subtree AS (
+    SELECT id FROM %s WHERE id = $1
+    UNION ALL
+    SELECT c.id FROM %s c JOIN subtree s ON c.parent_id = s.id
+) SELECT id FROM subtree`, categoriesTableName, categoriesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var categoryID string
+		if err = rows.Scan(&categoryID); err != nil {
+			return nil, errs.HandleErrorDB(err)
+		}
+		ids = append(ids, categoryID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+
+	return ids, nil
+}
+
+func (r *repo) Store(ctx context.Context, category *entity.Category) (string, error) {
+	var id string
+	query := fmt.Sprintf("INSERT INTO %s (parent_id, name, slug) VALUES ($1, $2, $3) RETURNING id", categoriesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	row := r.db.QueryRowContext(ctx, query, category.ParentID, category.Name, category.Slug)
+	if err := row.Scan(&id); err != nil {
+		return "", errs.HandleErrorDB(err)
+	}
+
+	return id, nil
+}
+
+func (r *repo) Update(ctx context.Context, category *entity.Category) error {
+	query := fmt.Sprintf("UPDATE %s SET parent_id = $1, name = $2, slug = $3 WHERE id = $4", categoriesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	res, err := r.db.ExecContext(ctx, query, category.ParentID, category.Name, category.Slug, category.ID)
+	if err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errs.HandleErrorDB(sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *repo) Remove(ctx context.Context, id string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", categoriesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		// subcategories must be moved or removed first
+		return errs.HandleRemoveErrorDB(err, "category has subcategories")
+	}
+	return nil
+}
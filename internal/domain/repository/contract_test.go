@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/lib/pq"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/repotest"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+// testDatabaseURL - the database is migrated and cleared by tests, don't point it to the data you need.
+const testDatabaseURL = "TEST_DATABASE_URL"
+
+func TestMemoryRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return repository.NewMemoryRepository(memdb.New())
+	})
+}
+
+func TestPostgresRepository(t *testing.T) {
+	url := os.Getenv(testDatabaseURL)
+	if url == "" {
+		t.Skip(testDatabaseURL + " is not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+	require.NoError(t, err)
+
+	m, err := migrate.New("file://../../../database/migrations", url)
+	require.NoError(t, err)
+	defer m.Close()
+	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("migrate up: %s", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		// other tables are cleared by cascade
+		_, err := db.Exec("TRUNCATE users, products, product_categories CASCADE")
+		require.NoError(t, err)
+		return repository.NewRepository(dbtx.New(db))
+	})
+}
@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+)
+
+type Repository interface {
+	// Reserve stores the record, if there is no live record with the same key of the user.
+	// Otherwise the existing record is returned and created is false.
+	Reserve(ctx context.Context, record *entity.IdempotencyRecord) (existing *entity.IdempotencyRecord, created bool, err error)
+	Complete(ctx context.Context, record *entity.IdempotencyRecord) error
+	Release(ctx context.Context, userID, key string) error
+	RemoveExpired(ctx context.Context) (int64, error)
+}
+
+func NewRepository(db dbtx.DB) Repository {
+	return newIdempotencyPostgresRepository(db)
+}
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newIdempotencyMemoryRepository(db)
+}
@@ -0,0 +1,100 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"time"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newIdempotencyMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) Reserve(_ context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+	var existing entity.IdempotencyRecord
+	created := false
+	err := r.db.Write(func(t *memdb.Tables) error {
+		k := memdb.IdempotencyKey{UserID: record.UserID, Key: record.Key}
+		now := time.Now()
+
+		if err := t.CheckUser(record.UserID, "user_id"); err != nil {
+			return err
+		}
+		// expired record is taken over by the new request
+		if rec, ok := t.Idempotency[k]; ok && rec.ExpiresAt.After(now) {
+			existing = copyRecord(rec)
+			return nil
+		}
+
+		record.CreatedAt = now
+		t.Idempotency[k] = entity.IdempotencyRecord{
+			UserID:      record.UserID,
+			Key:         record.Key,
+			RequestHash: record.RequestHash,
+			CreatedAt:   record.CreatedAt,
+			ExpiresAt:   record.ExpiresAt,
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		return record, true, nil
+	}
+	return &existing, false, nil
+}
+
+func (r *memoryRepo) Complete(_ context.Context, record *entity.IdempotencyRecord) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		k := memdb.IdempotencyKey{UserID: record.UserID, Key: record.Key}
+		rec, ok := t.Idempotency[k]
+		if !ok {
+			return nil
+		}
+		rec.StatusCode = record.StatusCode
+		rec.ContentType = record.ContentType
+		rec.ResponseBody = append([]byte(nil), record.ResponseBody...)
+		t.Idempotency[k] = rec
+		return nil
+	})
+}
+
+func (r *memoryRepo) Release(_ context.Context, userID, key string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		k := memdb.IdempotencyKey{UserID: userID, Key: key}
+		if rec, ok := t.Idempotency[k]; ok && !rec.IsCompleted() {
+			delete(t.Idempotency, k)
+		}
+		return nil
+	})
+}
+
+func (r *memoryRepo) RemoveExpired(_ context.Context) (int64, error) {
+	var n int64
+	err := r.db.Write(func(t *memdb.Tables) error {
+		now := time.Now()
+		for k, rec := range t.Idempotency {
+			if !rec.ExpiresAt.After(now) {
+				delete(t.Idempotency, k)
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+// copyRecord - the response body is not shared with the caller.
+func copyRecord(rec entity.IdempotencyRecord) entity.IdempotencyRecord {
+	rec.ResponseBody = append([]byte(nil), rec.ResponseBody...)
+	return rec
+}
@@ -0,0 +1,90 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const keysTableName = "idempotency_keys"
+
+type repo struct {
+	db dbtx.DB
+}
+
+func newIdempotencyPostgresRepository(d dbtx.DB) Repository {
+	return &repo{
+		db: d,
+	}
+}
+
+func (r *repo) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+	// expired record is taken over by the new request
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, key, request_hash, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status_code = NULL,
+			content_type = NULL, response_body = NULL, created_at = now(), expires_at = EXCLUDED.expires_at
+		WHERE %s.expires_at <= now()
+		RETURNING created_at`, keysTableName, keysTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	err := r.db.QueryRowContext(ctx, query, record.UserID, record.Key, record.RequestHash, record.ExpiresAt).
+		Scan(&record.CreatedAt)
+	if err == nil {
+		return record, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, errs.HandleErrorDB(err)
+	}
+
+	query = fmt.Sprintf(`SELECT user_id, key, request_hash, COALESCE(status_code, 0), COALESCE(content_type, ''),
+		response_body, created_at, expires_at FROM %s WHERE user_id = $1 AND key = $2`, keysTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	existing := entity.IdempotencyRecord{}
+	err = r.db.QueryRowContext(ctx, query, record.UserID, record.Key).Scan(&existing.UserID, &existing.Key,
+		&existing.RequestHash, &existing.StatusCode, &existing.ContentType, &existing.ResponseBody,
+		&existing.CreatedAt, &existing.ExpiresAt)
+	if err != nil {
+		return nil, false, errs.HandleErrorDB(err)
+	}
+	return &existing, false, nil
+}
+
+func (r *repo) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	query := fmt.Sprintf(`UPDATE %s SET status_code = $1, content_type = $2, response_body = $3
+		WHERE user_id = $4 AND key = $5`, keysTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	_, err := r.db.ExecContext(ctx, query, record.StatusCode, record.ContentType, record.ResponseBody, record.UserID, record.Key)
+	if err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	return nil
+}
+
+func (r *repo) Release(ctx context.Context, userID, key string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND key = $2 AND status_code IS NULL", keysTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	if _, err := r.db.ExecContext(ctx, query, userID, key); err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	return nil
+}
+
+func (r *repo) RemoveExpired(ctx context.Context) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE expires_at <= now()", keysTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	res, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, errs.HandleErrorDB(err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
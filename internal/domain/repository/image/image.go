@@ -0,0 +1,21 @@
+package image
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+)
+
+type Repository interface {
+	GetAllByProductID(ctx context.Context, productID string) (*[]entity.ProductImage, error)
+	Store(ctx context.Context, image *entity.ProductImage) (string, error)
+}
+
+func NewRepository(db dbtx.DB) Repository {
+	return newImagePostgresRepository(db)
+}
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newImageMemoryRepository(db)
+}
@@ -0,0 +1,66 @@
+package image
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"sort"
+	"time"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newImageMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) GetAllByProductID(_ context.Context, productID string) (*[]entity.ProductImage, error) {
+	images := []entity.ProductImage{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for _, i := range t.Images {
+			if i.ProductID == productID {
+				images = append(images, i)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].CreatedAt.Before(images[j].CreatedAt)
+	})
+	return &images, nil
+}
+
+func (r *memoryRepo) Store(_ context.Context, image *entity.ProductImage) (string, error) {
+	err := r.db.Write(func(t *memdb.Tables) error {
+		if err := t.CheckProduct(image.ProductID); err != nil {
+			return err
+		}
+		for _, i := range t.Images {
+			if i.Key == image.Key {
+				return memdb.ErrExists("storage_key")
+			}
+		}
+
+		image.ID = memdb.NewID()
+		image.CreatedAt = time.Now()
+		t.Images[image.ID] = entity.ProductImage{
+			ID:           image.ID,
+			ProductID:    image.ProductID,
+			Key:          image.Key,
+			ThumbnailKey: image.ThumbnailKey,
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			CreatedAt:    image.CreatedAt,
+		}
+		t.TouchProduct(image.ProductID)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return image.ID, nil
+}
@@ -0,0 +1,64 @@
+package image
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const imagesTableName = "product_images"
+
+type repo struct {
+	db dbtx.DB
+}
+
+func newImagePostgresRepository(d dbtx.DB) Repository {
+	return &repo{
+		db: d,
+	}
+}
+
+func (r *repo) GetAllByProductID(ctx context.Context, productID string) (*[]entity.ProductImage, error) {
+	query := fmt.Sprintf(`SELECT id, product_id, storage_key, thumbnail_key, content_type, size, created_at
+		FROM %s WHERE product_id = $1 ORDER BY created_at`, imagesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errs.HandleErrorDB(err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+		}
+	}(rows)
+
+	images := []entity.ProductImage{}
+	for rows.Next() {
+		i := entity.ProductImage{}
+		err := rows.Scan(&i.ID, &i.ProductID, &i.Key, &i.ThumbnailKey, &i.ContentType, &i.Size, &i.CreatedAt)
+		if err != nil {
+			continue
+		}
+		images = append(images, i)
+	}
+
+	return &images, nil
+}
+
+func (r *repo) Store(ctx context.Context, image *entity.ProductImage) (string, error) {
+	query := fmt.Sprintf(`INSERT INTO %s (product_id, storage_key, thumbnail_key, content_type, size)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`, imagesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	row := r.db.QueryRowContext(ctx, query, image.ProductID, image.Key, image.ThumbnailKey, image.ContentType, image.Size)
+	if err := row.Scan(&image.ID, &image.CreatedAt); err != nil {
+		return "", errs.HandleErrorDB(err)
+	}
+	return image.ID, nil
+}
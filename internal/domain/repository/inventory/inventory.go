@@ -0,0 +1,24 @@
+package inventory
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+)
+
+// Repository is the inventory ledger. Every movement changes products.left_in_stock
+// in the same transaction, so the stock column always equals the sum of the ledger.
+type Repository interface {
+	GetMovements(ctx context.Context, productID string) (*[]entity.StockMovement, error)
+
+	AddMovement(ctx context.Context, m *entity.StockMovement) (int64, error)
+}
+
+func NewRepository(db dbtx.DB) Repository {
+	return newInventoryPostgresRepository(db)
+}
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newInventoryMemoryRepository(db)
+}
@@ -0,0 +1,41 @@
+package inventory
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newInventoryMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) GetMovements(_ context.Context, productID string) (*[]entity.StockMovement, error) {
+	movements := []entity.StockMovement{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		// the ledger is kept in the order of IDs
+		for _, m := range t.Movements {
+			if m.ProductID == productID {
+				movements = append(movements, m)
+			}
+		}
+		return nil
+	})
+	return &movements, nil
+}
+
+func (r *memoryRepo) AddMovement(_ context.Context, m *entity.StockMovement) (int64, error) {
+	err := r.db.Write(func(t *memdb.Tables) error {
+		return t.ChangeStock(m)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return m.ID, nil
+}
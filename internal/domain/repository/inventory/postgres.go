@@ -0,0 +1,106 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	movementsTableName = "product_stock_movements"
+	productsTableName  = "products"
+	variantsTableName  = "product_variants"
+)
+
+type repo struct {
+	db dbtx.DB
+}
+
+func newInventoryPostgresRepository(d dbtx.DB) Repository {
+	return &repo{
+		db: d,
+	}
+}
+
+func (r *repo) GetMovements(ctx context.Context, productID string) (*[]entity.StockMovement, error) {
+	query := fmt.Sprintf(`SELECT id, product_id, variant_id, kind, quantity, balance, reason, actor_user_id, order_id, created_at
+		FROM %s WHERE product_id = $1 ORDER BY id`, movementsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+	defer rows.Close()
+
+	movements := []entity.StockMovement{}
+	for rows.Next() {
+		m := entity.StockMovement{}
+		err = rows.Scan(&m.ID, &m.ProductID, &m.VariantID, &m.Kind, &m.Quantity, &m.Balance, &m.Reason, &m.ActorUserID, &m.OrderID, &m.CreatedAt)
+		if err != nil {
+			return nil, errs.HandleErrorDB(err)
+		}
+		movements = append(movements, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+
+	return &movements, nil
+}
+
+func (r *repo) AddMovement(ctx context.Context, m *entity.StockMovement) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
+		return 0, errs.HandleErrorDB(err)
+	}
+	defer tx.Rollback()
+
+	// stock of variant is kept apart from the stock of the product itself
+	stockTable, stockID := productsTableName, m.ProductID
+	selQuery := fmt.Sprintf(`SELECT left_in_stock FROM %s WHERE id = $1 FOR UPDATE`, productsTableName)
+	selArgs := []interface{}{m.ProductID}
+	if m.VariantID != nil {
+		stockTable, stockID = variantsTableName, *m.VariantID
+		selQuery = fmt.Sprintf(`SELECT left_in_stock FROM %s WHERE id = $1 AND product_id = $2 FOR UPDATE`, variantsTableName)
+		selArgs = []interface{}{*m.VariantID, m.ProductID}
+	}
+	log.Ctx(ctx).Debug().Msg("Query: " + selQuery)
+
+	var stock int
+	if err = tx.QueryRowContext(ctx, selQuery, selArgs...).Scan(&stock); err != nil {
+		return 0, errs.HandleErrorDB(err)
+	}
+
+	if stock+m.Quantity < 0 {
+		return 0, errs.NewErrorWrapper(errs.Logic, errs.OutOfStock, "not enough amount in stock")
+	}
+
+	updateQuery := fmt.Sprintf(`UPDATE %s SET left_in_stock = $1 WHERE id = $2`, stockTable)
+	log.Ctx(ctx).Debug().Msg("Query: " + updateQuery)
+
+	if _, err = tx.ExecContext(ctx, updateQuery, stock+m.Quantity, stockID); err != nil {
+		return 0, errs.HandleErrorDB(err)
+	}
+
+	insQuery := fmt.Sprintf(`INSERT INTO %s (product_id, variant_id, kind, quantity, balance, reason, actor_user_id, order_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, balance, created_at`, movementsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + insQuery)
+
+	row := tx.QueryRowContext(ctx, insQuery, m.ProductID, m.VariantID, m.Kind, m.Quantity, stock+m.Quantity, m.Reason, m.ActorUserID, m.OrderID)
+	if err = row.Scan(&m.ID, &m.Balance, &m.CreatedAt); err != nil {
+		return 0, errs.HandleErrorDB(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
+		return 0, errs.HandleErrorDB(err)
+	}
+
+	return m.ID, nil
+}
@@ -0,0 +1,200 @@
+// Package memdb keeps the data of in-memory repositories. Tables of all entities are under one lock,
+// so the repositories check uniqueness and references between them like the database does.
+package memdb
+
+import (
+	"github.com/google/uuid"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"sync"
+)
+
+type Follow struct {
+	UserID     string
+	FollowerID string
+}
+
+// OrderLineKey - VariantID is empty for the line of simple product.
+type OrderLineKey struct {
+	OrderID   string
+	ProductID string
+	VariantID string
+}
+
+type OrderLine struct {
+	ID     int64
+	Amount int
+}
+
+type IdempotencyKey struct {
+	UserID string
+	Key    string
+}
+
+// Tables - rows are kept by value and must be replaced, not changed in place (pointers and maps
+// inside them are shared with the copies of tables). Products and variants are kept without prices
+// and images, those are in their own tables.
+type Tables struct {
+	Users         map[string]entity.User
+	Followers     map[Follow]struct{}
+	Profiles      map[string]entity.Profile // by user ID
+	Orders        map[string]entity.Order
+	OrderLines    map[OrderLineKey]OrderLine
+	Products      map[string]entity.Product
+	ProductPrices map[string]map[string]float64 // product ID -> currency -> price
+	ProductTags   map[string]map[string]struct{}
+	Variants      map[string]entity.Variant
+	VariantPrices map[string]map[string]float64
+	Categories    map[string]entity.Category
+	Images        map[string]entity.ProductImage
+	Movements     []entity.StockMovement
+	Idempotency   map[IdempotencyKey]entity.IdempotencyRecord
+
+	serial int64
+}
+
+func newTables() *Tables {
+	return &Tables{
+		Users:         map[string]entity.User{},
+		Followers:     map[Follow]struct{}{},
+		Profiles:      map[string]entity.Profile{},
+		Orders:        map[string]entity.Order{},
+		OrderLines:    map[OrderLineKey]OrderLine{},
+		Products:      map[string]entity.Product{},
+		ProductPrices: map[string]map[string]float64{},
+		ProductTags:   map[string]map[string]struct{}{},
+		Variants:      map[string]entity.Variant{},
+		VariantPrices: map[string]map[string]float64{},
+		Categories:    map[string]entity.Category{},
+		Images:        map[string]entity.ProductImage{},
+		Movements:     []entity.StockMovement{},
+		Idempotency:   map[IdempotencyKey]entity.IdempotencyRecord{},
+	}
+}
+
+func (t *Tables) clone() *Tables {
+	c := &Tables{
+		Users:         copyMap(t.Users),
+		Followers:     copyMap(t.Followers),
+		Profiles:      copyMap(t.Profiles),
+		Orders:        copyMap(t.Orders),
+		OrderLines:    copyMap(t.OrderLines),
+		Products:      copyMap(t.Products),
+		ProductPrices: make(map[string]map[string]float64, len(t.ProductPrices)),
+		ProductTags:   make(map[string]map[string]struct{}, len(t.ProductTags)),
+		Variants:      copyMap(t.Variants),
+		VariantPrices: make(map[string]map[string]float64, len(t.VariantPrices)),
+		Categories:    copyMap(t.Categories),
+		Images:        copyMap(t.Images),
+		Movements:     append([]entity.StockMovement{}, t.Movements...),
+		Idempotency:   copyMap(t.Idempotency),
+		serial:        t.serial,
+	}
+	for id, prices := range t.ProductPrices {
+		c.ProductPrices[id] = copyMap(prices)
+	}
+	for id, tags := range t.ProductTags {
+		c.ProductTags[id] = copyMap(tags)
+	}
+	for id, prices := range t.VariantPrices {
+		c.VariantPrices[id] = copyMap(prices)
+	}
+	return c
+}
+
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// NextSerial - one sequence for all serial IDs (profiles, order lines, stock movements).
+func (t *Tables) NextSerial() int64 {
+	t.serial++
+	return t.serial
+}
+
+// NewID returns UUID for the new row.
+func NewID() string {
+	return uuid.NewString()
+}
+
+type locker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+}
+
+// noLock - the transaction already holds the lock of the DB.
+type noLock struct{}
+
+func (noLock) Lock()    {}
+func (noLock) Unlock()  {}
+func (noLock) RLock()   {}
+func (noLock) RUnlock() {}
+
+type DB struct {
+	mu     locker
+	tables *Tables
+}
+
+func New() *DB {
+	return &DB{mu: &sync.RWMutex{}, tables: newTables()}
+}
+
+// Read runs fn with the tables, which must not be changed.
+func (db *DB) Read(fn func(t *Tables) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return fn(db.tables)
+}
+
+// Write runs fn exclusively. To keep the statement atomic fn checks everything it needs
+// before the first change of tables.
+func (db *DB) Write(fn func(t *Tables) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return fn(db.tables)
+}
+
+// Tx runs fn on the copy of tables, which replaces them if fn succeeds. Other readers and writers wait
+// for the end of the transaction, so it's serializable. The nested transaction works like a savepoint.
+// fn must use only tx, the outer DB is locked.
+func (db *DB) Tx(fn func(tx *DB) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx := &DB{mu: noLock{}, tables: db.tables.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	db.tables = tx.tables
+	return nil
+}
+
+// The errors have the same codes as the errors of postgres repositories.
+
+func ErrNotFound() error {
+	return errs.NewErrorWrapper(errs.NotExist, errs.RecordNotFound, "not found")
+}
+
+// ErrExists - the value of field is taken, constraint names are known only to the database.
+func ErrExists(field string) error {
+	return errs.UniqueViolation("", field)
+}
+
+// ErrBrokenRef - field refers to the missing record.
+func ErrBrokenRef(field string) error {
+	return errs.ForeignKeyViolation("", field)
+}
+
+func ErrInUse(message string) error {
+	return errs.NewErrorWrapper(errs.Logic, errs.RecordInUse, message)
+}
+
+func ErrVersionChanged(message string) error {
+	return errs.NewErrorWrapper(errs.PreconditionFailed, errs.VersionChanged, message)
+}
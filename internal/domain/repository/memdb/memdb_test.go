@@ -0,0 +1,94 @@
+package memdb
+
+import (
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTxRollback(t *testing.T) {
+	db := New()
+	txErr := errors.New("tx error")
+
+	err := db.Tx(func(tx *DB) error {
+		err := tx.Write(func(t *Tables) error {
+			t.Users["u1"] = entity.User{ID: "u1", Username: "first"}
+			return nil
+		})
+		require.NoError(t, err)
+
+		// failed nested transaction doesn't break the outer one
+		err = tx.Tx(func(tx *DB) error {
+			_ = tx.Write(func(t *Tables) error {
+				t.Users["u2"] = entity.User{ID: "u2", Username: "second"}
+				return nil
+			})
+			return txErr
+		})
+		require.ErrorIs(t, err, txErr)
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.Tx(func(tx *DB) error {
+		_ = tx.Write(func(t *Tables) error {
+			delete(t.Users, "u1")
+			return nil
+		})
+		return txErr
+	})
+	require.ErrorIs(t, err, txErr)
+
+	_ = db.Read(func(tables *Tables) error {
+		require.Len(t, tables.Users, 1)
+		require.Contains(t, tables.Users, "u1")
+		return nil
+	})
+}
+
+func TestChangeStock(t *testing.T) {
+	tables := newTables()
+	tables.Products["p1"] = entity.Product{ID: "p1", LeftInStock: 2, Version: 1}
+	tables.Variants["v1"] = entity.Variant{ID: "v1", ProductID: "p1", LeftInStock: 1}
+	variantID, wrongVariantID := "v1", "v2"
+
+	err := tables.ChangeStock(&entity.StockMovement{ProductID: "p1", Quantity: -3})
+	require.ErrorIs(t, err, errs.LogicalError)
+
+	err = tables.ChangeStock(&entity.StockMovement{ProductID: "p1", VariantID: &wrongVariantID, Quantity: 1})
+	require.ErrorIs(t, err, errs.RecordNotFound)
+
+	m := entity.StockMovement{ProductID: "p1", Quantity: -2}
+	require.NoError(t, tables.ChangeStock(&m))
+	require.Equal(t, 0, m.Balance)
+	require.Equal(t, 2, tables.Products["p1"].Version)
+
+	m = entity.StockMovement{ProductID: "p1", VariantID: &variantID, Quantity: 4}
+	require.NoError(t, tables.ChangeStock(&m))
+	require.Equal(t, 5, tables.Variants["v1"].LeftInStock)
+	require.Len(t, tables.Movements, 2)
+}
+
+func TestDeleteProduct(t *testing.T) {
+	tables := newTables()
+	tables.Products["p1"] = entity.Product{ID: "p1"}
+	tables.Variants["v1"] = entity.Variant{ID: "v1", ProductID: "p1"}
+	tables.VariantPrices["v1"] = map[string]float64{"USD": 1}
+	tables.ProductPrices["p1"] = map[string]float64{"USD": 2}
+	tables.Movements = []entity.StockMovement{{ID: 1, ProductID: "p1"}}
+	tables.OrderLines[OrderLineKey{OrderID: "o1", ProductID: "p1"}] = OrderLine{ID: 2, Amount: 1}
+
+	err := tables.DeleteProduct("p1")
+	require.ErrorIs(t, err, errs.RecordInUse)
+	require.Contains(t, tables.Products, "p1")
+
+	tables.DeleteOrder("o1")
+	require.NoError(t, tables.DeleteProduct("p1"))
+	require.Empty(t, tables.Products)
+	require.Empty(t, tables.Variants)
+	require.Empty(t, tables.VariantPrices)
+	require.Empty(t, tables.ProductPrices)
+	require.Empty(t, tables.Movements)
+}
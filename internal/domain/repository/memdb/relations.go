@@ -0,0 +1,240 @@
+package memdb
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"time"
+)
+
+// Helpers below do what foreign keys and triggers of the database do: check references,
+// cascade removals and bump versions of rows. Like Write callers they check before changes.
+
+// TouchProduct bumps the version of product, its representation includes prices, images, stock, tags and variants.
+func (t *Tables) TouchProduct(id string) {
+	if p, ok := t.Products[id]; ok {
+		p.Version++
+		t.Products[id] = p
+	}
+}
+
+// StockOf returns the stock of the simple product or its variant.
+func (t *Tables) StockOf(productID string, variantID *string) (int, error) {
+	if variantID != nil {
+		v, ok := t.Variants[*variantID]
+		if !ok || v.ProductID != productID {
+			return 0, ErrNotFound()
+		}
+		return v.LeftInStock, nil
+	}
+	p, ok := t.Products[productID]
+	if !ok {
+		return 0, ErrNotFound()
+	}
+	return p.LeftInStock, nil
+}
+
+// ChangeStock applies signed delta to the stock and registers the movement m in the ledger.
+func (t *Tables) ChangeStock(m *entity.StockMovement) error {
+	stock, err := t.StockOf(m.ProductID, m.VariantID)
+	if err != nil {
+		return err
+	}
+	if stock+m.Quantity < 0 {
+		return errs.NewErrorWrapper(errs.Logic, errs.OutOfStock, "not enough amount in stock")
+	}
+	if err = t.checkMovementRefs(m); err != nil {
+		return err
+	}
+
+	if m.VariantID != nil {
+		v := t.Variants[*m.VariantID]
+		v.LeftInStock += m.Quantity
+		t.Variants[v.ID] = v
+		t.TouchProduct(v.ProductID)
+	} else {
+		p := t.Products[m.ProductID]
+		p.LeftInStock += m.Quantity
+		p.Version++
+		t.Products[p.ID] = p
+	}
+
+	m.Balance = stock + m.Quantity
+	t.InsertMovement(m)
+	return nil
+}
+
+// InsertMovement appends the movement to the ledger, references must be checked by the caller.
+func (t *Tables) InsertMovement(m *entity.StockMovement) {
+	m.ID = t.NextSerial()
+	m.CreatedAt = time.Now()
+	t.Movements = append(t.Movements, *m)
+}
+
+func (t *Tables) checkMovementRefs(m *entity.StockMovement) error {
+	if m.ActorUserID != nil {
+		if err := t.CheckUser(*m.ActorUserID, "actor_user_id"); err != nil {
+			return err
+		}
+	}
+	if m.OrderID != nil {
+		if _, ok := t.Orders[*m.OrderID]; !ok {
+			return ErrBrokenRef("order_id")
+		}
+	}
+	return nil
+}
+
+// CheckCategory checks the reference of field to category, nil is no category.
+func (t *Tables) CheckCategory(id *string, field string) error {
+	if id == nil {
+		return nil
+	}
+	if _, ok := t.Categories[*id]; !ok {
+		return ErrBrokenRef(field)
+	}
+	return nil
+}
+
+// CheckProduct checks the reference to product.
+func (t *Tables) CheckProduct(id string) error {
+	if _, ok := t.Products[id]; !ok {
+		return ErrBrokenRef("product_id")
+	}
+	return nil
+}
+
+// CheckUser checks the reference of field to user.
+func (t *Tables) CheckUser(id, field string) error {
+	if _, ok := t.Users[id]; !ok {
+		return ErrBrokenRef(field)
+	}
+	return nil
+}
+
+// DeleteUser removes user with profile, orders, followers and idempotency keys.
+func (t *Tables) DeleteUser(id string) {
+	if _, ok := t.Users[id]; !ok {
+		return
+	}
+	for orderID, o := range t.Orders {
+		if o.UserID == id {
+			t.DeleteOrder(orderID)
+		}
+	}
+	for f := range t.Followers {
+		if f.UserID == id || f.FollowerID == id {
+			delete(t.Followers, f)
+		}
+	}
+	for k := range t.Idempotency {
+		if k.UserID == id {
+			delete(t.Idempotency, k)
+		}
+	}
+	for i, m := range t.Movements {
+		if m.ActorUserID != nil && *m.ActorUserID == id {
+			t.Movements[i].ActorUserID = nil
+		}
+	}
+	delete(t.Profiles, id)
+	delete(t.Users, id)
+}
+
+// DeleteOrder removes order with its lines, the stock is not released here.
+func (t *Tables) DeleteOrder(id string) {
+	for k := range t.OrderLines {
+		if k.OrderID == id {
+			delete(t.OrderLines, k)
+		}
+	}
+	for i, m := range t.Movements {
+		if m.OrderID != nil && *m.OrderID == id {
+			t.Movements[i].OrderID = nil
+		}
+	}
+	delete(t.Orders, id)
+}
+
+// DeleteProduct removes product with prices, tags, variants, images and movements.
+// Ordered products can't be removed.
+func (t *Tables) DeleteProduct(id string) error {
+	if _, ok := t.Products[id]; !ok {
+		return nil
+	}
+	for k := range t.OrderLines {
+		if k.ProductID == id {
+			return ErrInUse("product is used in orders")
+		}
+	}
+
+	for variantID, v := range t.Variants {
+		if v.ProductID == id {
+			delete(t.VariantPrices, variantID)
+			delete(t.Variants, variantID)
+		}
+	}
+	for imageID, i := range t.Images {
+		if i.ProductID == id {
+			delete(t.Images, imageID)
+		}
+	}
+	t.Movements = filterMovements(t.Movements, func(m entity.StockMovement) bool {
+		return m.ProductID != id
+	})
+	delete(t.ProductPrices, id)
+	delete(t.ProductTags, id)
+	delete(t.Products, id)
+	return nil
+}
+
+// DeleteVariant removes variant with prices and movements. Ordered variants can't be removed.
+func (t *Tables) DeleteVariant(id string) error {
+	if _, ok := t.Variants[id]; !ok {
+		return nil
+	}
+	for k := range t.OrderLines {
+		if k.VariantID == id {
+			return ErrInUse("variant is used in orders")
+		}
+	}
+
+	t.Movements = filterMovements(t.Movements, func(m entity.StockMovement) bool {
+		return m.VariantID == nil || *m.VariantID != id
+	})
+	t.TouchProduct(t.Variants[id].ProductID)
+	delete(t.VariantPrices, id)
+	delete(t.Variants, id)
+	return nil
+}
+
+// DeleteCategory removes category without subcategories, its products stay without category.
+func (t *Tables) DeleteCategory(id string) error {
+	if _, ok := t.Categories[id]; !ok {
+		return nil
+	}
+	for _, c := range t.Categories {
+		if c.ParentID != nil && *c.ParentID == id {
+			return ErrInUse("category has subcategories")
+		}
+	}
+
+	for productID, p := range t.Products {
+		if p.CategoryID != nil && *p.CategoryID == id {
+			p.CategoryID = nil
+			p.Version++
+			t.Products[productID] = p
+		}
+	}
+	delete(t.Categories, id)
+	return nil
+}
+
+func filterMovements(movements []entity.StockMovement, keep func(m entity.StockMovement) bool) []entity.StockMovement {
+	res := make([]entity.StockMovement, 0, len(movements))
+	for _, m := range movements {
+		if keep(m) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
@@ -0,0 +1,242 @@
+package order
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"sort"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newOrderMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) Get(_ context.Context, id string) (*entity.Order, error) {
+	var order entity.Order
+	err := r.db.Read(func(t *memdb.Tables) error {
+		o, ok := t.Orders[id]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		order = o
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *memoryRepo) GetAllByUserID(_ context.Context, userID string) (*[]entity.Order, error) {
+	orders := []entity.Order{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for _, o := range t.Orders {
+			if o.UserID == userID {
+				orders = append(orders, o)
+			}
+		}
+		return nil
+	})
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].Number < orders[j].Number
+	})
+	return &orders, nil
+}
+
+func (r *memoryRepo) GetProducts(_ context.Context, id string) (*[]entity.OrderProductView, error) {
+	type line struct {
+		view entity.OrderProductView
+		id   int64
+	}
+	lines := []line{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for k, l := range t.OrderLines {
+			if k.OrderID != id {
+				continue
+			}
+			v := entity.OrderProductView{ID: k.ProductID, Amount: l.Amount}
+			if k.VariantID != "" {
+				variantID := k.VariantID
+				v.VariantID = &variantID
+			}
+			lines = append(lines, line{view: v, id: l.ID})
+		}
+		return nil
+	})
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].id < lines[j].id
+	})
+
+	products := make([]entity.OrderProductView, 0, len(lines))
+	for _, l := range lines {
+		products = append(products, l.view)
+	}
+	return &products, nil
+}
+
+func (r *memoryRepo) Store(_ context.Context, order *entity.Order) (string, bool, error) {
+	var id string
+	var created bool
+	err := r.db.Write(func(t *memdb.Tables) error {
+		// idempotent
+		if o, ok := findOrder(t, order.UserID, order.Number); ok {
+			id = o.ID
+			return nil
+		}
+		if err := t.CheckUser(order.UserID, "user_id"); err != nil {
+			return err
+		}
+		id = memdb.NewID()
+		t.Orders[id] = entity.Order{ID: id, UserID: order.UserID, Number: order.Number, Version: 1}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return id, created, nil
+}
+
+func (r *memoryRepo) Update(_ context.Context, order *entity.Order) (int, error) {
+	var version int
+	err := r.db.Write(func(t *memdb.Tables) error {
+		o, ok := t.Orders[order.ID]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		if order.Version != 0 && order.Version != o.Version {
+			return memdb.ErrVersionChanged("order is changed")
+		}
+		if err := t.CheckUser(order.UserID, "user_id"); err != nil {
+			return err
+		}
+		if other, ok := findOrder(t, order.UserID, order.Number); ok && other.ID != o.ID {
+			return memdb.ErrExists("number")
+		}
+		o.UserID = order.UserID
+		o.Number = order.Number
+		o.Version++
+		t.Orders[o.ID] = o
+		version = o.Version
+		return nil
+	})
+	return version, err
+}
+
+func (r *memoryRepo) Remove(_ context.Context, id string, version int) error {
+	return r.db.Tx(func(tx *memdb.DB) error {
+		return tx.Write(func(t *memdb.Tables) error {
+			o, ok := t.Orders[id]
+			if !ok {
+				if version != 0 {
+					return memdb.ErrNotFound()
+				}
+				return nil
+			}
+			if version != 0 && version != o.Version {
+				return memdb.ErrVersionChanged("order is changed")
+			}
+
+			// reserved products go back to stock before lines are removed
+			for k, l := range t.OrderLines {
+				if k.OrderID != id {
+					continue
+				}
+				if err := changeLineStock(t, o, k, l.Amount, entity.StockRelease, "order removed"); err != nil {
+					return err
+				}
+			}
+			t.DeleteOrder(id)
+			return nil
+		})
+	})
+}
+
+func (r *memoryRepo) AddProduct(_ context.Context, op *entity.OrderProduct) error {
+	return r.db.Tx(func(tx *memdb.DB) error {
+		return tx.Write(func(t *memdb.Tables) error {
+			stock, err := t.StockOf(op.ProductID, op.VariantID)
+			if err != nil {
+				return err
+			}
+			if p := t.Products[op.ProductID]; p.IsArchived() {
+				return errs.NewErrorWrapper(errs.Logic, errs.LogicalError, "product is archived")
+			}
+			if op.Amount > stock {
+				return errs.NewErrorWrapper(errs.Logic, errs.OutOfStock, "not enough amount in stock")
+			}
+			o, ok := t.Orders[op.OrderID]
+			if !ok {
+				return memdb.ErrBrokenRef("order_id")
+			}
+
+			// idempotent
+			k := lineKey(op.OrderID, op.ProductID, op.VariantID)
+			l, ok := t.OrderLines[k]
+			if !ok {
+				l.ID = t.NextSerial()
+			}
+			l.Amount += op.Amount
+			t.OrderLines[k] = l
+
+			return changeLineStock(t, o, k, -op.Amount, entity.StockReservation, "order line added")
+		})
+	})
+}
+
+func (r *memoryRepo) RemoveProduct(_ context.Context, orderID, productID string, variantID *string) error {
+	return r.db.Tx(func(tx *memdb.DB) error {
+		return tx.Write(func(t *memdb.Tables) error {
+			k := lineKey(orderID, productID, variantID)
+			l, ok := t.OrderLines[k]
+			if !ok {
+				return nil
+			}
+			delete(t.OrderLines, k)
+			if l.Amount <= 0 {
+				return nil
+			}
+			return changeLineStock(t, t.Orders[orderID], k, l.Amount, entity.StockRelease, "order line removed")
+		})
+	})
+}
+
+// changeLineStock applies signed delta to the stock of the order line and registers it in the ledger.
+func changeLineStock(t *memdb.Tables, o entity.Order, k memdb.OrderLineKey, delta int, kind, reason string) error {
+	m := entity.StockMovement{
+		ProductID:   k.ProductID,
+		Kind:        kind,
+		Quantity:    delta,
+		Reason:      reason,
+		ActorUserID: &o.UserID,
+		OrderID:     &o.ID,
+	}
+	if k.VariantID != "" {
+		m.VariantID = &k.VariantID
+	}
+	return t.ChangeStock(&m)
+}
+
+func lineKey(orderID, productID string, variantID *string) memdb.OrderLineKey {
+	k := memdb.OrderLineKey{OrderID: orderID, ProductID: productID}
+	if variantID != nil {
+		k.VariantID = *variantID
+	}
+	return k
+}
+
+func findOrder(t *memdb.Tables, userID string, number int) (entity.Order, bool) {
+	for _, o := range t.Orders {
+		if o.UserID == userID && o.Number == number {
+			return o, true
+		}
+	}
+	return entity.Order{}, false
+}
@@ -95,40 +95,41 @@ func (mr *MockRepositoryMockRecorder) GetProducts(ctx, id interface{}) *gomock.C
 }
 
 // Remove mocks base method.
-func (m *MockRepository) Remove(ctx context.Context, id string) error {
+func (m *MockRepository) Remove(ctx context.Context, id string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Remove", ctx, id)
+	ret := m.ctrl.Call(m, "Remove", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Remove indicates an expected call of Remove.
-func (mr *MockRepositoryMockRecorder) Remove(ctx, id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Remove(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockRepository)(nil).Remove), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockRepository)(nil).Remove), ctx, id, version)
 }
 
 // RemoveProduct mocks base method.
-func (m *MockRepository) RemoveProduct(ctx context.Context, orderID, productID string) error {
+func (m *MockRepository) RemoveProduct(ctx context.Context, orderID, productID string, variantID *string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveProduct", ctx, orderID, productID)
+	ret := m.ctrl.Call(m, "RemoveProduct", ctx, orderID, productID, variantID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RemoveProduct indicates an expected call of RemoveProduct.
-func (mr *MockRepositoryMockRecorder) RemoveProduct(ctx, orderID, productID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) RemoveProduct(ctx, orderID, productID, variantID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProduct", reflect.TypeOf((*MockRepository)(nil).RemoveProduct), ctx, orderID, productID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProduct", reflect.TypeOf((*MockRepository)(nil).RemoveProduct), ctx, orderID, productID, variantID)
 }
 
 // Store mocks base method.
-func (m *MockRepository) Store(ctx context.Context, order *entity.Order) (string, error) {
+func (m *MockRepository) Store(ctx context.Context, order *entity.Order) (string, bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Store", ctx, order)
 	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Store indicates an expected call of Store.
@@ -138,11 +139,12 @@ func (mr *MockRepositoryMockRecorder) Store(ctx, order interface{}) *gomock.Call
 }
 
 // Update mocks base method.
-func (m *MockRepository) Update(ctx context.Context, order *entity.Order) error {
+func (m *MockRepository) Update(ctx context.Context, order *entity.Order) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Update", ctx, order)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
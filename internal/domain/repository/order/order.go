@@ -2,8 +2,9 @@ package order
 
 import (
 	"context"
-	"database/sql"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
 )
 
 type Repository interface {
@@ -11,13 +12,19 @@ type Repository interface {
 	GetAllByUserID(ctx context.Context, userId string) (*[]entity.Order, error)
 	GetProducts(ctx context.Context, id string) (*[]entity.OrderProductView, error)
 
-	Store(ctx context.Context, order *entity.Order) (string, error)
-	Update(ctx context.Context, order *entity.Order) error
-	Remove(ctx context.Context, id string) error
+	// Store is idempotent: the order with the same user and number is returned as is, created is false then.
+	Store(ctx context.Context, order *entity.Order) (id string, created bool, err error)
+	// Update checks order.Version if it's not 0 and returns the new version, Remove does the same with version.
+	Update(ctx context.Context, order *entity.Order) (int, error)
+	Remove(ctx context.Context, id string, version int) error
 	AddProduct(ctx context.Context, p *entity.OrderProduct) error
-	RemoveProduct(ctx context.Context, orderID, productID string) error
+	RemoveProduct(ctx context.Context, orderID, productID string, variantID *string) error
 }
 
-func NewRepository(db *sql.DB) Repository {
+func NewRepository(db dbtx.DB) Repository {
 	return newOrderPostgresRepository(db)
 }
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newOrderMemoryRepository(db)
+}
@@ -6,42 +6,51 @@ import (
 	"fmt"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"time"
 )
 
 const (
 	ordersTableName        = "user_orders"
 	orderProductsTableName = "user_order_products"
 	productsTableName      = "products"
+	variantsTableName      = "product_variants"
+	movementsTableName     = "product_stock_movements"
+
+	// noVariantID stands for the variant of simple product in the order lines unique index
+	noVariantID = "00000000-0000-0000-0000-000000000000"
 )
 
+var errVersionChanged = errs.NewErrorWrapper(errs.PreconditionFailed, errs.VersionChanged, "order is changed")
+
 type repo struct {
-	db *sql.DB
+	db dbtx.DB
 }
 
-func newOrderPostgresRepository(d *sql.DB) Repository {
+func newOrderPostgresRepository(d dbtx.DB) Repository {
 	return &repo{
 		db: d,
 	}
 }
 
 func (r *repo) Get(ctx context.Context, id string) (*entity.Order, error) {
-	query := fmt.Sprintf("SELECT id, user_id, number FROM %s WHERE id = $1", ordersTableName)
-	log.Debug().Msg("Query: " + query)
+	query := fmt.Sprintf("SELECT id, user_id, number, version FROM %s WHERE id = $1", ordersTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	row := r.db.QueryRowContext(ctx, query, id)
 	order := entity.Order{}
 
-	if err := row.Scan(&order.ID, &order.UserID, &order.Number); err != nil {
+	if err := row.Scan(&order.ID, &order.UserID, &order.Number, &order.Version); err != nil {
 		return nil, errs.HandleErrorDB(err)
 	}
 	return &order, nil
 }
 
 func (r *repo) GetAllByUserID(ctx context.Context, userID string) (*[]entity.Order, error) {
-	query := fmt.Sprintf("SELECT id, user_id, number FROM %s WHERE user_id = $1", ordersTableName)
-	log.Debug().Msg("Query: " + query)
+	query := fmt.Sprintf("SELECT id, user_id, number, version FROM %s WHERE user_id = $1", ordersTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
@@ -56,7 +65,7 @@ func (r *repo) GetAllByUserID(ctx context.Context, userID string) (*[]entity.Ord
 	orders := []entity.Order{}
 	for rows.Next() {
 		o := entity.Order{}
-		err := rows.Scan(&o.ID, &o.UserID, &o.Number)
+		err := rows.Scan(&o.ID, &o.UserID, &o.Number, &o.Version)
 		if err != nil {
 			//fmt.Println(err)
 			continue
@@ -68,8 +77,8 @@ func (r *repo) GetAllByUserID(ctx context.Context, userID string) (*[]entity.Ord
 }
 
 func (r *repo) GetProducts(ctx context.Context, id string) (*[]entity.OrderProductView, error) {
-	query := fmt.Sprintf("SELECT product_id, amount FROM %s WHERE order_id = $1", orderProductsTableName)
-	log.Debug().Msg("Query: " + query)
+	query := fmt.Sprintf("SELECT product_id, variant_id, amount FROM %s WHERE order_id = $1", orderProductsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	rows, err := r.db.QueryContext(ctx, query, id)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -84,7 +93,7 @@ func (r *repo) GetProducts(ctx context.Context, id string) (*[]entity.OrderProdu
 	products := []entity.OrderProductView{}
 	for rows.Next() {
 		p := entity.OrderProductView{}
-		err := rows.Scan(&p.ID, &p.Amount)
+		err := rows.Scan(&p.ID, &p.VariantID, &p.Amount)
 		if err != nil {
 			//fmt.Println(err)
 			continue
@@ -95,8 +104,9 @@ func (r *repo) GetProducts(ctx context.Context, id string) (*[]entity.OrderProdu
 	return &products, nil
 }
 
-func (r *repo) Store(ctx context.Context, order *entity.Order) (string, error) {
+func (r *repo) Store(ctx context.Context, order *entity.Order) (string, bool, error) {
 	var id string
+	var created bool
 	// idempotent
 	query := fmt.Sprintf(`WITH ins_orders AS (
     INSERT INTO %s (user_id, number)
@@ -106,35 +116,101 @@ func (r *repo) Store(ctx context.Context, order *entity.Order) (string, error) {
 ) SELECT COALESCE(
     (SELECT id FROM ins_orders),
     (SELECT id FROM %s WHERE user_id = $1 AND number = $2)
-) as id`, ordersTableName, ordersTableName)
-	log.Debug().Msg("Query: " + query)
+) as id, EXISTS(SELECT 1 FROM ins_orders) as created`, ordersTableName, ordersTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	row := r.db.QueryRowContext(ctx, query, order.UserID, order.Number)
-	if err := row.Scan(&id); err != nil {
-		return "", errs.HandleErrorDB(err)
+	if err := row.Scan(&id, &created); err != nil {
+		return "", false, errs.HandleErrorDB(err)
 	}
 
-	return id, nil
+	return id, created, nil
 }
 
-func (r *repo) Update(ctx context.Context, order *entity.Order) error {
-	query := fmt.Sprintf("UPDATE %s SET number = $1, user_id = $2 WHERE id = $3", ordersTableName)
-	log.Debug().Msg("Query: " + query)
+func (r *repo) Update(ctx context.Context, order *entity.Order) (int, error) {
+	query := fmt.Sprintf(`UPDATE %s SET number = $1, user_id = $2 WHERE id = $3 AND ($4 = 0 OR version = $4)
+RETURNING version`, ordersTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
-	_, err := r.db.ExecContext(ctx, query, order.Number, order.UserID, order.ID)
+	var version int
+	err := r.db.QueryRowContext(ctx, query, order.Number, order.UserID, order.ID, order.Version).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, r.versionError(ctx, order.ID)
+	}
 	if err != nil {
-		return errs.HandleErrorDB(err)
+		return 0, errs.HandleErrorDB(err)
 	}
 
-	return nil
+	return version, nil
+}
+
+// versionError explains why the versioned query didn't touch the order: it's removed or changed by someone else.
+func (r *repo) versionError(ctx context.Context, id string) error {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id = $1", ordersTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&id); err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	return errVersionChanged
 }
 
-func (r *repo) Remove(ctx context.Context, id string) error {
+func (r *repo) Remove(ctx context.Context, id string, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
+		return errs.HandleErrorDB(err)
+	}
+	defer tx.Rollback()
+
+	if version != 0 {
+		var current int
+		verQuery := fmt.Sprintf("SELECT version FROM %s WHERE id = $1 FOR UPDATE", ordersTableName)
+		log.Ctx(ctx).Debug().Msg("Query: " + verQuery)
+
+		if err = tx.QueryRowContext(ctx, verQuery, id).Scan(&current); err != nil {
+			return errs.HandleErrorDB(err)
+		}
+		if current != version {
+			return errVersionChanged
+		}
+	}
+
+	// reserved products go back to stock before lines are removed by cascade
+	selQuery := fmt.Sprintf("DELETE FROM %s WHERE order_id = $1 RETURNING product_id, variant_id, amount", orderProductsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + selQuery)
+
+	rows, err := tx.QueryContext(ctx, selQuery, id)
+	if err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	lines := []entity.OrderProduct{}
+	for rows.Next() {
+		op := entity.OrderProduct{OrderID: id}
+		if err = rows.Scan(&op.ProductID, &op.VariantID, &op.Amount); err != nil {
+			rows.Close()
+			return errs.HandleErrorDB(err)
+		}
+		lines = append(lines, op)
+	}
+	rows.Close()
+
+	for _, op := range lines {
+		if err = releaseStock(ctx, tx, &op, "order removed"); err != nil {
+			return err
+		}
+	}
+
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", ordersTableName)
-	log.Debug().Msg("Query: " + query)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
-	_, err := r.db.ExecContext(ctx, query, id)
+	if _, err = tx.ExecContext(ctx, query, id); err != nil {
+		return errs.HandleErrorDB(err)
+	}
+
+	err = tx.Commit()
 	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
 		return errs.HandleErrorDB(err)
 	}
 	return nil
@@ -143,54 +219,132 @@ func (r *repo) Remove(ctx context.Context, id string) error {
 func (r *repo) AddProduct(ctx context.Context, op *entity.OrderProduct) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Debug().Msg("Start transaction err: " + err.Error())
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
 		return errs.HandleErrorDB(err)
 	}
 	defer tx.Rollback()
 
-	selQuery := fmt.Sprintf(`SELECT id, left_in_stock FROM %s WHERE id = $1`, productsTableName)
-	log.Debug().Msg("Query: " + selQuery)
+	stock, err := lockStock(ctx, tx, op)
+	if err != nil {
+		return err
+	}
+
+	if op.Amount > stock {
+		return errs.NewErrorWrapper(errs.Logic, errs.OutOfStock, "not enough amount in stock")
+	}
 
-	row := tx.QueryRowContext(ctx, selQuery, op.ProductID)
-	product := entity.Product{}
+	// idempotent
+	insQuery := fmt.Sprintf(`INSERT INTO %s (order_id, product_id, variant_id, amount) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (order_id, product_id, COALESCE(variant_id, '%s'))
+		DO UPDATE SET amount = %s.amount + EXCLUDED.amount`, orderProductsTableName, noVariantID, orderProductsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + insQuery)
 
-	if err = row.Scan(&product.ID, &product.LeftInStock); err != nil {
+	if _, err = tx.ExecContext(ctx, insQuery, op.OrderID, op.ProductID, op.VariantID, op.Amount); err != nil {
 		return errs.HandleErrorDB(err)
 	}
 
-	// idempotent
-	insQuery := fmt.Sprintf(`INSERT INTO %s (order_id, product_id, amount) VALUES ($1, $2, $3)
-		ON CONFLICT (order_id, product_id) DO UPDATE SET amount = %s.amount + EXCLUDED.amount`, orderProductsTableName, orderProductsTableName)
-	log.Debug().Msg("Query: " + insQuery)
+	if err = changeStock(ctx, tx, op, -op.Amount, entity.StockReservation, "order line added"); err != nil {
+		return err
+	}
 
-	if _, err = tx.ExecContext(ctx, insQuery, op.OrderID, op.ProductID, op.Amount); err != nil {
+	err = tx.Commit()
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
 		return errs.HandleErrorDB(err)
 	}
 
-	updateQuery := fmt.Sprintf(`UPDATE %s SET left_in_stock = $1 WHERE id = $2`, productsTableName)
-	log.Debug().Msg("Query: " + updateQuery)
+	return nil
+}
 
-	if _, err = tx.ExecContext(ctx, updateQuery, product.LeftInStock-op.Amount, product.ID); err != nil {
+func (r *repo) RemoveProduct(ctx context.Context, orderID, productID string, variantID *string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
+		return errs.HandleErrorDB(err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE order_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3
+		RETURNING amount`, orderProductsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	op := entity.OrderProduct{OrderID: orderID, ProductID: productID, VariantID: variantID}
+	err = tx.QueryRowContext(ctx, query, orderID, productID, variantID).Scan(&op.Amount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
 		return errs.HandleErrorDB(err)
 	}
 
+	if err = releaseStock(ctx, tx, &op, "order line removed"); err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
-		log.Debug().Msg("Commit transaction err: " + err.Error())
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
 		return errs.HandleErrorDB(err)
 	}
 
 	return nil
 }
 
-func (r *repo) RemoveProduct(ctx context.Context, orderID, productID string) error {
-	query := fmt.Sprintf(`DELETE FROM %s WHERE order_id = $1 AND product_id = $2`, orderProductsTableName)
-	log.Debug().Msg("Query: " + query)
+// lockStock locks the stock row of the order line (variant or simple product) and returns the amount left.
+func lockStock(ctx context.Context, tx dbtx.Querier, op *entity.OrderProduct) (int, error) {
+	var stock int
+	var archivedAt *time.Time
+
+	query := fmt.Sprintf(`SELECT left_in_stock, archived_at FROM %s WHERE id = $1 FOR UPDATE`, productsTableName)
+	args := []interface{}{op.ProductID}
+	if op.VariantID != nil {
+		query = fmt.Sprintf(`SELECT v.left_in_stock, p.archived_at FROM %s v JOIN %s p ON p.id = v.product_id
+			WHERE v.id = $1 AND v.product_id = $2 FOR UPDATE OF v`, variantsTableName, productsTableName)
+		args = []interface{}{*op.VariantID, op.ProductID}
+	}
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&stock, &archivedAt); err != nil {
+		return 0, errs.HandleErrorDB(err)
+	}
+
+	if archivedAt != nil {
+		return 0, errs.NewErrorWrapper(errs.Logic, errs.LogicalError, "product is archived")
+	}
+	return stock, nil
+}
+
+// changeStock applies signed delta to the stock of the order line and registers it in the ledger.
+func changeStock(ctx context.Context, tx dbtx.Querier, op *entity.OrderProduct, delta int, kind, reason string) error {
+	stockTable, stockID := productsTableName, op.ProductID
+	if op.VariantID != nil {
+		stockTable, stockID = variantsTableName, *op.VariantID
+	}
+
+	updateQuery := fmt.Sprintf(`UPDATE %s SET left_in_stock = left_in_stock + $1 WHERE id = $2 RETURNING left_in_stock`, stockTable)
+	log.Ctx(ctx).Debug().Msg("Query: " + updateQuery)
+
+	var balance int
+	if err := tx.QueryRowContext(ctx, updateQuery, delta, stockID).Scan(&balance); err != nil {
+		return errs.HandleErrorDB(err)
+	}
 
-	_, err := r.db.ExecContext(ctx, query, orderID, productID)
+	movQuery := fmt.Sprintf(`INSERT INTO %s (product_id, variant_id, kind, quantity, balance, reason, actor_user_id, order_id)
+		SELECT $1, $2, $3, $4, $5, $6, user_id, id FROM %s WHERE id = $7`, movementsTableName, ordersTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + movQuery)
+
+	_, err := tx.ExecContext(ctx, movQuery, op.ProductID, op.VariantID, kind, delta, balance, reason, op.OrderID)
 	if err != nil {
 		return errs.HandleErrorDB(err)
 	}
 
 	return nil
 }
+
+// releaseStock returns reserved amount of the order line back to stock and registers it in the ledger.
+func releaseStock(ctx context.Context, tx dbtx.Querier, op *entity.OrderProduct, reason string) error {
+	if op.Amount <= 0 {
+		return nil
+	}
+	return changeStock(ctx, tx, op, op.Amount, entity.StockRelease, reason)
+}
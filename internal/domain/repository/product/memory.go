@@ -0,0 +1,315 @@
+package product
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"sort"
+	"time"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newProductMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) Get(_ context.Context, id string) (*entity.Product, error) {
+	var product entity.Product
+	err := r.db.Read(func(t *memdb.Tables) error {
+		p, ok := t.Products[id]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		product = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *memoryRepo) GetAll(_ context.Context, filter entity.ProductFilter) (*[]entity.Product, error) {
+	categories := make(map[string]struct{}, len(filter.CategoryIDs))
+	for _, id := range filter.CategoryIDs {
+		categories[id] = struct{}{}
+	}
+
+	tag := entity.NormalizeTag(filter.Tag)
+
+	products := []entity.Product{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for _, p := range t.Products {
+			if p.IsArchived() {
+				continue
+			}
+			if len(categories) > 0 {
+				if p.CategoryID == nil {
+					continue
+				}
+				if _, ok := categories[*p.CategoryID]; !ok {
+					continue
+				}
+			}
+			if tag != "" {
+				if _, ok := t.ProductTags[p.ID][tag]; !ok {
+					continue
+				}
+			}
+			products = append(products, entity.Product{
+				ID:          p.ID,
+				Name:        p.Name,
+				Description: p.Description,
+				LeftInStock: p.LeftInStock,
+				CategoryID:  p.CategoryID,
+			})
+		}
+		return nil
+	})
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].Name != products[j].Name {
+			return products[i].Name < products[j].Name
+		}
+		return products[i].ID < products[j].ID
+	})
+	return &products, nil
+}
+
+func (r *memoryRepo) GetPrices(_ context.Context, id string) (*[]entity.Price, error) {
+	var prices []entity.Price
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		prices = pricesOf(t, id)
+		return nil
+	})
+	return &prices, nil
+}
+
+func (r *memoryRepo) GetCurrencies(_ context.Context) ([]string, error) {
+	set := map[string]struct{}{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for _, prices := range t.ProductPrices {
+			for currency := range prices {
+				set[currency] = struct{}{}
+			}
+		}
+		return nil
+	})
+
+	currencies := make([]string, 0, len(set))
+	for currency := range set {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies, nil
+}
+
+func (r *memoryRepo) Export(_ context.Context, fn func(product *entity.Product) error) error {
+	// fn may be slow, it gets the snapshot and doesn't hold the lock
+	products := []entity.Product{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for _, p := range t.Products {
+			if p.IsArchived() {
+				continue
+			}
+			products = append(products, entity.Product{
+				ID:          p.ID,
+				Name:        p.Name,
+				Description: p.Description,
+				LeftInStock: p.LeftInStock,
+				CategoryID:  p.CategoryID,
+				Prices:      pricesOf(t, p.ID),
+			})
+		}
+		return nil
+	})
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].ID < products[j].ID
+	})
+
+	for i := range products {
+		if err := fn(&products[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepo) Store(_ context.Context, product *entity.Product) (string, error) {
+	var id string
+	err := r.db.Write(func(t *memdb.Tables) error {
+		var err error
+		id, err = insertProduct(t, product)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *memoryRepo) StoreWithPrices(_ context.Context, product *entity.Product) (string, error) {
+	var id string
+	err := r.db.Write(func(t *memdb.Tables) error {
+		var err error
+		if id, err = insertProduct(t, product); err != nil {
+			return err
+		}
+		prices := make(map[string]float64, len(product.Prices))
+		for _, price := range product.Prices {
+			prices[price.Currency] = price.Price
+			// each stored price makes a new version, like the trigger of the database
+			t.TouchProduct(id)
+		}
+		t.ProductPrices[id] = prices
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *memoryRepo) Update(_ context.Context, id string, input *entity.ProductUpdateInput, version int) (int, error) {
+	var newVersion int
+	err := r.db.Write(func(t *memdb.Tables) error {
+		p, ok := t.Products[id]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		if version != 0 && version != p.Version {
+			return memdb.ErrVersionChanged("product is changed")
+		}
+		if input.Name == nil && input.Description == nil && input.CategoryID == nil {
+			// nothing to change
+			newVersion = p.Version
+			return nil
+		}
+
+		if input.Name != nil {
+			p.Name = *input.Name
+		}
+		if input.Description != nil {
+			p.Description = *input.Description
+		}
+		if input.CategoryID != nil {
+			p.CategoryID = nil
+			if *input.CategoryID != "" {
+				categoryID := *input.CategoryID
+				p.CategoryID = &categoryID
+			}
+			if err := t.CheckCategory(p.CategoryID, "category_id"); err != nil {
+				return err
+			}
+		}
+		p.Version++
+		t.Products[id] = p
+		newVersion = p.Version
+		return nil
+	})
+	return newVersion, err
+}
+
+func (r *memoryRepo) Remove(_ context.Context, id string, version int) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		p, ok := t.Products[id]
+		if !ok {
+			if version != 0 {
+				return memdb.ErrNotFound()
+			}
+			return nil
+		}
+		if version != 0 && version != p.Version {
+			return memdb.ErrVersionChanged("product is changed")
+		}
+		// products from the orders history can be only archived
+		return t.DeleteProduct(id)
+	})
+}
+
+func (r *memoryRepo) Archive(_ context.Context, id string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		p, ok := t.Products[id]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		if p.ArchivedAt == nil {
+			now := time.Now()
+			p.ArchivedAt = &now
+		}
+		p.Version++
+		t.Products[id] = p
+		return nil
+	})
+}
+
+func (r *memoryRepo) Restore(_ context.Context, id string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		p, ok := t.Products[id]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		p.ArchivedAt = nil
+		p.Version++
+		t.Products[id] = p
+		return nil
+	})
+}
+
+func (r *memoryRepo) AddPrice(_ context.Context, productID string, price *entity.Price) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		if err := t.CheckProduct(productID); err != nil {
+			return err
+		}
+		prices, ok := t.ProductPrices[productID]
+		if !ok {
+			prices = map[string]float64{}
+			t.ProductPrices[productID] = prices
+		}
+		prices[price.Currency] = price.Price
+		t.TouchProduct(productID)
+		return nil
+	})
+}
+
+// insertProduct stores the product without prices and registers its stock in the inventory ledger.
+func insertProduct(t *memdb.Tables, product *entity.Product) (string, error) {
+	if err := t.CheckCategory(product.CategoryID, "category_id"); err != nil {
+		return "", err
+	}
+
+	id := memdb.NewID()
+	t.Products[id] = entity.Product{
+		ID:          id,
+		Name:        product.Name,
+		Description: product.Description,
+		LeftInStock: product.LeftInStock,
+		CategoryID:  product.CategoryID,
+		Version:     1,
+	}
+	if product.LeftInStock > 0 {
+		t.InsertMovement(&entity.StockMovement{
+			ProductID: id,
+			Kind:      entity.StockReceipt,
+			Quantity:  product.LeftInStock,
+			Balance:   product.LeftInStock,
+			Reason:    "initial stock",
+		})
+	}
+	return id, nil
+}
+
+func pricesOf(t *memdb.Tables, id string) []entity.Price {
+	prices := []entity.Price{}
+	for currency, price := range t.ProductPrices[id] {
+		prices = append(prices, entity.Price{Currency: currency, Price: price})
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Currency < prices[j].Currency
+	})
+	return prices
+}
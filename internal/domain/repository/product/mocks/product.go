@@ -49,6 +49,34 @@ func (mr *MockRepositoryMockRecorder) AddPrice(ctx, productId, price interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPrice", reflect.TypeOf((*MockRepository)(nil).AddPrice), ctx, productId, price)
 }
 
+// Archive mocks base method.
+func (m *MockRepository) Archive(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockRepositoryMockRecorder) Archive(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockRepository)(nil).Archive), ctx, id)
+}
+
+// Export mocks base method.
+func (m *MockRepository) Export(ctx context.Context, fn func(*entity.Product) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockRepositoryMockRecorder) Export(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockRepository)(nil).Export), ctx, fn)
+}
+
 // Get mocks base method.
 func (m *MockRepository) Get(ctx context.Context, id string) (*entity.Product, error) {
 	m.ctrl.T.Helper()
@@ -65,18 +93,33 @@ func (mr *MockRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
 }
 
 // GetAll mocks base method.
-func (m *MockRepository) GetAll(ctx context.Context) (*[]entity.Product, error) {
+func (m *MockRepository) GetAll(ctx context.Context, filter entity.ProductFilter) (*[]entity.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter)
 	ret0, _ := ret[0].(*[]entity.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockRepositoryMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetAll(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), ctx, filter)
+}
+
+// GetCurrencies mocks base method.
+func (m *MockRepository) GetCurrencies(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrencies", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrencies indicates an expected call of GetCurrencies.
+func (mr *MockRepositoryMockRecorder) GetCurrencies(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencies", reflect.TypeOf((*MockRepository)(nil).GetCurrencies), ctx)
 }
 
 // GetPrices mocks base method.
@@ -95,17 +138,31 @@ func (mr *MockRepositoryMockRecorder) GetPrices(ctx, id interface{}) *gomock.Cal
 }
 
 // Remove mocks base method.
-func (m *MockRepository) Remove(ctx context.Context, id string) error {
+func (m *MockRepository) Remove(ctx context.Context, id string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Remove", ctx, id)
+	ret := m.ctrl.Call(m, "Remove", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Remove indicates an expected call of Remove.
-func (mr *MockRepositoryMockRecorder) Remove(ctx, id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Remove(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockRepository)(nil).Remove), ctx, id, version)
+}
+
+// Restore mocks base method.
+func (m *MockRepository) Restore(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockRepositoryMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockRepository)(nil).Remove), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), ctx, id)
 }
 
 // Store mocks base method.
@@ -139,15 +196,16 @@ func (mr *MockRepositoryMockRecorder) StoreWithPrices(ctx, product interface{})
 }
 
 // Update mocks base method.
-func (m *MockRepository) Update(ctx context.Context, id string, input *entity.ProductUpdateInput) error {
+func (m *MockRepository) Update(ctx context.Context, id string, input *entity.ProductUpdateInput, version int) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, id, input)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Update", ctx, id, input, version)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockRepositoryMockRecorder) Update(ctx, id, input interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Update(ctx, id, input, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, id, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, id, input, version)
 }
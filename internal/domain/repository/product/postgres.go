@@ -4,47 +4,67 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"strings"
 )
 
 const (
-	productTableName = "products"
-	pricesTableName  = "product_prices"
+	productTableName   = "products"
+	pricesTableName    = "product_prices"
+	movementsTableName = "product_stock_movements"
+	tagsTableName      = "product_tags"
 )
 
+var errVersionChanged = errs.NewErrorWrapper(errs.PreconditionFailed, errs.VersionChanged, "product is changed")
+
 type repo struct {
-	db *sql.DB
+	db dbtx.DB
 }
 
-func newProductPostgresRepository(d *sql.DB) Repository {
+func newProductPostgresRepository(d dbtx.DB) Repository {
 	return &repo{
 		db: d,
 	}
 }
 
 func (r *repo) Get(ctx context.Context, id string) (*entity.Product, error) {
-	query := fmt.Sprintf("SELECT id, name, description, left_in_stock FROM %s WHERE id = $1", productTableName)
-	log.Debug().Msg("Query: " + query)
+	query := fmt.Sprintf("SELECT id, name, description, left_in_stock, category_id, archived_at, version FROM %s WHERE id = $1", productTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	row := r.db.QueryRowContext(ctx, query, id)
 	product := entity.Product{}
 
-	err := row.Scan(&product.ID, &product.Name, &product.Description, &product.LeftInStock)
+	err := row.Scan(&product.ID, &product.Name, &product.Description, &product.LeftInStock, &product.CategoryID, &product.ArchivedAt, &product.Version)
 	if err != nil {
 		return nil, errs.HandleErrorDB(err)
 	}
 	return &product, nil
 }
 
-func (r *repo) GetAll(ctx context.Context) (*[]entity.Product, error) {
-	query := fmt.Sprintf("SELECT id, name, description, left_in_stock FROM %s", productTableName)
-	log.Debug().Msg("Query: " + query)
+func (r *repo) GetAll(ctx context.Context, filter entity.ProductFilter) (*[]entity.Product, error) {
+	conditions := []string{"archived_at IS NULL"}
+	args := make([]interface{}, 0)
 
-	rows, err := r.db.QueryContext(ctx, query)
+	if len(filter.CategoryIDs) > 0 {
+		args = append(args, pq.Array(filter.CategoryIDs))
+		conditions = append(conditions, fmt.Sprintf("category_id = ANY($%d)", len(args)))
+	}
+
+	if filter.Tag != "" {
+		args = append(args, entity.NormalizeTag(filter.Tag))
+		conditions = append(conditions, fmt.Sprintf("id IN (SELECT product_id FROM %s WHERE tag = $%d)", tagsTableName, len(args)))
+	}
+
+	query := fmt.Sprintf("SELECT id, name, description, left_in_stock, category_id FROM %s WHERE %s",
+		productTableName, strings.Join(conditions, " AND "))
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, errs.HandleErrorDB(err)
 	}
@@ -58,7 +78,7 @@ func (r *repo) GetAll(ctx context.Context) (*[]entity.Product, error) {
 	products := []entity.Product{}
 	for rows.Next() {
 		p := entity.Product{}
-		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.LeftInStock)
+		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.LeftInStock, &p.CategoryID)
 		if err != nil {
 			//fmt.Println(err)
 			continue
@@ -71,7 +91,7 @@ func (r *repo) GetAll(ctx context.Context) (*[]entity.Product, error) {
 
 func (r *repo) GetPrices(ctx context.Context, id string) (*[]entity.Price, error) {
 	query := fmt.Sprintf("SELECT price, currency FROM %s WHERE product_id = $1", pricesTableName)
-	log.Debug().Msg("Query: " + query)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	rows, err := r.db.QueryContext(ctx, query, id)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -97,13 +117,99 @@ func (r *repo) GetPrices(ctx context.Context, id string) (*[]entity.Price, error
 	return &prices, nil
 }
 
+func (r *repo) GetCurrencies(ctx context.Context) ([]string, error) {
+	query := fmt.Sprintf("SELECT DISTINCT currency FROM %s ORDER BY currency", pricesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+	defer rows.Close()
+
+	currencies := []string{}
+	for rows.Next() {
+		var currency string
+		if err = rows.Scan(&currency); err != nil {
+			return nil, errs.HandleErrorDB(err)
+		}
+		currencies = append(currencies, currency)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+
+	return currencies, nil
+}
+
+func (r *repo) Export(ctx context.Context, fn func(product *entity.Product) error) error {
+	query := fmt.Sprintf(`SELECT p.id, p.name, p.description, p.left_in_stock, p.category_id, pp.currency, pp.price
+		FROM %s p LEFT JOIN %s pp ON pp.product_id = p.id
+		WHERE p.archived_at IS NULL ORDER BY p.id, pp.currency`, productTableName, pricesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	defer rows.Close()
+
+	// rows of one product are consecutive, product is passed to fn when all its prices are read
+	var current *entity.Product
+	for rows.Next() {
+		p := entity.Product{Prices: []entity.Price{}}
+		var currency sql.NullString
+		var price sql.NullFloat64
+		if err = rows.Scan(&p.ID, &p.Name, &p.Description, &p.LeftInStock, &p.CategoryID, &currency, &price); err != nil {
+			return errs.HandleErrorDB(err)
+		}
+
+		if current == nil || current.ID != p.ID {
+			if current != nil {
+				if err = fn(current); err != nil {
+					return err
+				}
+			}
+			current = &p
+		}
+		if currency.Valid {
+			current.Prices = append(current.Prices, entity.Price{Currency: currency.String, Price: price.Float64})
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return errs.HandleErrorDB(err)
+	}
+
+	if current != nil {
+		return fn(current)
+	}
+	return nil
+}
+
 func (r *repo) Store(ctx context.Context, product *entity.Product) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
+		return "", errs.HandleErrorDB(err)
+	}
+	defer tx.Rollback()
+
 	var id string
-	query := fmt.Sprintf("INSERT INTO %s (name, description, left_in_stock) VALUES ($1, $2, $3) RETURNING id", productTableName)
-	log.Debug().Msg("Query: " + query)
+	query := fmt.Sprintf("INSERT INTO %s (name, description, left_in_stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id", productTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	row := tx.QueryRowContext(ctx, query, product.Name, product.Description, product.LeftInStock, product.CategoryID)
+	if err = row.Scan(&id); err != nil {
+		return "", errs.HandleErrorDB(err)
+	}
+
+	if err = storeInitialStock(ctx, tx, id, product.LeftInStock); err != nil {
+		return "", err
+	}
 
-	row := r.db.QueryRowContext(ctx, query, product.Name, product.Description, product.LeftInStock)
-	if err := row.Scan(&id); err != nil {
+	err = tx.Commit()
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
 		return "", errs.HandleErrorDB(err)
 	}
 
@@ -113,27 +219,31 @@ func (r *repo) Store(ctx context.Context, product *entity.Product) (string, erro
 func (r *repo) StoreWithPrices(ctx context.Context, product *entity.Product) (string, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Debug().Msg("Start transaction err: " + err.Error())
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
 		return "", errs.HandleErrorDB(err)
 	}
 	defer tx.Rollback()
 
 	var productID string
-	query := fmt.Sprintf("INSERT INTO %s (name, description, left_in_stock) VALUES ($1, $2, $3) RETURNING id", productTableName)
-	log.Debug().Msg("Query: " + query)
+	query := fmt.Sprintf("INSERT INTO %s (name, description, left_in_stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id", productTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
-	row := tx.QueryRowContext(ctx, query, product.Name, product.Description, product.LeftInStock)
+	row := tx.QueryRowContext(ctx, query, product.Name, product.Description, product.LeftInStock, product.CategoryID)
 	if err = row.Scan(&productID); err != nil {
 		return "", errs.HandleErrorDB(err)
 	}
 
+	if err = storeInitialStock(ctx, tx, productID, product.LeftInStock); err != nil {
+		return "", err
+	}
+
 	query = fmt.Sprintf(`INSERT INTO %s (product_id, currency, price) VALUES ($1, $2, $3)
 		ON CONFLICT (product_id, currency) DO UPDATE SET price = EXCLUDED.price`, pricesTableName)
-	log.Debug().Msg("Query for stmt: " + query)
+	log.Ctx(ctx).Debug().Msg("Query for stmt: " + query)
 
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
-		log.Debug().Msg("Prepare stmt err: " + err.Error())
+		log.Ctx(ctx).Debug().Msg("Prepare stmt err: " + err.Error())
 		return "", errs.HandleErrorDB(err)
 	}
 
@@ -146,14 +256,14 @@ func (r *repo) StoreWithPrices(ctx context.Context, product *entity.Product) (st
 
 	err = tx.Commit()
 	if err != nil {
-		log.Debug().Msg("Commit transaction err: " + err.Error())
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
 		return "", errs.HandleErrorDB(err)
 	}
 
 	return productID, nil
 }
 
-func (r *repo) Update(ctx context.Context, id string, input *entity.ProductUpdateInput) error {
+func (r *repo) Update(ctx context.Context, id string, input *entity.ProductUpdateInput, version int) (int, error) {
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
 	argId := 1
@@ -170,32 +280,96 @@ func (r *repo) Update(ctx context.Context, id string, input *entity.ProductUpdat
 		argId++
 	}
 
-	if input.LeftInStock != nil {
-		setValues = append(setValues, fmt.Sprintf("left_in_stock=$%d", argId))
-		args = append(args, *input.LeftInStock)
+	if input.CategoryID != nil {
+		setValues = append(setValues, fmt.Sprintf("category_id=$%d", argId))
+		if *input.CategoryID == "" {
+			args = append(args, nil)
+		} else {
+			args = append(args, *input.CategoryID)
+		}
 		argId++
 	}
 
+	if len(setValues) == 0 {
+		// nothing to change, but the precondition is still checked
+		current, err := r.getVersion(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if version != 0 && version != current {
+			return 0, errVersionChanged
+		}
+		return current, nil
+	}
+
 	setQuery := strings.Join(setValues, ", ")
-	args = append(args, id)
+	args = append(args, id, version)
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", productTableName, setQuery, argId)
-	log.Debug().Msg("Query: " + query)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d AND ($%d = 0 OR version = $%d) RETURNING version",
+		productTableName, setQuery, argId, argId+1, argId+1)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
-	_, err := r.db.ExecContext(ctx, query, args...)
+	var newVersion int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&newVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, r.versionError(ctx, id)
+	}
 	if err != nil {
-		return errs.HandleErrorDB(err)
+		return 0, errs.HandleErrorDB(err)
 	}
 
-	return nil
+	return newVersion, nil
 }
 
-func (r *repo) Remove(ctx context.Context, id string) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", productTableName)
-	log.Debug().Msg("Query: " + query)
+func (r *repo) Remove(ctx context.Context, id string, version int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND ($2 = 0 OR version = $2)", productTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
-	_, err := r.db.ExecContext(ctx, query, id)
+	res, err := r.db.ExecContext(ctx, query, id, version)
 	if err != nil {
+		// products from the orders history can be only archived
+		return errs.HandleRemoveErrorDB(err, "product is used in orders")
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 && version != 0 {
+		return r.versionError(ctx, id)
+	}
+	return nil
+}
+
+func (r *repo) getVersion(ctx context.Context, id string) (int, error) {
+	query := fmt.Sprintf("SELECT version FROM %s WHERE id = $1", productTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	var version int
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&version); err != nil {
+		return 0, errs.HandleErrorDB(err)
+	}
+	return version, nil
+}
+
+// versionError explains why the versioned query didn't touch the row: it's removed or changed by someone else.
+func (r *repo) versionError(ctx context.Context, id string) error {
+	if _, err := r.getVersion(ctx, id); err != nil {
+		return err
+	}
+	return errVersionChanged
+}
+
+func (r *repo) Archive(ctx context.Context, id string) error {
+	query := fmt.Sprintf("UPDATE %s SET archived_at = COALESCE(archived_at, now()) WHERE id = $1 RETURNING id", productTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&id); err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	return nil
+}
+
+func (r *repo) Restore(ctx context.Context, id string) error {
+	query := fmt.Sprintf("UPDATE %s SET archived_at = NULL WHERE id = $1 RETURNING id", productTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&id); err != nil {
 		return errs.HandleErrorDB(err)
 	}
 	return nil
@@ -204,7 +378,7 @@ func (r *repo) Remove(ctx context.Context, id string) error {
 func (r *repo) AddPrice(ctx context.Context, productID string, price *entity.Price) error {
 	query := fmt.Sprintf(`INSERT INTO %s (product_id, currency, price) VALUES ($1, $2, $3)
 		ON CONFLICT (product_id, currency) DO UPDATE SET price = EXCLUDED.price`, pricesTableName)
-	log.Debug().Msg("Query: " + query)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	_, err := r.db.ExecContext(ctx, query, productID, price.Currency, price.Price)
 	if err != nil {
@@ -212,3 +386,18 @@ func (r *repo) AddPrice(ctx context.Context, productID string, price *entity.Pri
 	}
 	return nil
 }
+
+// storeInitialStock registers the stock of a new product in the inventory ledger.
+func storeInitialStock(ctx context.Context, tx dbtx.Querier, productID string, amount int) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (product_id, kind, quantity, balance, reason) VALUES ($1, $2, $3, $3, $4)`, movementsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	if _, err := tx.ExecContext(ctx, query, productID, entity.StockReceipt, amount, "initial stock"); err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	return nil
+}
@@ -2,22 +2,33 @@ package product
 
 import (
 	"context"
-	"database/sql"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
 )
 
 type Repository interface {
 	Get(ctx context.Context, id string) (*entity.Product, error)
-	GetAll(ctx context.Context) (*[]entity.Product, error)
+	GetAll(ctx context.Context, filter entity.ProductFilter) (*[]entity.Product, error)
 	GetPrices(ctx context.Context, id string) (*[]entity.Price, error)
+	GetCurrencies(ctx context.Context) ([]string, error)
+	// Export calls fn for each not archived product with prices, rows are read from the cursor one by one.
+	Export(ctx context.Context, fn func(product *entity.Product) error) error
 
 	Store(ctx context.Context, product *entity.Product) (string, error)
 	StoreWithPrices(ctx context.Context, product *entity.Product) (string, error)
-	Update(ctx context.Context, id string, input *entity.ProductUpdateInput) error
-	Remove(ctx context.Context, id string) error
+	// Update and Remove check the version of the row if it's not 0, Update returns the new version.
+	Update(ctx context.Context, id string, input *entity.ProductUpdateInput, version int) (int, error)
+	Remove(ctx context.Context, id string, version int) error
+	Archive(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	AddPrice(ctx context.Context, productId string, price *entity.Price) error
 }
 
-func NewRepository(db *sql.DB) Repository {
+func NewRepository(db dbtx.DB) Repository {
 	return newProductPostgresRepository(db)
 }
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newProductMemoryRepository(db)
+}
@@ -0,0 +1,87 @@
+package profile
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"strings"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newProfileMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) GetByUserID(_ context.Context, userID string) (*entity.Profile, error) {
+	var profile entity.Profile
+	err := r.db.Read(func(t *memdb.Tables) error {
+		p, ok := t.Profiles[userID]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		profile = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	profile.FullName = strings.TrimSpace(strings.Join([]string{profile.LastName, profile.FirstName, profile.MiddleName}, " "))
+	return &profile, nil
+}
+
+func (r *memoryRepo) Store(_ context.Context, profile *entity.Profile) (int, error) {
+	var id int
+	err := r.db.Write(func(t *memdb.Tables) error {
+		if err := t.CheckUser(profile.UserID, "user_id"); err != nil {
+			return err
+		}
+		if _, ok := t.Profiles[profile.UserID]; ok {
+			return memdb.ErrExists("user_id")
+		}
+		p := *profile
+		p.FullName = ""
+		p.Version = 1
+		t.Profiles[p.UserID] = p
+		id = int(t.NextSerial())
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *memoryRepo) Update(_ context.Context, profile *entity.Profile) (int, error) {
+	var version int
+	err := r.db.Write(func(t *memdb.Tables) error {
+		p, ok := t.Profiles[profile.UserID]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		if profile.Version != 0 && profile.Version != p.Version {
+			return memdb.ErrVersionChanged("profile is changed")
+		}
+		p.FirstName = profile.FirstName
+		p.LastName = profile.LastName
+		p.MiddleName = profile.MiddleName
+		p.Sex = profile.Sex
+		p.Age = profile.Age
+		p.Version++
+		t.Profiles[p.UserID] = p
+		version = p.Version
+		return nil
+	})
+	return version, err
+}
+
+func (r *memoryRepo) RemoveByUserID(_ context.Context, userID string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		delete(t.Profiles, userID)
+		return nil
+	})
+}
@@ -80,11 +80,12 @@ func (mr *MockRepositoryMockRecorder) Store(ctx, profile interface{}) *gomock.Ca
 }
 
 // Update mocks base method.
-func (m *MockRepository) Update(ctx context.Context, profile *entity.Profile) error {
+func (m *MockRepository) Update(ctx context.Context, profile *entity.Profile) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Update", ctx, profile)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
@@ -6,16 +6,20 @@ import (
 	"fmt"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
 const tableName = "user_profiles"
 
+var errVersionChanged = errs.NewErrorWrapper(errs.PreconditionFailed, errs.VersionChanged, "profile is changed")
+
 type repo struct {
-	db *sql.DB
+	db dbtx.DB
 }
 
-func newProfilePostgresRepository(d *sql.DB) Repository {
+func newProfilePostgresRepository(d dbtx.DB) Repository {
 	return &repo{
 		db: d,
 	}
@@ -23,13 +27,13 @@ func newProfilePostgresRepository(d *sql.DB) Repository {
 
 func (r *repo) GetByUserID(ctx context.Context, userID string) (*entity.Profile, error) {
 	query := fmt.Sprintf(`SELECT user_id, first_name, last_name, middle_name,
-		TRIM(CONCAT_WS(' ', last_name, first_name, middle_name)) AS full_name, sex, age FROM %s WHERE user_id = $1`, tableName)
-	log.Debug().Msg("Query: " + query)
+		TRIM(CONCAT_WS(' ', last_name, first_name, middle_name)) AS full_name, sex, age, version FROM %s WHERE user_id = $1`, tableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	row := r.db.QueryRowContext(ctx, query, userID)
 	profile := entity.Profile{}
 
-	err := row.Scan(&profile.UserID, &profile.FirstName, &profile.LastName, &profile.MiddleName, &profile.FullName, &profile.Sex, &profile.Age)
+	err := row.Scan(&profile.UserID, &profile.FirstName, &profile.LastName, &profile.MiddleName, &profile.FullName, &profile.Sex, &profile.Age, &profile.Version)
 	if err != nil {
 		return nil, errs.HandleErrorDB(err)
 	}
@@ -39,7 +43,7 @@ func (r *repo) GetByUserID(ctx context.Context, userID string) (*entity.Profile,
 func (r *repo) Store(ctx context.Context, profile *entity.Profile) (int, error) {
 	var id int
 	query := fmt.Sprintf(`INSERT INTO %s (user_id, first_name, last_name, middle_name, sex, age) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, tableName)
-	log.Debug().Msg("Query: " + query)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	row := r.db.QueryRowContext(ctx, query, profile.UserID, profile.FirstName, profile.LastName, profile.MiddleName, profile.Sex, profile.Age)
 	if err := row.Scan(&id); err != nil {
@@ -49,21 +53,38 @@ func (r *repo) Store(ctx context.Context, profile *entity.Profile) (int, error)
 	return id, nil
 }
 
-func (r *repo) Update(ctx context.Context, profile *entity.Profile) error {
-	query := fmt.Sprintf(`UPDATE %s SET first_name = $1, last_name = $2, middle_name = $3, sex = $4, age = $5 WHERE user_id = $6`, tableName)
-	log.Debug().Msg("Query: " + query)
+func (r *repo) Update(ctx context.Context, profile *entity.Profile) (int, error) {
+	query := fmt.Sprintf(`UPDATE %s SET first_name = $1, last_name = $2, middle_name = $3, sex = $4, age = $5
+WHERE user_id = $6 AND ($7 = 0 OR version = $7) RETURNING version`, tableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
-	_, err := r.db.ExecContext(ctx, query, profile.FirstName, profile.LastName, profile.MiddleName, profile.Sex, profile.Age, profile.UserID)
+	var version int
+	row := r.db.QueryRowContext(ctx, query, profile.FirstName, profile.LastName, profile.MiddleName, profile.Sex, profile.Age, profile.UserID, profile.Version)
+	err := row.Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, r.versionError(ctx, profile.UserID)
+	}
 	if err != nil {
-		return errs.HandleErrorDB(err)
+		return 0, errs.HandleErrorDB(err)
 	}
 
-	return nil
+	return version, nil
+}
+
+// versionError explains why the versioned query didn't touch the profile: it's removed or changed by someone else.
+func (r *repo) versionError(ctx context.Context, userID string) error {
+	query := fmt.Sprintf(`SELECT user_id FROM %s WHERE user_id = $1`, tableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&userID); err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	return errVersionChanged
 }
 
 func (r *repo) RemoveByUserID(ctx context.Context, userID string) error {
 	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1`, tableName)
-	log.Debug().Msg("Query: " + query)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
 
 	_, err := r.db.ExecContext(ctx, query, userID)
 	if err != nil {
@@ -2,18 +2,24 @@ package profile
 
 import (
 	"context"
-	"database/sql"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
 )
 
 type Repository interface {
 	GetByUserID(ctx context.Context, userID string) (*entity.Profile, error)
 
 	Store(ctx context.Context, profile *entity.Profile) (int, error)
-	Update(ctx context.Context, profile *entity.Profile) error
+	// Update checks profile.Version if it's not 0 and returns the new version.
+	Update(ctx context.Context, profile *entity.Profile) (int, error)
 	RemoveByUserID(ctx context.Context, userID string) error
 }
 
-func NewRepository(db *sql.DB) Repository {
+func NewRepository(db dbtx.DB) Repository {
 	return newProfilePostgresRepository(db)
 }
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newProfileMemoryRepository(db)
+}
@@ -1,25 +1,65 @@
 package repository
 
 import (
-	"database/sql"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/category"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/idempotency"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/image"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/inventory"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/order"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/product"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/profile"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/tag"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/user"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/variant"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
 )
 
 type Repository struct {
-	Orders   order.Repository
-	Products product.Repository
-	Users    user.Repository
-	Profiles profile.Repository
+	Orders      order.Repository
+	Products    product.Repository
+	Users       user.Repository
+	Profiles    profile.Repository
+	Inventory   inventory.Repository
+	Categories  category.Repository
+	Tags        tag.Repository
+	Variants    variant.Repository
+	Images      image.Repository
+	Idempotency idempotency.Repository
+	UnitOfWork  UnitOfWork
 }
 
-func NewRepository(db *sql.DB) Repository {
+// NewRepository returns repositories working on db, for the transaction they are bound to it.
+func NewRepository(db dbtx.DB) Repository {
 	return Repository{
-		Orders:   order.NewRepository(db),
-		Products: product.NewRepository(db),
-		Users:    user.NewRepository(db),
-		Profiles: profile.NewRepository(db),
+		Orders:      order.NewRepository(db),
+		Products:    product.NewRepository(db),
+		Users:       user.NewRepository(db),
+		Profiles:    profile.NewRepository(db),
+		Inventory:   inventory.NewRepository(db),
+		Categories:  category.NewRepository(db),
+		Tags:        tag.NewRepository(db),
+		Variants:    variant.NewRepository(db),
+		Images:      image.NewRepository(db),
+		Idempotency: idempotency.NewRepository(db),
+		UnitOfWork:  NewUnitOfWork(db),
+	}
+}
+
+// NewMemoryRepository returns repositories keeping the data in db instead of the database,
+// to run the API locally and in tests. Uniqueness and references are checked like in the database.
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return Repository{
+		Orders:      order.NewMemoryRepository(db),
+		Products:    product.NewMemoryRepository(db),
+		Users:       user.NewMemoryRepository(db),
+		Profiles:    profile.NewMemoryRepository(db),
+		Inventory:   inventory.NewMemoryRepository(db),
+		Categories:  category.NewMemoryRepository(db),
+		Tags:        tag.NewMemoryRepository(db),
+		Variants:    variant.NewMemoryRepository(db),
+		Images:      image.NewMemoryRepository(db),
+		Idempotency: idempotency.NewMemoryRepository(db),
+		UnitOfWork:  NewMemoryUnitOfWork(db),
 	}
 }
@@ -0,0 +1,249 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testCategories(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	_, err := repos.Categories.Get(ctx, missingID)
+	requireCode(t, err, errs.NotExist)
+	_, err = repos.Categories.GetBySlug(ctx, "missing")
+	requireCode(t, err, errs.NotExist)
+
+	parentID := missingID
+	_, err = repos.Categories.Store(ctx, &entity.Category{ParentID: &parentID, Name: "Pens", Slug: "pens"})
+	requireCode(t, err, errs.BrokenLink)
+
+	officeID, err := repos.Categories.Store(ctx, &entity.Category{Name: "Office", Slug: "office"})
+	require.NoError(t, err)
+	artID, err := repos.Categories.Store(ctx, &entity.Category{Name: "Art", Slug: "art"})
+	require.NoError(t, err)
+	pensID, err := repos.Categories.Store(ctx, &entity.Category{ParentID: &officeID, Name: "Pens", Slug: "pens"})
+	require.NoError(t, err)
+	paperID, err := repos.Categories.Store(ctx, &entity.Category{ParentID: &officeID, Name: "Paper", Slug: "paper"})
+	require.NoError(t, err)
+	inkID, err := repos.Categories.Store(ctx, &entity.Category{ParentID: &pensID, Name: "Ink", Slug: "ink"})
+	require.NoError(t, err)
+
+	_, err = repos.Categories.Store(ctx, &entity.Category{Name: "Pens again", Slug: "pens"})
+	requireCode(t, err, errs.Exist)
+
+	c, err := repos.Categories.GetBySlug(ctx, "pens")
+	require.NoError(t, err)
+	require.Equal(t, entity.Category{ID: pensID, ParentID: &officeID, Name: "Pens", Slug: "pens"}, *c)
+
+	// root categories go first, then subcategories of one parent by name
+	err = repos.Categories.Update(ctx, &entity.Category{ID: inkID, ParentID: &officeID, Name: "Ink", Slug: "ink"})
+	require.NoError(t, err)
+	categories, err := repos.Categories.GetAll(ctx)
+	require.NoError(t, err)
+	names := []string{}
+	for _, c := range *categories {
+		names = append(names, c.Name)
+	}
+	require.Equal(t, []string{"Art", "Office", "Ink", "Paper", "Pens"}, names)
+
+	err = repos.Categories.Update(ctx, &entity.Category{ID: artID, Name: "Art", Slug: "office"})
+	requireCode(t, err, errs.Exist)
+	err = repos.Categories.Update(ctx, &entity.Category{ID: missingID, Name: "Art", Slug: "missing"})
+	requireCode(t, err, errs.NotExist)
+
+	err = repos.Categories.Update(ctx, &entity.Category{ID: inkID, ParentID: &pensID, Name: "Ink", Slug: "ink"})
+	require.NoError(t, err)
+	ids, err := repos.Categories.GetSubtreeIDs(ctx, officeID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{officeID, pensID, paperID, inkID}, ids)
+	ids, err = repos.Categories.GetSubtreeIDs(ctx, missingID)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	// subcategories must be moved or removed first
+	err = repos.Categories.Remove(ctx, pensID)
+	require.True(t, errors.Is(err, errs.RecordInUse))
+
+	// products stay without category
+	productID, err := repos.Products.Store(ctx, &entity.Product{Name: "ink", CategoryID: &inkID})
+	require.NoError(t, err)
+	require.NoError(t, repos.Categories.Remove(ctx, inkID))
+	p, err := repos.Products.Get(ctx, productID)
+	require.NoError(t, err)
+	require.Nil(t, p.CategoryID)
+
+	require.NoError(t, repos.Categories.Remove(ctx, pensID))
+	_, err = repos.Categories.Get(ctx, pensID)
+	requireCode(t, err, errs.NotExist)
+}
+
+func testTags(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	penID := createProduct(t, repos, "pen", 0)
+	inkID := createProduct(t, repos, "ink", 0)
+
+	requireCode(t, repos.Tags.SetForProduct(ctx, missingID, []string{"blue"}), errs.BrokenLink)
+
+	// tags are the part of the product representation
+	penVersion, inkVersion := versionOf(t, repos, penID), versionOf(t, repos, inkID)
+	require.NoError(t, repos.Tags.SetForProduct(ctx, penID, []string{"cheap", "blue", "cheap"}))
+	require.NoError(t, repos.Tags.SetForProduct(ctx, inkID, []string{"blue", "liquid"}))
+	require.Greater(t, versionOf(t, repos, penID), penVersion)
+	inkTagged := versionOf(t, repos, inkID)
+	require.Greater(t, inkTagged, inkVersion)
+
+	tags, err := repos.Tags.GetByProductID(ctx, penID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"blue", "cheap"}, tags)
+
+	tags, err = repos.Tags.GetAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"blue", "cheap", "liquid"}, tags)
+
+	// tags are replaced
+	require.NoError(t, repos.Tags.SetForProduct(ctx, penID, []string{"red"}))
+	require.NoError(t, repos.Tags.SetForProduct(ctx, inkID, nil))
+	require.Greater(t, versionOf(t, repos, inkID), inkTagged)
+	tags, err = repos.Tags.GetAll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"red"}, tags)
+	tags, err = repos.Tags.GetByProductID(ctx, inkID)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+}
+
+func testVariants(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	_, err := repos.Variants.Get(ctx, missingID)
+	requireCode(t, err, errs.NotExist)
+
+	_, err = repos.Variants.StoreWithPrices(ctx, &entity.Variant{ProductID: missingID, SKU: "PEN-RED"})
+	requireCode(t, err, errs.BrokenLink)
+
+	productID := createProduct(t, repos, "pen", 0)
+	// variants with their prices and stock are the part of the product representation
+	version := versionOf(t, repos, productID)
+	requireBumped := func(change string) {
+		t.Helper()
+		v := versionOf(t, repos, productID)
+		require.Greater(t, v, version, change)
+		version = v
+	}
+
+	redID, err := repos.Variants.StoreWithPrices(ctx, &entity.Variant{
+		ProductID:   productID,
+		SKU:         "PEN-RED",
+		Attributes:  map[string]string{"color": "red"},
+		LeftInStock: 2,
+		Prices:      []entity.Price{{Currency: "USD", Price: 2}},
+	})
+	require.NoError(t, err)
+	blueID, err := repos.Variants.StoreWithPrices(ctx, &entity.Variant{ProductID: productID, SKU: "PEN-BLUE"})
+	require.NoError(t, err)
+	requireBumped("create")
+
+	_, err = repos.Variants.StoreWithPrices(ctx, &entity.Variant{ProductID: productID, SKU: "PEN-RED"})
+	requireCode(t, err, errs.Exist)
+	sku := "PEN-RED"
+	err = repos.Variants.Update(ctx, blueID, &entity.VariantUpdateInput{SKU: &sku})
+	requireCode(t, err, errs.Exist)
+
+	v, err := repos.Variants.Get(ctx, redID)
+	require.NoError(t, err)
+	require.Equal(t, entity.Variant{ID: redID, ProductID: productID, SKU: "PEN-RED",
+		Attributes: map[string]string{"color": "red"}, LeftInStock: 2}, *v)
+
+	v, err = repos.Variants.Get(ctx, blueID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{}, v.Attributes)
+
+	attributes := map[string]string{"color": "blue", "ink": "gel"}
+	require.NoError(t, repos.Variants.Update(ctx, blueID, &entity.VariantUpdateInput{Attributes: &attributes}))
+	requireBumped("update")
+	attributes["color"] = "changed"
+	v, err = repos.Variants.Get(ctx, blueID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"color": "blue", "ink": "gel"}, v.Attributes)
+
+	variants, err := repos.Variants.GetAllByProductID(ctx, productID)
+	require.NoError(t, err)
+	require.Len(t, *variants, 2)
+	require.Equal(t, []string{blueID, redID}, []string{(*variants)[0].ID, (*variants)[1].ID})
+
+	require.NoError(t, repos.Variants.AddPrice(ctx, redID, &entity.Price{Currency: "USD", Price: 3}))
+	require.NoError(t, repos.Variants.AddPrice(ctx, redID, &entity.Price{Currency: "EUR", Price: 2}))
+	requireBumped("price")
+	requireCode(t, repos.Variants.AddPrice(ctx, missingID, &entity.Price{Currency: "USD", Price: 1}), errs.BrokenLink)
+	prices, err := repos.Variants.GetPrices(ctx, redID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []entity.Price{{Currency: "USD", Price: 3}, {Currency: "EUR", Price: 2}}, *prices)
+
+	// stock of variant is apart from the stock of product
+	userID := createUser(t, repos, "alice")
+	orderID := createOrder(t, repos, userID, 1)
+	err = repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: productID, VariantID: &redID, Amount: 3})
+	require.True(t, errors.Is(err, errs.LogicalError))
+	require.NoError(t, repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: productID, VariantID: &redID, Amount: 2}))
+	requireBumped("stock")
+	v, err = repos.Variants.Get(ctx, redID)
+	require.NoError(t, err)
+	require.Equal(t, 0, v.LeftInStock)
+	require.Equal(t, 0, stockOf(t, repos, productID))
+
+	// variant of another product
+	otherID := createProduct(t, repos, "ink", 0)
+	err = repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: otherID, VariantID: &blueID, Amount: 1})
+	requireCode(t, err, errs.NotExist)
+
+	err = repos.Variants.Remove(ctx, redID)
+	require.True(t, errors.Is(err, errs.RecordInUse))
+
+	require.NoError(t, repos.Variants.Remove(ctx, blueID))
+	requireBumped("remove")
+	_, err = repos.Variants.Get(ctx, blueID)
+	requireCode(t, err, errs.NotExist)
+	require.NoError(t, repos.Variants.Remove(ctx, blueID))
+}
+
+func testImages(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	_, err := repos.Images.Store(ctx, &entity.ProductImage{ProductID: missingID, Key: "a.jpg", ThumbnailKey: "a_t.jpg", ContentType: "image/jpeg"})
+	requireCode(t, err, errs.BrokenLink)
+
+	productID := createProduct(t, repos, "pen", 0)
+	p, err := repos.Products.Get(ctx, productID)
+	require.NoError(t, err)
+
+	first := entity.ProductImage{ProductID: productID, Key: "a.jpg", ThumbnailKey: "a_t.jpg", ContentType: "image/jpeg", Size: 10}
+	id, err := repos.Images.Store(ctx, &first)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, id)
+	require.False(t, first.CreatedAt.IsZero())
+
+	second := entity.ProductImage{ProductID: productID, Key: "b.png", ThumbnailKey: "b_t.png", ContentType: "image/png", Size: 20}
+	_, err = repos.Images.Store(ctx, &second)
+	require.NoError(t, err)
+
+	_, err = repos.Images.Store(ctx, &entity.ProductImage{ProductID: productID, Key: "a.jpg", ThumbnailKey: "c_t.jpg", ContentType: "image/jpeg"})
+	requireCode(t, err, errs.Exist)
+
+	images, err := repos.Images.GetAllByProductID(ctx, productID)
+	require.NoError(t, err)
+	require.Len(t, *images, 2)
+	require.Equal(t, []string{first.ID, second.ID}, []string{(*images)[0].ID, (*images)[1].ID})
+	require.Equal(t, "b.png", (*images)[1].Key)
+	require.Equal(t, int64(20), (*images)[1].Size)
+
+	// images are the part of the product representation
+	updated, err := repos.Products.Get(ctx, productID)
+	require.NoError(t, err)
+	require.Equal(t, p.Version+2, updated.Version)
+}
@@ -0,0 +1,108 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testIdempotency(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+	userID := createUser(t, repos, "alice")
+	hash := strings.Repeat("a", 64)
+
+	record := entity.IdempotencyRecord{UserID: userID, Key: "k1", RequestHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+	_, created, err := repos.Idempotency.Reserve(ctx, &record)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.False(t, record.CreatedAt.IsZero())
+
+	other := entity.IdempotencyRecord{UserID: userID, Key: "k1", RequestHash: strings.Repeat("b", 64), ExpiresAt: time.Now().Add(time.Hour)}
+	existing, created, err := repos.Idempotency.Reserve(ctx, &other)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, hash, existing.RequestHash)
+	require.False(t, existing.IsCompleted())
+
+	// the response is stored once the request is done, completed record is not released
+	record.StatusCode = 201
+	record.ContentType = "application/json"
+	record.ResponseBody = []byte(`{"id":"1"}`)
+	require.NoError(t, repos.Idempotency.Complete(ctx, &record))
+	require.NoError(t, repos.Idempotency.Release(ctx, userID, "k1"))
+
+	existing, created, err = repos.Idempotency.Reserve(ctx, &other)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, 201, existing.StatusCode)
+	require.Equal(t, "application/json", existing.ContentType)
+	require.Equal(t, []byte(`{"id":"1"}`), existing.ResponseBody)
+
+	// incomplete record is released for the retry
+	second := entity.IdempotencyRecord{UserID: userID, Key: "k2", RequestHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+	_, created, err = repos.Idempotency.Reserve(ctx, &second)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NoError(t, repos.Idempotency.Release(ctx, userID, "k2"))
+	_, created, err = repos.Idempotency.Reserve(ctx, &second)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// expired record is taken over by the new request
+	expired := entity.IdempotencyRecord{UserID: userID, Key: "k3", RequestHash: hash, ExpiresAt: time.Now().Add(-time.Minute)}
+	_, created, err = repos.Idempotency.Reserve(ctx, &expired)
+	require.NoError(t, err)
+	require.True(t, created)
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	_, created, err = repos.Idempotency.Reserve(ctx, &expired)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	n, err := repos.Idempotency.RemoveExpired(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	_, _, err = repos.Idempotency.Reserve(ctx, &entity.IdempotencyRecord{UserID: missingID, Key: "k1", RequestHash: hash, ExpiresAt: time.Now().Add(time.Hour)})
+	requireCode(t, err, errs.BrokenLink)
+}
+
+func testUnitOfWork(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+	fnErr := errors.New("fn error")
+
+	var userID string
+	err := repos.UnitOfWork.Do(ctx, func(repos repository.Repository) error {
+		userID = createUser(t, repos, "alice")
+		createProduct(t, repos, "pen", 1)
+		return fnErr
+	})
+	require.ErrorIs(t, err, fnErr)
+	_, err = repos.Users.Get(ctx, userID)
+	requireCode(t, err, errs.NotExist)
+	products, err := repos.Products.GetAll(ctx, entity.ProductFilter{})
+	require.NoError(t, err)
+	require.Empty(t, *products)
+
+	err = repos.UnitOfWork.Do(ctx, func(repos repository.Repository) error {
+		userID = createUser(t, repos, "alice")
+
+		// failed nested unit of work doesn't break the outer one
+		err := repos.UnitOfWork.Do(ctx, func(repos repository.Repository) error {
+			createUser(t, repos, "bob")
+			return fnErr
+		})
+		require.ErrorIs(t, err, fnErr)
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = repos.Users.Get(ctx, userID)
+	require.NoError(t, err)
+	_, err = repos.Users.GetByUsername(ctx, "bob")
+	requireCode(t, err, errs.NotExist)
+}
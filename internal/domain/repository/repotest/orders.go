@@ -0,0 +1,129 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testOrders(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	_, err := repos.Orders.Get(ctx, missingID)
+	requireCode(t, err, errs.NotExist)
+
+	_, _, err = repos.Orders.Store(ctx, &entity.Order{UserID: missingID, Number: 1})
+	requireCode(t, err, errs.BrokenLink)
+
+	userID := createUser(t, repos, "alice")
+	id, created, err := repos.Orders.Store(ctx, &entity.Order{UserID: userID, Number: 1})
+	require.NoError(t, err)
+	require.True(t, created)
+	// idempotent
+	sameID, created, err := repos.Orders.Store(ctx, &entity.Order{UserID: userID, Number: 1})
+	require.NoError(t, err)
+	require.Equal(t, id, sameID)
+	require.False(t, created)
+	secondID := createOrder(t, repos, userID, 2)
+
+	o, err := repos.Orders.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, entity.Order{ID: id, UserID: userID, Number: 1, Version: 1}, *o)
+
+	orders, err := repos.Orders.GetAllByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{id, secondID}, orderIDs(*orders))
+
+	orders, err = repos.Orders.GetAllByUserID(ctx, missingID)
+	require.NoError(t, err)
+	require.Empty(t, *orders)
+
+	// number is unique for the user
+	_, err = repos.Orders.Update(ctx, &entity.Order{ID: secondID, UserID: userID, Number: 1})
+	requireCode(t, err, errs.Exist)
+
+	version, err := repos.Orders.Update(ctx, &entity.Order{ID: secondID, UserID: userID, Number: 3, Version: 1})
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+	_, err = repos.Orders.Update(ctx, &entity.Order{ID: secondID, UserID: userID, Number: 4, Version: 1})
+	requireCode(t, err, errs.PreconditionFailed)
+	_, err = repos.Orders.Update(ctx, &entity.Order{ID: missingID, UserID: userID, Number: 5})
+	requireCode(t, err, errs.NotExist)
+
+	err = repos.Orders.Remove(ctx, secondID, 1)
+	requireCode(t, err, errs.PreconditionFailed)
+	require.NoError(t, repos.Orders.Remove(ctx, secondID, 2))
+	_, err = repos.Orders.Get(ctx, secondID)
+	requireCode(t, err, errs.NotExist)
+
+	err = repos.Orders.Remove(ctx, secondID, 2)
+	requireCode(t, err, errs.NotExist)
+	// without version the missing order is not an error
+	require.NoError(t, repos.Orders.Remove(ctx, secondID, 0))
+}
+
+func testOrderProducts(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	userID := createUser(t, repos, "alice")
+	orderID := createOrder(t, repos, userID, 1)
+	productID := createProduct(t, repos, "pen", 5)
+
+	err := repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: missingID, Amount: 1})
+	requireCode(t, err, errs.NotExist)
+
+	err = repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: productID, Amount: 6})
+	require.True(t, errors.Is(err, errs.LogicalError))
+	require.Equal(t, 5, stockOf(t, repos, productID))
+
+	// amount of the same product is merged
+	require.NoError(t, repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: productID, Amount: 2}))
+	require.NoError(t, repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: productID, Amount: 1}))
+	require.Equal(t, 2, stockOf(t, repos, productID))
+
+	lines, err := repos.Orders.GetProducts(ctx, orderID)
+	require.NoError(t, err)
+	require.Equal(t, []entity.OrderProductView{{ID: productID, Amount: 3}}, *lines)
+
+	// archived product is not available for ordering
+	archivedID := createProduct(t, repos, "ink", 5)
+	require.NoError(t, repos.Products.Archive(ctx, archivedID))
+	err = repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: archivedID, Amount: 1})
+	require.True(t, errors.Is(err, errs.LogicalError))
+
+	// removing of the missing line is not an error
+	require.NoError(t, repos.Orders.RemoveProduct(ctx, orderID, archivedID, nil))
+
+	require.NoError(t, repos.Orders.RemoveProduct(ctx, orderID, productID, nil))
+	require.Equal(t, 5, stockOf(t, repos, productID))
+	lines, err = repos.Orders.GetProducts(ctx, orderID)
+	require.NoError(t, err)
+	require.Empty(t, *lines)
+
+	// removed order returns reserved products to stock
+	require.NoError(t, repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: productID, Amount: 4}))
+	require.Equal(t, 1, stockOf(t, repos, productID))
+	require.NoError(t, repos.Orders.Remove(ctx, orderID, 0))
+	require.Equal(t, 5, stockOf(t, repos, productID))
+
+	movements, err := repos.Inventory.GetMovements(ctx, productID)
+	require.NoError(t, err)
+	kinds := []string{}
+	for _, m := range *movements {
+		kinds = append(kinds, m.Kind)
+	}
+	require.Equal(t, []string{entity.StockReceipt, entity.StockReservation, entity.StockReservation,
+		entity.StockRelease, entity.StockReservation, entity.StockRelease}, kinds)
+}
+
+func orderIDs(orders []entity.Order) []string {
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
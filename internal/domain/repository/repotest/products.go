@@ -0,0 +1,233 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testProducts(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	_, err := repos.Products.Get(ctx, missingID)
+	requireCode(t, err, errs.NotExist)
+
+	categoryID := missingID
+	_, err = repos.Products.Store(ctx, &entity.Product{Name: "pen", CategoryID: &categoryID})
+	requireCode(t, err, errs.BrokenLink)
+
+	id := createProduct(t, repos, "pen", 3, entity.Price{Currency: "USD", Price: 2}, entity.Price{Currency: "EUR", Price: 1.5})
+	p, err := repos.Products.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, entity.Product{ID: id, Name: "pen", LeftInStock: 3, Version: p.Version}, *p)
+	v := p.Version
+
+	prices, err := repos.Products.GetPrices(ctx, id)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []entity.Price{{Currency: "USD", Price: 2}, {Currency: "EUR", Price: 1.5}}, *prices)
+
+	// price is replaced, the representation of product is changed
+	require.NoError(t, repos.Products.AddPrice(ctx, id, &entity.Price{Currency: "USD", Price: 3}))
+	require.NoError(t, repos.Products.AddPrice(ctx, id, &entity.Price{Currency: "GBP", Price: 1}))
+	requireCode(t, repos.Products.AddPrice(ctx, missingID, &entity.Price{Currency: "USD", Price: 1}), errs.BrokenLink)
+	prices, err = repos.Products.GetPrices(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, *prices, 3)
+	p, err = repos.Products.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, v+2, p.Version)
+
+	currencies, err := repos.Products.GetCurrencies(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"EUR", "GBP", "USD"}, currencies)
+
+	name := "pencil"
+	version, err := repos.Products.Update(ctx, id, &entity.ProductUpdateInput{Name: &name}, v+2)
+	require.NoError(t, err)
+	require.Equal(t, v+3, version)
+	_, err = repos.Products.Update(ctx, id, &entity.ProductUpdateInput{Name: &name}, v+2)
+	requireCode(t, err, errs.PreconditionFailed)
+	// nothing to change, but the version is checked
+	_, err = repos.Products.Update(ctx, id, &entity.ProductUpdateInput{}, v+2)
+	requireCode(t, err, errs.PreconditionFailed)
+	version, err = repos.Products.Update(ctx, id, &entity.ProductUpdateInput{}, 0)
+	require.NoError(t, err)
+	require.Equal(t, v+3, version)
+	_, err = repos.Products.Update(ctx, missingID, &entity.ProductUpdateInput{Name: &name}, 0)
+	requireCode(t, err, errs.NotExist)
+
+	require.NoError(t, repos.Products.Archive(ctx, id))
+	p, err = repos.Products.Get(ctx, id)
+	require.NoError(t, err)
+	require.True(t, p.IsArchived())
+	require.NoError(t, repos.Products.Restore(ctx, id))
+	p, err = repos.Products.Get(ctx, id)
+	require.NoError(t, err)
+	require.False(t, p.IsArchived())
+	requireCode(t, repos.Products.Archive(ctx, missingID), errs.NotExist)
+}
+
+func testProductRemove(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	id := createProduct(t, repos, "pen", 3, entity.Price{Currency: "USD", Price: 2})
+	require.NoError(t, repos.Tags.SetForProduct(ctx, id, []string{"office"}))
+	_, err := repos.Variants.StoreWithPrices(ctx, &entity.Variant{ProductID: id, SKU: "PEN-RED", LeftInStock: 1})
+	require.NoError(t, err)
+
+	// products from the orders history can be only archived
+	userID := createUser(t, repos, "alice")
+	orderID := createOrder(t, repos, userID, 1)
+	require.NoError(t, repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: id, Amount: 1}))
+	err = repos.Products.Remove(ctx, id, 0)
+	require.True(t, errors.Is(err, errs.RecordInUse))
+	require.NoError(t, repos.Orders.RemoveProduct(ctx, orderID, id, nil))
+
+	p, err := repos.Products.Get(ctx, id)
+	require.NoError(t, err)
+	err = repos.Products.Remove(ctx, id, p.Version-1)
+	requireCode(t, err, errs.PreconditionFailed)
+	require.NoError(t, repos.Products.Remove(ctx, id, p.Version))
+	_, err = repos.Products.Get(ctx, id)
+	requireCode(t, err, errs.NotExist)
+	requireCode(t, repos.Products.Remove(ctx, id, 1), errs.NotExist)
+	require.NoError(t, repos.Products.Remove(ctx, id, 0))
+
+	// everything of the product is removed with it
+	prices, err := repos.Products.GetPrices(ctx, id)
+	require.NoError(t, err)
+	require.Empty(t, *prices)
+	tags, err := repos.Tags.GetAll(ctx)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+	variants, err := repos.Variants.GetAllByProductID(ctx, id)
+	require.NoError(t, err)
+	require.Empty(t, *variants)
+	movements, err := repos.Inventory.GetMovements(ctx, id)
+	require.NoError(t, err)
+	require.Empty(t, *movements)
+}
+
+func testProductListing(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	rootID, err := repos.Categories.Store(ctx, &entity.Category{Name: "Office", Slug: "office"})
+	require.NoError(t, err)
+	childID, err := repos.Categories.Store(ctx, &entity.Category{ParentID: &rootID, Name: "Pens", Slug: "pens"})
+	require.NoError(t, err)
+
+	penID, err := repos.Products.Store(ctx, &entity.Product{Name: "pen", LeftInStock: 1, CategoryID: &childID})
+	require.NoError(t, err)
+	paperID, err := repos.Products.Store(ctx, &entity.Product{Name: "paper", CategoryID: &rootID})
+	require.NoError(t, err)
+	inkID := createProduct(t, repos, "ink", 0, entity.Price{Currency: "USD", Price: 5}, entity.Price{Currency: "EUR", Price: 4})
+	archivedID := createProduct(t, repos, "old", 0)
+	require.NoError(t, repos.Products.Archive(ctx, archivedID))
+	require.NoError(t, repos.Tags.SetForProduct(ctx, penID, []string{"blue", "cheap"}))
+	require.NoError(t, repos.Tags.SetForProduct(ctx, inkID, []string{"blue"}))
+
+	products, err := repos.Products.GetAll(ctx, entity.ProductFilter{})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{penID, paperID, inkID}, productIDs(*products))
+
+	products, err = repos.Products.GetAll(ctx, entity.ProductFilter{CategoryIDs: []string{rootID, childID}})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{penID, paperID}, productIDs(*products))
+
+	products, err = repos.Products.GetAll(ctx, entity.ProductFilter{CategoryIDs: []string{childID}, Tag: "blue"})
+	require.NoError(t, err)
+	require.Equal(t, []string{penID}, productIDs(*products))
+
+	// tags are stored lowercased
+	products, err = repos.Products.GetAll(ctx, entity.ProductFilter{Tag: " Blue"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{penID, inkID}, productIDs(*products))
+
+	products, err = repos.Products.GetAll(ctx, entity.ProductFilter{Tag: "unknown"})
+	require.NoError(t, err)
+	require.Empty(t, *products)
+
+	// export is ordered by ID, prices by currency
+	exported := []entity.Product{}
+	err = repos.Products.Export(ctx, func(p *entity.Product) error {
+		exported = append(exported, *p)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, exported, 3)
+	for i := 1; i < len(exported); i++ {
+		require.Less(t, exported[i-1].ID, exported[i].ID)
+	}
+	for _, p := range exported {
+		if p.ID == inkID {
+			require.Equal(t, []entity.Price{{Currency: "EUR", Price: 4}, {Currency: "USD", Price: 5}}, p.Prices)
+		} else {
+			require.Empty(t, p.Prices)
+		}
+	}
+
+	stopErr := errors.New("stop")
+	calls := 0
+	err = repos.Products.Export(ctx, func(p *entity.Product) error {
+		calls++
+		return stopErr
+	})
+	require.ErrorIs(t, err, stopErr)
+	require.Equal(t, 1, calls)
+}
+
+func testInventory(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	movements, err := repos.Inventory.GetMovements(ctx, missingID)
+	require.NoError(t, err)
+	require.Empty(t, *movements)
+
+	_, err = repos.Inventory.AddMovement(ctx, &entity.StockMovement{ProductID: missingID, Kind: entity.StockReceipt, Quantity: 1})
+	requireCode(t, err, errs.NotExist)
+
+	userID := createUser(t, repos, "alice")
+	productID := createProduct(t, repos, "pen", 2)
+
+	m := entity.StockMovement{ProductID: productID, Kind: entity.StockAdjustment, Quantity: -3, ActorUserID: &userID}
+	_, err = repos.Inventory.AddMovement(ctx, &m)
+	require.True(t, errors.Is(err, errs.LogicalError))
+
+	m.Quantity = -2
+	id, err := repos.Inventory.AddMovement(ctx, &m)
+	require.NoError(t, err)
+	require.Equal(t, m.ID, id)
+	require.Equal(t, 0, m.Balance)
+	require.Equal(t, 0, stockOf(t, repos, productID))
+
+	m = entity.StockMovement{ProductID: productID, Kind: entity.StockReceipt, Quantity: 5, Reason: "supply"}
+	_, err = repos.Inventory.AddMovement(ctx, &m)
+	require.NoError(t, err)
+	require.Equal(t, 5, m.Balance)
+
+	movements, err = repos.Inventory.GetMovements(ctx, productID)
+	require.NoError(t, err)
+	require.Len(t, *movements, 3)
+	balances := []int{}
+	for i, m := range *movements {
+		if i > 0 {
+			require.Less(t, (*movements)[i-1].ID, m.ID)
+		}
+		balances = append(balances, m.Balance)
+	}
+	require.Equal(t, []int{2, 0, 5}, balances)
+	require.Equal(t, userID, *(*movements)[1].ActorUserID)
+	require.Equal(t, "supply", (*movements)[2].Reason)
+}
+
+func productIDs(products []entity.Product) []string {
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
@@ -0,0 +1,92 @@
+// Package repotest is the contract of repositories: every implementation (postgres, in memory)
+// runs the same tests, so the use cases can't tell one from another.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// missingID - valid UUID of no record.
+const missingID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+
+// Factory returns repositories with no data, it's called for each test.
+type Factory func(t *testing.T) repository.Repository
+
+// Run runs the contract tests of all repositories. Tests are not parallel, so the factory may reuse one database.
+func Run(t *testing.T, newRepos Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repos repository.Repository)
+	}{
+		{"Users", testUsers},
+		{"UserRemoveCascade", testUserRemoveCascade},
+		{"Profiles", testProfiles},
+		{"Orders", testOrders},
+		{"OrderProducts", testOrderProducts},
+		{"Products", testProducts},
+		{"ProductRemove", testProductRemove},
+		{"ProductListing", testProductListing},
+		{"Inventory", testInventory},
+		{"Categories", testCategories},
+		{"Tags", testTags},
+		{"Variants", testVariants},
+		{"Images", testImages},
+		{"Idempotency", testIdempotency},
+		{"UnitOfWork", testUnitOfWork},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, newRepos(t))
+		})
+	}
+}
+
+// requireCode checks the code of the innermost error wrapper, it's mapped to the HTTP status.
+func requireCode(t *testing.T, err error, code int) {
+	t.Helper()
+	var ew errs.CustomErrorWrapper
+	require.True(t, errors.As(err, &ew), "error %v is not wrapped", err)
+	require.Equal(t, code, ew.Dig().Code, "unexpected code of error %v", err)
+}
+
+func createUser(t *testing.T, repos repository.Repository, username string) string {
+	t.Helper()
+	id, err := repos.Users.Store(context.Background(), &entity.User{Username: username, PasswordHash: "hash"})
+	require.NoError(t, err)
+	return id
+}
+
+func createProduct(t *testing.T, repos repository.Repository, name string, stock int, prices ...entity.Price) string {
+	t.Helper()
+	id, err := repos.Products.StoreWithPrices(context.Background(), &entity.Product{Name: name, LeftInStock: stock, Prices: prices})
+	require.NoError(t, err)
+	return id
+}
+
+func createOrder(t *testing.T, repos repository.Repository, userID string, number int) string {
+	t.Helper()
+	id, _, err := repos.Orders.Store(context.Background(), &entity.Order{UserID: userID, Number: number})
+	require.NoError(t, err)
+	return id
+}
+
+func stockOf(t *testing.T, repos repository.Repository, productID string) int {
+	t.Helper()
+	p, err := repos.Products.Get(context.Background(), productID)
+	require.NoError(t, err)
+	return p.LeftInStock
+}
+
+func versionOf(t *testing.T, repos repository.Repository, productID string) int {
+	t.Helper()
+	p, err := repos.Products.Get(context.Background(), productID)
+	require.NoError(t, err)
+	return p.Version
+}
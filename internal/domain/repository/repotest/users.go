@@ -0,0 +1,132 @@
+package repotest
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testUsers(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	_, err := repos.Users.Get(ctx, missingID)
+	requireCode(t, err, errs.NotExist)
+	_, err = repos.Users.GetByUsername(ctx, "nobody")
+	requireCode(t, err, errs.NotExist)
+
+	id := createUser(t, repos, "alice")
+	u, err := repos.Users.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, entity.User{ID: id, Username: "alice", PasswordHash: "hash"}, *u)
+
+	u, err = repos.Users.GetByUsername(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, id, u.ID)
+
+	_, err = repos.Users.Store(ctx, &entity.User{Username: "alice", PasswordHash: "other"})
+	requireCode(t, err, errs.Exist)
+
+	bobID := createUser(t, repos, "bob")
+	err = repos.Users.Update(ctx, &entity.User{ID: bobID, Username: "alice", PasswordHash: "hash"})
+	requireCode(t, err, errs.Exist)
+
+	err = repos.Users.Update(ctx, &entity.User{ID: bobID, Username: "robert", PasswordHash: "new"})
+	require.NoError(t, err)
+	u, err = repos.Users.Get(ctx, bobID)
+	require.NoError(t, err)
+	require.Equal(t, "robert", u.Username)
+	require.Equal(t, "new", u.PasswordHash)
+
+	// idempotent
+	require.NoError(t, repos.Users.AddFollower(ctx, id, bobID))
+	require.NoError(t, repos.Users.AddFollower(ctx, id, bobID))
+	requireCode(t, repos.Users.AddFollower(ctx, id, missingID), errs.BrokenLink)
+}
+
+func testUserRemoveCascade(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	userID := createUser(t, repos, "alice")
+	followerID := createUser(t, repos, "bob")
+	require.NoError(t, repos.Users.AddFollower(ctx, userID, followerID))
+	_, err := repos.Profiles.Store(ctx, &entity.Profile{UserID: userID, FirstName: "Alice", LastName: "Smith", Sex: "w", Age: 30})
+	require.NoError(t, err)
+
+	productID := createProduct(t, repos, "pen", 5)
+	orderID := createOrder(t, repos, userID, 1)
+	require.NoError(t, repos.Orders.AddProduct(ctx, &entity.OrderProduct{OrderID: orderID, ProductID: productID, Amount: 2}))
+
+	require.NoError(t, repos.Users.Remove(ctx, userID))
+	// removing of the missing user is not an error
+	require.NoError(t, repos.Users.Remove(ctx, userID))
+
+	_, err = repos.Users.Get(ctx, userID)
+	requireCode(t, err, errs.NotExist)
+	_, err = repos.Profiles.GetByUserID(ctx, userID)
+	requireCode(t, err, errs.NotExist)
+	_, err = repos.Orders.Get(ctx, orderID)
+	requireCode(t, err, errs.NotExist)
+
+	_, err = repos.Users.Get(ctx, followerID)
+	require.NoError(t, err)
+
+	// the ledger keeps movements of the removed order without references
+	movements, err := repos.Inventory.GetMovements(ctx, productID)
+	require.NoError(t, err)
+	require.Len(t, *movements, 2)
+	for _, m := range *movements {
+		require.Nil(t, m.ActorUserID)
+		require.Nil(t, m.OrderID)
+	}
+	// and the ordered product can be removed now
+	require.NoError(t, repos.Products.Remove(ctx, productID, 0))
+}
+
+func testProfiles(t *testing.T, repos repository.Repository) {
+	ctx := context.Background()
+
+	_, err := repos.Profiles.GetByUserID(ctx, missingID)
+	requireCode(t, err, errs.NotExist)
+
+	_, err = repos.Profiles.Store(ctx, &entity.Profile{UserID: missingID, FirstName: "A", LastName: "B", Sex: "m", Age: 1})
+	requireCode(t, err, errs.BrokenLink)
+
+	userID := createUser(t, repos, "alice")
+	profile := entity.Profile{UserID: userID, FirstName: "Alice", LastName: "Smith", Sex: "w", Age: 30}
+	// one profile per user is checked by the use case, the table has no unique key for it
+	_, err = repos.Profiles.Store(ctx, &profile)
+	require.NoError(t, err)
+
+	p, err := repos.Profiles.GetByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, "Smith Alice", p.FullName)
+	require.Equal(t, 1, p.Version)
+
+	p.MiddleName = "Jane"
+	version, err := repos.Profiles.Update(ctx, p)
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+
+	// p.Version is outdated
+	_, err = repos.Profiles.Update(ctx, p)
+	requireCode(t, err, errs.PreconditionFailed)
+
+	p.Version = 0
+	version, err = repos.Profiles.Update(ctx, p)
+	require.NoError(t, err)
+	require.Equal(t, 3, version)
+
+	p, err = repos.Profiles.GetByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, "Smith Alice Jane", p.FullName)
+
+	_, err = repos.Profiles.Update(ctx, &entity.Profile{UserID: missingID, Version: 1})
+	requireCode(t, err, errs.NotExist)
+
+	require.NoError(t, repos.Profiles.RemoveByUserID(ctx, userID))
+	_, err = repos.Profiles.GetByUserID(ctx, userID)
+	requireCode(t, err, errs.NotExist)
+}
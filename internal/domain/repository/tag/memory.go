@@ -0,0 +1,72 @@
+package tag
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"sort"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newTagMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) GetByProductID(_ context.Context, productID string) ([]string, error) {
+	set := map[string]struct{}{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for tag := range t.ProductTags[productID] {
+			set[tag] = struct{}{}
+		}
+		return nil
+	})
+	return sortedTags(set), nil
+}
+
+func (r *memoryRepo) GetAll(_ context.Context) ([]string, error) {
+	set := map[string]struct{}{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for _, tags := range t.ProductTags {
+			for tag := range tags {
+				set[tag] = struct{}{}
+			}
+		}
+		return nil
+	})
+	return sortedTags(set), nil
+}
+
+func (r *memoryRepo) SetForProduct(_ context.Context, productID string, tags []string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		if len(tags) == 0 {
+			if _, ok := t.ProductTags[productID]; ok {
+				delete(t.ProductTags, productID)
+				t.TouchProduct(productID)
+			}
+			return nil
+		}
+		if err := t.CheckProduct(productID); err != nil {
+			return err
+		}
+		set := make(map[string]struct{}, len(tags))
+		for _, tag := range tags {
+			set[tag] = struct{}{}
+		}
+		t.ProductTags[productID] = set
+		t.TouchProduct(productID)
+		return nil
+	})
+}
+
+func sortedTags(set map[string]struct{}) []string {
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,99 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const tagsTableName = "product_tags"
+
+type repo struct {
+	db dbtx.DB
+}
+
+func newTagPostgresRepository(d dbtx.DB) Repository {
+	return &repo{
+		db: d,
+	}
+}
+
+func (r *repo) GetByProductID(ctx context.Context, productID string) ([]string, error) {
+	query := fmt.Sprintf("SELECT tag FROM %s WHERE product_id = $1 ORDER BY tag", tagsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	return r.queryTags(ctx, query, productID)
+}
+
+func (r *repo) GetAll(ctx context.Context) ([]string, error) {
+	query := fmt.Sprintf("SELECT DISTINCT tag FROM %s ORDER BY tag", tagsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	return r.queryTags(ctx, query)
+}
+
+func (r *repo) queryTags(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errs.HandleErrorDB(err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+		}
+	}(rows)
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (r *repo) SetForProduct(ctx context.Context, productID string, tags []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
+		return errs.HandleErrorDB(err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE product_id = $1", tagsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	if _, err = tx.ExecContext(ctx, query, productID); err != nil {
+		return errs.HandleErrorDB(err)
+	}
+
+	query = fmt.Sprintf("INSERT INTO %s (product_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING", tagsTableName)
+	log.Ctx(ctx).Debug().Msg("Query for stmt: " + query)
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Prepare stmt err: " + err.Error())
+		return errs.HandleErrorDB(err)
+	}
+
+	for _, tag := range tags {
+		if _, err = stmt.ExecContext(ctx, productID, tag); err != nil {
+			return errs.HandleErrorDB(err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
+		return errs.HandleErrorDB(err)
+	}
+
+	return nil
+}
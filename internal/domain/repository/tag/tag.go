@@ -0,0 +1,24 @@
+package tag
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+)
+
+type Repository interface {
+	GetByProductID(ctx context.Context, productID string) ([]string, error)
+	// GetAll returns all tags used by products.
+	GetAll(ctx context.Context) ([]string, error)
+
+	// SetForProduct replaces tags of the product.
+	SetForProduct(ctx context.Context, productID string, tags []string) error
+}
+
+func NewRepository(db dbtx.DB) Repository {
+	return newTagPostgresRepository(db)
+}
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newTagMemoryRepository(db)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const (
+	uowMaxAttempts = 3
+	uowRetryDelay  = 20 * time.Millisecond
+)
+
+// UnitOfWork runs fn with the repositories bound to one transaction: it's committed if fn returns nil
+// and rolled back otherwise. The error of fn is returned as is.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repository) error) error
+}
+
+type unitOfWork struct {
+	db dbtx.DB
+}
+
+// NewUnitOfWork - the unit of work inside the transaction becomes the savepoint of it.
+func NewUnitOfWork(db dbtx.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+// Do repeats the whole transaction on serialization failures and deadlocks, so fn must not keep
+// the state between the attempts. The nested unit of work is not repeated, the outer one is.
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos Repository) error) error {
+	attempts := uowMaxAttempts
+	if dbtx.IsTx(u.db) {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = u.do(ctx, fn)
+		if err == nil || attempt >= attempts || !isRetryable(err) {
+			return err
+		}
+		log.Ctx(ctx).Debug().Msgf("Transaction attempt %d failed, retry: %s", attempt, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * uowRetryDelay):
+		}
+	}
+}
+
+func (u *unitOfWork) do(ctx context.Context, fn func(repos Repository) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
+		return errs.HandleErrorDB(err)
+	}
+	defer tx.Rollback()
+
+	if err = fn(NewRepository(tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
+		return errs.HandleErrorDB(err)
+	}
+	return nil
+}
+
+type memoryUnitOfWork struct {
+	db *memdb.DB
+}
+
+// NewMemoryUnitOfWork - the transaction of in-memory repositories is serializable, so it's never repeated.
+func NewMemoryUnitOfWork(db *memdb.DB) UnitOfWork {
+	return &memoryUnitOfWork{db: db}
+}
+
+func (u *memoryUnitOfWork) Do(_ context.Context, fn func(repos Repository) error) error {
+	return u.db.Tx(func(tx *memdb.DB) error {
+		return fn(NewMemoryRepository(tx))
+	})
+}
+
+// isRetryable - serialization_failure and deadlock_detected leave no changes, so the transaction can be repeated.
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnitOfWorkRetrySerializationFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	calls := 0
+	err = NewUnitOfWork(dbtx.New(db)).Do(context.Background(), func(repos Repository) error {
+		calls++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnitOfWorkRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	fnErr := errors.New("fn error")
+	err = NewUnitOfWork(dbtx.New(db)).Do(context.Background(), func(repos Repository) error {
+		return fnErr
+	})
+	require.ErrorIs(t, err, fnErr)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnitOfWorkNested(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	nestedErr := errors.New("nested error")
+	err = NewUnitOfWork(dbtx.New(db)).Do(context.Background(), func(repos Repository) error {
+		// failed nested unit of work is not repeated and doesn't break the outer one
+		calls := 0
+		err := repos.UnitOfWork.Do(context.Background(), func(repos Repository) error {
+			calls++
+			return nestedErr
+		})
+		require.ErrorIs(t, err, nestedErr)
+		require.Equal(t, 1, calls)
+
+		return repos.UnitOfWork.Do(context.Background(), func(repos Repository) error {
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,107 @@
+package user
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newUserMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) Get(_ context.Context, id string) (*entity.User, error) {
+	var user entity.User
+	err := r.db.Read(func(t *memdb.Tables) error {
+		u, ok := t.Users[id]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		user = u
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *memoryRepo) GetByUsername(_ context.Context, username string) (*entity.User, error) {
+	var user entity.User
+	err := r.db.Read(func(t *memdb.Tables) error {
+		for _, u := range t.Users {
+			if u.Username == username {
+				user = u
+				return nil
+			}
+		}
+		return memdb.ErrNotFound()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *memoryRepo) Store(_ context.Context, user *entity.User) (string, error) {
+	id := memdb.NewID()
+	err := r.db.Write(func(t *memdb.Tables) error {
+		if usernameTaken(t, user.Username, "") {
+			return memdb.ErrExists("username")
+		}
+		t.Users[id] = entity.User{ID: id, Username: user.Username, PasswordHash: user.PasswordHash}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *memoryRepo) Update(_ context.Context, user *entity.User) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		if _, ok := t.Users[user.ID]; !ok {
+			return nil
+		}
+		if usernameTaken(t, user.Username, user.ID) {
+			return memdb.ErrExists("username")
+		}
+		t.Users[user.ID] = entity.User{ID: user.ID, Username: user.Username, PasswordHash: user.PasswordHash}
+		return nil
+	})
+}
+
+func (r *memoryRepo) Remove(_ context.Context, id string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		t.DeleteUser(id)
+		return nil
+	})
+}
+
+func (r *memoryRepo) AddFollower(_ context.Context, userID, followerID string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		if err := t.CheckUser(userID, "user_id"); err != nil {
+			return err
+		}
+		if err := t.CheckUser(followerID, "follower_id"); err != nil {
+			return err
+		}
+		t.Followers[memdb.Follow{UserID: userID, FollowerID: followerID}] = struct{}{}
+		return nil
+	})
+}
+
+func usernameTaken(t *memdb.Tables, username, exceptID string) bool {
+	for id, u := range t.Users {
+		if id != exceptID && u.Username == username {
+			return true
+		}
+	}
+	return false
+}
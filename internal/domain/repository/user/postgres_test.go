@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
 	"testing"
 )
 
@@ -22,7 +23,7 @@ func TestGet(t *testing.T) {
 	mock.ExpectQuery("SELECT").WithArgs(id).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password"}).AddRow(id, "qwerty", "password"))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	u, err := r.Get(ctx, id)
 	if err != nil {
 		t.Errorf("error was not expected while get user: %s", err)
@@ -50,7 +51,7 @@ func TestGetError(t *testing.T) {
 	mock.ExpectQuery("SELECT").WithArgs(id).
 		WillReturnError(fmt.Errorf("some error"))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	u, err := r.Get(ctx, id)
 	if err == nil {
 		t.Errorf("was expecting an error, but there was none")
@@ -79,7 +80,7 @@ func TestGetByUsername(t *testing.T) {
 	mock.ExpectQuery("SELECT").WithArgs(username).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password"}).AddRow(id, username, "password"))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	u, err := r.GetByUsername(ctx, username)
 	if err != nil {
 		t.Errorf("error was not expected while get user: %s", err)
@@ -107,7 +108,7 @@ func TestGetByUsernameError(t *testing.T) {
 	mock.ExpectQuery("SELECT").WithArgs(username).
 		WillReturnError(fmt.Errorf("some error"))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	u, err := r.GetByUsername(ctx, username)
 	if err == nil {
 		t.Errorf("was expecting an error, but there was none")
@@ -135,7 +136,7 @@ func TestStore(t *testing.T) {
 	query := fmt.Sprintf("INSERT INTO %s", userTableName)
 	mock.ExpectQuery(query).WithArgs(u.Username, u.PasswordHash).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(u.ID))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	id, err := r.Store(ctx, u)
 	if err != nil {
 		t.Errorf("error was not expected while insert user: %s", err)
@@ -164,7 +165,7 @@ func TestStoreError(t *testing.T) {
 	mock.ExpectQuery(query).WithArgs(u.Username, u.PasswordHash).
 		WillReturnError(fmt.Errorf("some error"))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	if _, err := r.Store(ctx, u); err == nil {
 		t.Errorf("was expecting an error, but there was none")
 	}
@@ -189,7 +190,7 @@ func TestUpdate(t *testing.T) {
 	mock.ExpectExec(query).WithArgs(u.Username, u.PasswordHash, u.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	if err := r.Update(ctx, u); err != nil {
 		t.Errorf("error was not expected while insert user: %s", err)
 	}
@@ -214,7 +215,7 @@ func TestUpdateError(t *testing.T) {
 	mock.ExpectExec(query).WithArgs(u.Username, u.PasswordHash, u.ID).
 		WillReturnError(fmt.Errorf("some error"))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	if err := r.Update(ctx, u); err == nil {
 		t.Errorf("was expecting an error, but there was none")
 	}
@@ -239,7 +240,7 @@ func TestRemove(t *testing.T) {
 	mock.ExpectExec(query).WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	if err := r.Remove(ctx, id); err != nil {
 		t.Errorf("error was not expected while insert user: %s", err)
 	}
@@ -264,7 +265,7 @@ func TestRemoveError(t *testing.T) {
 	mock.ExpectExec(query).WithArgs(id).
 		WillReturnError(fmt.Errorf("some error"))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	if err := r.Remove(ctx, id); err == nil {
 		t.Errorf("was expecting an error, but there was none")
 	}
@@ -290,7 +291,7 @@ func TestAddFollower(t *testing.T) {
 	mock.ExpectExec(query).WithArgs(u1.ID, u2.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	if err := r.AddFollower(ctx, u1.ID, u2.ID); err != nil {
 		t.Errorf("error was not expected while insert user: %s", err)
 	}
@@ -316,7 +317,7 @@ func TestAddFollowerError(t *testing.T) {
 	mock.ExpectExec(query).WithArgs(u1.ID, u2.ID).
 		WillReturnError(fmt.Errorf("some error"))
 
-	r := newUserPostgresRepository(db)
+	r := newUserPostgresRepository(dbtx.New(db))
 	if err := r.AddFollower(ctx, u1.ID, u2.ID); err == nil {
 		t.Errorf("was expecting an error, but there was none")
 	}
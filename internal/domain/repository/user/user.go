@@ -2,8 +2,9 @@ package user
 
 import (
 	"context"
-	"database/sql"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
 )
 
 type Repository interface {
@@ -17,6 +18,10 @@ type Repository interface {
 	AddFollower(ctx context.Context, userID, followerID string) error
 }
 
-func NewRepository(db *sql.DB) Repository {
+func NewRepository(db dbtx.DB) Repository {
 	return newUserPostgresRepository(db)
 }
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newUserMemoryRepository(db)
+}
@@ -0,0 +1,169 @@
+package variant
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"sort"
+)
+
+type memoryRepo struct {
+	db *memdb.DB
+}
+
+func newVariantMemoryRepository(db *memdb.DB) Repository {
+	return &memoryRepo{
+		db: db,
+	}
+}
+
+func (r *memoryRepo) Get(_ context.Context, id string) (*entity.Variant, error) {
+	var variant entity.Variant
+	err := r.db.Read(func(t *memdb.Tables) error {
+		v, ok := t.Variants[id]
+		if !ok {
+			return memdb.ErrNotFound()
+		}
+		variant = copyVariant(v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *memoryRepo) GetAllByProductID(_ context.Context, productID string) (*[]entity.Variant, error) {
+	variants := []entity.Variant{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for _, v := range t.Variants {
+			if v.ProductID == productID {
+				variants = append(variants, copyVariant(v))
+			}
+		}
+		return nil
+	})
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].SKU < variants[j].SKU
+	})
+	return &variants, nil
+}
+
+func (r *memoryRepo) GetPrices(_ context.Context, id string) (*[]entity.Price, error) {
+	prices := []entity.Price{}
+	_ = r.db.Read(func(t *memdb.Tables) error {
+		for currency, price := range t.VariantPrices[id] {
+			prices = append(prices, entity.Price{Currency: currency, Price: price})
+		}
+		return nil
+	})
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Currency < prices[j].Currency
+	})
+	return &prices, nil
+}
+
+func (r *memoryRepo) StoreWithPrices(_ context.Context, variant *entity.Variant) (string, error) {
+	id := memdb.NewID()
+	err := r.db.Write(func(t *memdb.Tables) error {
+		if err := t.CheckProduct(variant.ProductID); err != nil {
+			return err
+		}
+		if skuTaken(t, variant.SKU, "") {
+			return memdb.ErrExists("sku")
+		}
+
+		t.Variants[id] = copyVariant(entity.Variant{
+			ID:          id,
+			ProductID:   variant.ProductID,
+			SKU:         variant.SKU,
+			Attributes:  variant.Attributes,
+			LeftInStock: variant.LeftInStock,
+		})
+		if variant.LeftInStock > 0 {
+			t.InsertMovement(&entity.StockMovement{
+				ProductID: variant.ProductID,
+				VariantID: &id,
+				Kind:      entity.StockReceipt,
+				Quantity:  variant.LeftInStock,
+				Balance:   variant.LeftInStock,
+				Reason:    "initial stock",
+			})
+		}
+		prices := make(map[string]float64, len(variant.Prices))
+		for _, price := range variant.Prices {
+			prices[price.Currency] = price.Price
+		}
+		t.VariantPrices[id] = prices
+		t.TouchProduct(variant.ProductID)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *memoryRepo) Update(_ context.Context, id string, input *entity.VariantUpdateInput) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		v, ok := t.Variants[id]
+		if !ok {
+			return nil
+		}
+		if input.SKU != nil {
+			if skuTaken(t, *input.SKU, id) {
+				return memdb.ErrExists("sku")
+			}
+			v.SKU = *input.SKU
+		}
+		if input.Attributes != nil {
+			v.Attributes = *input.Attributes
+		}
+		t.Variants[id] = copyVariant(v)
+		t.TouchProduct(v.ProductID)
+		return nil
+	})
+}
+
+func (r *memoryRepo) Remove(_ context.Context, id string) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		return t.DeleteVariant(id)
+	})
+}
+
+func (r *memoryRepo) AddPrice(_ context.Context, variantID string, price *entity.Price) error {
+	return r.db.Write(func(t *memdb.Tables) error {
+		v, ok := t.Variants[variantID]
+		if !ok {
+			return memdb.ErrBrokenRef("variant_id")
+		}
+		prices, ok := t.VariantPrices[variantID]
+		if !ok {
+			prices = map[string]float64{}
+			t.VariantPrices[variantID] = prices
+		}
+		prices[price.Currency] = price.Price
+		t.TouchProduct(v.ProductID)
+		return nil
+	})
+}
+
+// copyVariant - attributes are stored and returned as copies, like the JSON column.
+func copyVariant(v entity.Variant) entity.Variant {
+	attributes := make(map[string]string, len(v.Attributes))
+	for k, val := range v.Attributes {
+		attributes[k] = val
+	}
+	v.Attributes = attributes
+	v.Prices = nil
+	return v
+}
+
+func skuTaken(t *memdb.Tables, sku, exceptID string) bool {
+	for id, v := range t.Variants {
+		if id != exceptID && v.SKU == sku {
+			return true
+		}
+	}
+	return false
+}
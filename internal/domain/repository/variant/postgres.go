@@ -0,0 +1,233 @@
+package variant
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+const (
+	variantsTableName  = "product_variants"
+	pricesTableName    = "product_variant_prices"
+	movementsTableName = "product_stock_movements"
+)
+
+type repo struct {
+	db dbtx.DB
+}
+
+func newVariantPostgresRepository(d dbtx.DB) Repository {
+	return &repo{
+		db: d,
+	}
+}
+
+func (r *repo) Get(ctx context.Context, id string) (*entity.Variant, error) {
+	query := fmt.Sprintf("SELECT id, product_id, sku, attributes, left_in_stock FROM %s WHERE id = $1", variantsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	row := r.db.QueryRowContext(ctx, query, id)
+	variant := entity.Variant{}
+
+	var attributes []byte
+	err := row.Scan(&variant.ID, &variant.ProductID, &variant.SKU, &attributes, &variant.LeftInStock)
+	if err != nil {
+		return nil, errs.HandleErrorDB(err)
+	}
+	if err = json.Unmarshal(attributes, &variant.Attributes); err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "can't decode variant attributes")
+	}
+	return &variant, nil
+}
+
+func (r *repo) GetAllByProductID(ctx context.Context, productID string) (*[]entity.Variant, error) {
+	query := fmt.Sprintf("SELECT id, product_id, sku, attributes, left_in_stock FROM %s WHERE product_id = $1 ORDER BY sku", variantsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errs.HandleErrorDB(err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+		}
+	}(rows)
+
+	variants := []entity.Variant{}
+	for rows.Next() {
+		v := entity.Variant{}
+		var attributes []byte
+		err := rows.Scan(&v.ID, &v.ProductID, &v.SKU, &attributes, &v.LeftInStock)
+		if err != nil {
+			continue
+		}
+		if err = json.Unmarshal(attributes, &v.Attributes); err != nil {
+			continue
+		}
+		variants = append(variants, v)
+	}
+
+	return &variants, nil
+}
+
+func (r *repo) GetPrices(ctx context.Context, id string) (*[]entity.Price, error) {
+	query := fmt.Sprintf("SELECT price, currency FROM %s WHERE variant_id = $1", pricesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errs.HandleErrorDB(err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+		}
+	}(rows)
+
+	prices := []entity.Price{}
+	for rows.Next() {
+		p := entity.Price{}
+		err := rows.Scan(&p.Price, &p.Currency)
+		if err != nil {
+			continue
+		}
+		prices = append(prices, p)
+	}
+
+	return &prices, nil
+}
+
+func (r *repo) StoreWithPrices(ctx context.Context, variant *entity.Variant) (string, error) {
+	attributes, err := json.Marshal(attributesOrEmpty(variant.Attributes))
+	if err != nil {
+		return "", errs.NewErrorWrapper(errs.Validation, err, "can't encode variant attributes")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Start transaction err: " + err.Error())
+		return "", errs.HandleErrorDB(err)
+	}
+	defer tx.Rollback()
+
+	var variantID string
+	query := fmt.Sprintf("INSERT INTO %s (product_id, sku, attributes, left_in_stock) VALUES ($1, $2, $3, $4) RETURNING id", variantsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	row := tx.QueryRowContext(ctx, query, variant.ProductID, variant.SKU, attributes, variant.LeftInStock)
+	if err = row.Scan(&variantID); err != nil {
+		return "", errs.HandleErrorDB(err)
+	}
+
+	if variant.LeftInStock > 0 {
+		query = fmt.Sprintf(`INSERT INTO %s (product_id, variant_id, kind, quantity, balance, reason) VALUES ($1, $2, $3, $4, $4, $5)`, movementsTableName)
+		log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+		_, err = tx.ExecContext(ctx, query, variant.ProductID, variantID, entity.StockReceipt, variant.LeftInStock, "initial stock")
+		if err != nil {
+			return "", errs.HandleErrorDB(err)
+		}
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (variant_id, currency, price) VALUES ($1, $2, $3)
+		ON CONFLICT (variant_id, currency) DO UPDATE SET price = EXCLUDED.price`, pricesTableName)
+	log.Ctx(ctx).Debug().Msg("Query for stmt: " + query)
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Prepare stmt err: " + err.Error())
+		return "", errs.HandleErrorDB(err)
+	}
+
+	for _, price := range variant.Prices {
+		_, err = stmt.ExecContext(ctx, variantID, price.Currency, price.Price)
+		if err != nil {
+			return "", errs.HandleErrorDB(err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Ctx(ctx).Debug().Msg("Commit transaction err: " + err.Error())
+		return "", errs.HandleErrorDB(err)
+	}
+
+	return variantID, nil
+}
+
+func (r *repo) Update(ctx context.Context, id string, input *entity.VariantUpdateInput) error {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.SKU != nil {
+		setValues = append(setValues, fmt.Sprintf("sku=$%d", argId))
+		args = append(args, *input.SKU)
+		argId++
+	}
+
+	if input.Attributes != nil {
+		attributes, err := json.Marshal(attributesOrEmpty(*input.Attributes))
+		if err != nil {
+			return errs.NewErrorWrapper(errs.Validation, err, "can't encode variant attributes")
+		}
+		setValues = append(setValues, fmt.Sprintf("attributes=$%d", argId))
+		args = append(args, attributes)
+		argId++
+	}
+
+	if len(setValues) == 0 {
+		return nil
+	}
+
+	setQuery := strings.Join(setValues, ", ")
+	args = append(args, id)
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", variantsTableName, setQuery, argId)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errs.HandleErrorDB(err)
+	}
+
+	return nil
+}
+
+func (r *repo) Remove(ctx context.Context, id string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", variantsTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errs.HandleRemoveErrorDB(err, "variant is used in orders")
+	}
+	return nil
+}
+
+func (r *repo) AddPrice(ctx context.Context, variantID string, price *entity.Price) error {
+	query := fmt.Sprintf(`INSERT INTO %s (variant_id, currency, price) VALUES ($1, $2, $3)
+		ON CONFLICT (variant_id, currency) DO UPDATE SET price = EXCLUDED.price`, pricesTableName)
+	log.Ctx(ctx).Debug().Msg("Query: " + query)
+
+	_, err := r.db.ExecContext(ctx, query, variantID, price.Currency, price.Price)
+	if err != nil {
+		return errs.HandleErrorDB(err)
+	}
+	return nil
+}
+
+func attributesOrEmpty(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return map[string]string{}
+	}
+	return attributes
+}
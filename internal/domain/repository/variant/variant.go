@@ -0,0 +1,27 @@
+package variant
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/dbtx"
+)
+
+type Repository interface {
+	Get(ctx context.Context, id string) (*entity.Variant, error)
+	GetAllByProductID(ctx context.Context, productID string) (*[]entity.Variant, error)
+	GetPrices(ctx context.Context, id string) (*[]entity.Price, error)
+
+	StoreWithPrices(ctx context.Context, variant *entity.Variant) (string, error)
+	Update(ctx context.Context, id string, input *entity.VariantUpdateInput) error
+	Remove(ctx context.Context, id string) error
+	AddPrice(ctx context.Context, variantID string, price *entity.Price) error
+}
+
+func NewRepository(db dbtx.DB) Repository {
+	return newVariantPostgresRepository(db)
+}
+
+func NewMemoryRepository(db *memdb.DB) Repository {
+	return newVariantMemoryRepository(db)
+}
@@ -7,11 +7,30 @@ import (
 )
 
 const (
-	signingKey = "asdq2ed1212d12dawsx"
-	tokenTTL   = 12 * time.Hour
+	developmentSigningKey = "asdq2ed1212d12dawsx"
+	defaultTokenTTL       = 12 * time.Hour
 )
 
+// TokenManager issues access tokens of users and gets the user back from them.
+type TokenManager interface {
+	GenerateToken(userID string) (string, error)
+	ParseToken(accessToken string) (string, error)
+}
+
+// NewAuthTokenGenerator - the development key is used without signingKey, tokens live 12h without ttl.
+func NewAuthTokenGenerator(signingKey string, ttl time.Duration) AuthTokenGenerator {
+	if signingKey == "" {
+		signingKey = developmentSigningKey
+	}
+	if ttl <= 0 {
+		ttl = defaultTokenTTL
+	}
+	return AuthTokenGenerator{signingKey: []byte(signingKey), ttl: ttl}
+}
+
 type AuthTokenGenerator struct {
+	signingKey []byte
+	ttl        time.Duration
 }
 
 type tokenClaims struct {
@@ -22,13 +41,13 @@ type tokenClaims struct {
 func (t AuthTokenGenerator) GenerateToken(userID string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
 		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
+			ExpiresAt: time.Now().Add(t.ttl).Unix(),
 			IssuedAt:  time.Now().Unix(),
 		},
 		userID,
 	})
 
-	return token.SignedString([]byte(signingKey))
+	return token.SignedString(t.signingKey)
 }
 
 func (t AuthTokenGenerator) ParseToken(accessToken string) (string, error) {
@@ -37,7 +56,7 @@ func (t AuthTokenGenerator) ParseToken(accessToken string) (string, error) {
 			return nil, errors.New("invalid signing method")
 		}
 
-		return []byte(signingKey), nil
+		return t.signingKey, nil
 	})
 	if err != nil {
 		return "", err
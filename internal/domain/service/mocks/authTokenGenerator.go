@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/service/authTokenGenerator.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTokenManager is a mock of TokenManager interface.
+type MockTokenManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenManagerMockRecorder
+}
+
+// MockTokenManagerMockRecorder is the mock recorder for MockTokenManager.
+type MockTokenManagerMockRecorder struct {
+	mock *MockTokenManager
+}
+
+// NewMockTokenManager creates a new mock instance.
+func NewMockTokenManager(ctrl *gomock.Controller) *MockTokenManager {
+	mock := &MockTokenManager{ctrl: ctrl}
+	mock.recorder = &MockTokenManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenManager) EXPECT() *MockTokenManagerMockRecorder {
+	return m.recorder
+}
+
+// GenerateToken mocks base method.
+func (m *MockTokenManager) GenerateToken(userID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateToken", userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateToken indicates an expected call of GenerateToken.
+func (mr *MockTokenManagerMockRecorder) GenerateToken(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockTokenManager)(nil).GenerateToken), userID)
+}
+
+// ParseToken mocks base method.
+func (m *MockTokenManager) ParseToken(accessToken string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseToken", accessToken)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseToken indicates an expected call of ParseToken.
+func (mr *MockTokenManagerMockRecorder) ParseToken(accessToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockTokenManager)(nil).ParseToken), accessToken)
+}
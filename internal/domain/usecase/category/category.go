@@ -0,0 +1,128 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/category"
+)
+
+var errCategoryCycle = errors.New("category can't be moved into own subtree")
+
+type UseCase interface {
+	GetByID(ctx context.Context, id string) (*entity.Category, error)
+	GetAll(ctx context.Context) (*[]entity.Category, error)
+	// GetSubtreeIDs finds category by ID or slug and returns IDs of it and all its subcategories.
+	GetSubtreeIDs(ctx context.Context, idOrSlug string) ([]string, error)
+	Create(ctx context.Context, category entity.Category) (string, error)
+	Update(ctx context.Context, category entity.Category) error
+	Remove(ctx context.Context, id string) error
+}
+
+type useCase struct {
+	repo category.Repository
+}
+
+func NewCategoryUseCase(repo category.Repository) UseCase {
+	return &useCase{repo: repo}
+}
+
+func (uc *useCase) GetByID(ctx context.Context, id string) (*entity.Category, error) {
+	res, err := uc.repo.Get(ctx, id)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+	return res, nil
+}
+
+func (uc *useCase) GetAll(ctx context.Context) (*[]entity.Category, error) {
+	res, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+	return res, nil
+}
+
+func (uc *useCase) GetSubtreeIDs(ctx context.Context, idOrSlug string) ([]string, error) {
+	var (
+		c   *entity.Category
+		err error
+	)
+	if is.UUIDv4.Validate(idOrSlug) == nil {
+		c, err = uc.repo.Get(ctx, idOrSlug)
+	} else {
+		c, err = uc.repo.GetBySlug(ctx, idOrSlug)
+	}
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+
+	ids, err := uc.repo.GetSubtreeIDs(ctx, c.ID)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+	return ids, nil
+}
+
+func (uc *useCase) Create(ctx context.Context, category entity.Category) (string, error) {
+	if err := category.Validate(); err != nil {
+		return "", errs.NewErrorWrapper(errs.Validation, err, "category validation error")
+	}
+
+	exist, err := uc.repo.GetBySlug(ctx, category.Slug)
+	if err != nil && !errors.Is(err, errs.RecordNotFound) {
+		return "", errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+	if exist != nil {
+		return "", errs.NewErrorWrapper(errs.Exist, nil, "slug is already taken")
+	}
+
+	res, err := uc.repo.Store(ctx, &category)
+	if err != nil {
+		return "", errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+	return res, nil
+}
+
+func (uc *useCase) Update(ctx context.Context, category entity.Category) error {
+	if err := category.Validate(); err != nil {
+		return errs.NewErrorWrapper(errs.Validation, err, "category validation error")
+	}
+
+	exist, err := uc.repo.GetBySlug(ctx, category.Slug)
+	if err != nil && !errors.Is(err, errs.RecordNotFound) {
+		return errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+	if exist != nil && exist.ID != category.ID {
+		return errs.NewErrorWrapper(errs.Exist, nil, "slug is already taken")
+	}
+
+	if category.ParentID != nil {
+		subtree, err := uc.repo.GetSubtreeIDs(ctx, category.ID)
+		if err != nil {
+			return errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+		}
+		for _, id := range subtree {
+			if id == *category.ParentID {
+				return errs.NewErrorWrapper(errs.Validation, errCategoryCycle, "category validation error")
+			}
+		}
+	}
+
+	if err := uc.repo.Update(ctx, &category); err != nil {
+		return errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+	return nil
+}
+
+func (uc *useCase) Remove(ctx context.Context, id string) error {
+	if err := uc.repo.Remove(ctx, id); err != nil {
+		if errors.Is(err, errs.RecordInUse) {
+			return errs.NewErrorWrapper(errs.Logic, err, "category has subcategories, move or remove them first")
+		}
+		return errs.NewErrorWrapper(errs.Database, err, "error from category repo")
+	}
+	return nil
+}
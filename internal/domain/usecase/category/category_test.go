@@ -0,0 +1,95 @@
+package category_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	mockCategory "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/category/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/category"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	rootID  = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1"
+	childID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+)
+
+func TestGetSubtreeIDsBySlug(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	repo := mockCategory.NewMockRepository(ctrl)
+	repo.EXPECT().GetBySlug(ctx, "clothes").Return(&entity.Category{ID: rootID, Slug: "clothes"}, nil).Times(1)
+	repo.EXPECT().GetSubtreeIDs(ctx, rootID).Return([]string{rootID, childID}, nil).Times(1)
+
+	useCase := category.NewCategoryUseCase(repo)
+	ids, err := useCase.GetSubtreeIDs(ctx, "clothes")
+	require.NoError(t, err)
+	require.Equal(t, []string{rootID, childID}, ids)
+}
+
+func TestUpdateMoveIntoOwnSubtree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	parentID := childID
+	c := entity.Category{ID: rootID, ParentID: &parentID, Name: "Clothes", Slug: "clothes"}
+
+	repo := mockCategory.NewMockRepository(ctrl)
+	repo.EXPECT().GetBySlug(ctx, c.Slug).Return(&entity.Category{ID: rootID, Slug: c.Slug}, nil).Times(1)
+	repo.EXPECT().GetSubtreeIDs(ctx, rootID).Return([]string{rootID, childID}, nil).Times(1)
+
+	useCase := category.NewCategoryUseCase(repo)
+	err := useCase.Update(ctx, c)
+	require.Error(t, err)
+	var tmp errs.CustomErrorWrapper
+	errors.As(err, &tmp)
+	require.Equal(t, errs.Validation, tmp.Code)
+}
+
+func TestCreateSlugTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	c := entity.Category{Name: "Clothes", Slug: "clothes"}
+
+	repo := mockCategory.NewMockRepository(ctrl)
+	repo.EXPECT().GetBySlug(ctx, c.Slug).Return(&entity.Category{ID: rootID, Slug: c.Slug}, nil).Times(1)
+
+	useCase := category.NewCategoryUseCase(repo)
+	id, err := useCase.Create(ctx, c)
+	require.Error(t, err)
+	var tmp errs.CustomErrorWrapper
+	errors.As(err, &tmp)
+	require.Equal(t, errs.Exist, tmp.Code)
+	require.Empty(t, id)
+}
+
+func TestCreateSlugCheckFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	c := entity.Category{Name: "Clothes", Slug: "clothes"}
+
+	repo := mockCategory.NewMockRepository(ctrl)
+	repo.EXPECT().GetBySlug(ctx, c.Slug).Return(nil, errs.HandleErrorDB(context.DeadlineExceeded)).Times(1)
+	repo.EXPECT().Store(ctx, gomock.Any()).Times(0)
+
+	useCase := category.NewCategoryUseCase(repo)
+	_, err := useCase.Create(ctx, c)
+	require.Error(t, err)
+	var tmp errs.CustomErrorWrapper
+	errors.As(err, &tmp)
+	require.Equal(t, errs.Timeout, tmp.Dig().Code)
+}
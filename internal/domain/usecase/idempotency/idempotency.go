@@ -0,0 +1,87 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/idempotency"
+	"time"
+)
+
+type UseCase interface {
+	// Begin reserves the key for the request. It returns stored record, if the response should be replayed,
+	// or nil, if the request should be processed and then completed or released.
+	Begin(ctx context.Context, userID, key, requestHash string) (*entity.IdempotencyRecord, error)
+	// Complete stores the response to replay it for retries.
+	Complete(ctx context.Context, record *entity.IdempotencyRecord) error
+	// Release frees the key of not completed request, so the client can retry it.
+	Release(ctx context.Context, userID, key string) error
+	RemoveExpired(ctx context.Context) (int64, error)
+}
+
+type useCase struct {
+	repo idempotency.Repository
+	ttl  time.Duration
+}
+
+const defaultTTL = 24 * time.Hour
+
+// NewIdempotencyUseCase - keys expire after ttl (24 hours if it's 0), then they can be used again.
+func NewIdempotencyUseCase(repo idempotency.Repository, ttl time.Duration) UseCase {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &useCase{repo: repo, ttl: ttl}
+}
+
+func (uc *useCase) Begin(ctx context.Context, userID, key, requestHash string) (*entity.IdempotencyRecord, error) {
+	record := entity.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(uc.ttl),
+	}
+	if err := record.Validate(); err != nil {
+		return nil, errs.NewErrorWrapper(errs.Validation, err, "idempotency key validation error")
+	}
+
+	existing, created, err := uc.repo.Reserve(ctx, &record)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from idempotency repo")
+	}
+	if created {
+		return nil, nil
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, errs.NewErrorWrapper(errs.Validation,
+			errors.New("idempotency key is already used for another request"), "idempotency key reuse")
+	}
+	if !existing.IsCompleted() {
+		return nil, errs.NewErrorWrapper(errs.Logic, errs.LogicalError, "request with this idempotency key is in progress")
+	}
+	return existing, nil
+}
+
+func (uc *useCase) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	if err := uc.repo.Complete(ctx, record); err != nil {
+		return errs.NewErrorWrapper(errs.Database, err, "error from idempotency repo")
+	}
+	return nil
+}
+
+func (uc *useCase) Release(ctx context.Context, userID, key string) error {
+	if err := uc.repo.Release(ctx, userID, key); err != nil {
+		return errs.NewErrorWrapper(errs.Database, err, "error from idempotency repo")
+	}
+	return nil
+}
+
+func (uc *useCase) RemoveExpired(ctx context.Context) (int64, error) {
+	n, err := uc.repo.RemoveExpired(ctx)
+	if err != nil {
+		return 0, errs.NewErrorWrapper(errs.Database, err, "error from idempotency repo")
+	}
+	return n, nil
+}
@@ -0,0 +1,124 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/image"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/storage"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/thumbnail"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// ThumbnailSize - max width and height of generated thumbnails.
+const ThumbnailSize = 320
+
+type UseCase interface {
+	GetAllByProductID(ctx context.Context, productID string) (*[]entity.ProductImage, error)
+	// Upload checks that data is an image of the declared content type, stores it with thumbnail
+	// and registers the image of product.
+	Upload(ctx context.Context, productID, contentType string, data []byte) (*entity.ProductImage, error)
+}
+
+type useCase struct {
+	repo    image.Repository
+	storage storage.Storage
+}
+
+func NewImageUseCase(repo image.Repository, storage storage.Storage) UseCase {
+	return &useCase{repo: repo, storage: storage}
+}
+
+var errNoStorage = errors.New("files storage is not configured")
+
+// GetAllByProductID - images are listed without URLs, if the storage isn't configured.
+func (uc *useCase) GetAllByProductID(ctx context.Context, productID string) (*[]entity.ProductImage, error) {
+	res, err := uc.repo.GetAllByProductID(ctx, productID)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from image repo")
+	}
+	if uc.storage == nil {
+		return res, nil
+	}
+	for i := 0; i < len(*res); i++ {
+		(*res)[i].URL = uc.storage.URL((*res)[i].Key)
+		(*res)[i].ThumbnailURL = uc.storage.URL((*res)[i].ThumbnailKey)
+	}
+	return res, nil
+}
+
+func (uc *useCase) Upload(ctx context.Context, productID, contentType string, data []byte) (*entity.ProductImage, error) {
+	if uc.storage == nil {
+		return nil, errs.NewErrorWrapper(errs.Internal, errNoStorage, "")
+	}
+
+	ext, ok := entity.ImageContentTypes[contentType]
+	if !ok {
+		return nil, errs.NewErrorWrapper(errs.Validation, errors.New("unsupported content type "+contentType),
+			"image validation error")
+	}
+	if detected := http.DetectContentType(data); detected != contentType {
+		return nil, errs.NewErrorWrapper(errs.Validation, errors.New("file content is "+detected+", not "+contentType),
+			"image validation error")
+	}
+
+	thumb, err := thumbnail.Make(bytes.NewReader(data), ThumbnailSize)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Validation, err, "image validation error")
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Internal, err, "can't generate image name")
+	}
+
+	img := entity.ProductImage{
+		ProductID:    productID,
+		Key:          "products/" + productID + "/" + name + ext,
+		ThumbnailKey: "products/" + productID + "/" + name + "_thumb.jpg",
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+	}
+	if err = img.Validate(); err != nil {
+		return nil, errs.NewErrorWrapper(errs.Validation, err, "image validation error")
+	}
+
+	if err = uc.storage.Put(ctx, img.Key, bytes.NewReader(data), contentType); err != nil {
+		return nil, errs.NewErrorWrapper(errs.IO, err, "error from image storage")
+	}
+	if err = uc.storage.Put(ctx, img.ThumbnailKey, bytes.NewReader(thumb), "image/jpeg"); err != nil {
+		uc.cleanup(ctx, img.Key)
+		return nil, errs.NewErrorWrapper(errs.IO, err, "error from image storage")
+	}
+
+	if _, err = uc.repo.Store(ctx, &img); err != nil {
+		uc.cleanup(ctx, img.Key, img.ThumbnailKey)
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from image repo")
+	}
+
+	img.URL = uc.storage.URL(img.Key)
+	img.ThumbnailURL = uc.storage.URL(img.ThumbnailKey)
+	return &img, nil
+}
+
+// cleanup removes files of the image which failed to be registered.
+func (uc *useCase) cleanup(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := uc.storage.Delete(ctx, key); err != nil {
+			log.Ctx(ctx).Warn().Msgf("Can't remove orphaned image %s: %s", key, err.Error())
+		}
+	}
+}
+
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,53 @@
+package inventory
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/inventory"
+)
+
+type UseCase interface {
+	GetMovements(ctx context.Context, productID string) (*[]entity.StockMovement, error)
+	// Adjust registers manual stock movement (receipt, return or adjustment) made by the actor.
+	Adjust(ctx context.Context, productID, actorID string, input entity.StockAdjustmentInput) (*entity.StockMovement, error)
+}
+
+type useCase struct {
+	repo inventory.Repository
+}
+
+func NewInventoryUseCase(repo inventory.Repository) UseCase {
+	return &useCase{repo: repo}
+}
+
+func (uc *useCase) GetMovements(ctx context.Context, productID string) (*[]entity.StockMovement, error) {
+	res, err := uc.repo.GetMovements(ctx, productID)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from inventory repo")
+	}
+	return res, nil
+}
+
+func (uc *useCase) Adjust(ctx context.Context, productID, actorID string, input entity.StockAdjustmentInput) (*entity.StockMovement, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errs.NewErrorWrapper(errs.Validation, err, "stock adjustment validation error")
+	}
+
+	m := entity.StockMovement{
+		ProductID:   productID,
+		VariantID:   input.VariantID,
+		Kind:        input.Kind,
+		Quantity:    input.Quantity,
+		Reason:      input.Reason,
+		ActorUserID: &actorID,
+	}
+	if err := m.Validate(); err != nil {
+		return nil, errs.NewErrorWrapper(errs.Validation, err, "stock movement validation error")
+	}
+
+	if _, err := uc.repo.AddMovement(ctx, &m); err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from inventory repo")
+	}
+	return &m, nil
+}
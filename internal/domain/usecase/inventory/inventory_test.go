@@ -0,0 +1,93 @@
+package inventory_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	mockInventory "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/inventory/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/inventory"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	productID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+	actorID   = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1"
+)
+
+func TestAdjust(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	repo := mockInventory.NewMockRepository(ctrl)
+	repo.EXPECT().AddMovement(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, m *entity.StockMovement) (int64, error) {
+		require.Equal(t, productID, m.ProductID)
+		require.Equal(t, entity.StockAdjustment, m.Kind)
+		require.Equal(t, -2, m.Quantity)
+		require.Equal(t, actorID, *m.ActorUserID)
+		m.ID = 5
+		m.Balance = 8
+		return m.ID, nil
+	}).Times(1)
+
+	useCase := inventory.NewInventoryUseCase(repo)
+	m, err := useCase.Adjust(ctx, productID, actorID, entity.StockAdjustmentInput{
+		Kind:     entity.StockAdjustment,
+		Quantity: -2,
+		Reason:   "damaged in warehouse",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), m.ID)
+	require.Equal(t, 8, m.Balance)
+}
+
+func TestAdjustValidateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	repo := mockInventory.NewMockRepository(ctrl)
+
+	cases := []entity.StockAdjustmentInput{
+		{Kind: entity.StockReservation, Quantity: -1},
+		{Kind: entity.StockAdjustment, Quantity: 1},
+		{Kind: entity.StockReturn, Quantity: -1},
+	}
+
+	useCase := inventory.NewInventoryUseCase(repo)
+	for _, input := range cases {
+		m, err := useCase.Adjust(ctx, productID, actorID, input)
+		require.Error(t, err)
+		var tmp errs.CustomErrorWrapper
+		errors.As(err, &tmp)
+		require.Equal(t, errs.Validation, tmp.Code)
+		require.Nil(t, m)
+	}
+}
+
+func TestAdjustNotEnoughStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	repo := mockInventory.NewMockRepository(ctrl)
+	repoErr := errs.NewErrorWrapper(errs.Logic, errs.OutOfStock, "not enough amount in stock")
+	repo.EXPECT().AddMovement(ctx, gomock.Any()).Return(int64(0), repoErr).Times(1)
+
+	useCase := inventory.NewInventoryUseCase(repo)
+	_, err := useCase.Adjust(ctx, productID, actorID, entity.StockAdjustmentInput{
+		Kind:     entity.StockAdjustment,
+		Quantity: -100,
+		Reason:   "inventory count",
+	})
+	require.Error(t, err)
+	var tmp errs.CustomErrorWrapper
+	errors.As(err, &tmp)
+	require.Equal(t, errs.Logic, tmp.Dig().Code)
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/inventory"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/order"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/user"
+)
+
+// Recorder counts domain events, e.g. into Prometheus (pkg/metrics).
+type Recorder interface {
+	OrderCreated()
+	StockOutRejected(operation string)
+	SignInFailed(reason string)
+}
+
+// WithMetrics decorates use cases producing domain events, others are kept as is.
+func WithMetrics(ucs UseCases, r Recorder) UseCases {
+	ucs.Orders = ordersWithMetrics{UseCase: ucs.Orders, r: r}
+	ucs.Inventory = inventoryWithMetrics{UseCase: ucs.Inventory, r: r}
+	ucs.Users = usersWithMetrics{UseCase: ucs.Users, r: r}
+	return ucs
+}
+
+type ordersWithMetrics struct {
+	order.UseCase
+	r Recorder
+}
+
+func (uc ordersWithMetrics) Create(ctx context.Context, o entity.Order) (string, bool, error) {
+	id, created, err := uc.UseCase.Create(ctx, o)
+	// the repeated request returns the stored order, it isn't counted again
+	if created {
+		uc.r.OrderCreated()
+	}
+	if errors.Is(err, errs.OutOfStock) {
+		uc.r.StockOutRejected("order")
+	}
+	return id, created, err
+}
+
+func (uc ordersWithMetrics) AddProduct(ctx context.Context, p *entity.Product, v *entity.Variant, op *entity.OrderProduct) error {
+	err := uc.UseCase.AddProduct(ctx, p, v, op)
+	if errors.Is(err, errs.OutOfStock) {
+		uc.r.StockOutRejected("order")
+	}
+	return err
+}
+
+type inventoryWithMetrics struct {
+	inventory.UseCase
+	r Recorder
+}
+
+func (uc inventoryWithMetrics) Adjust(ctx context.Context, productID, actorID string, input entity.StockAdjustmentInput) (*entity.StockMovement, error) {
+	m, err := uc.UseCase.Adjust(ctx, productID, actorID, input)
+	if errors.Is(err, errs.OutOfStock) {
+		uc.r.StockOutRejected("adjustment")
+	}
+	return m, err
+}
+
+type usersWithMetrics struct {
+	user.UseCase
+	r Recorder
+}
+
+func (uc usersWithMetrics) GetUserIfCredentialsValid(ctx context.Context, username, password string) (*entity.User, error) {
+	u, err := uc.UseCase.GetUserIfCredentialsValid(ctx, username, password)
+	switch {
+	case errors.Is(err, errs.InvalidPassword):
+		uc.r.SignInFailed("invalid_password")
+	case errors.Is(err, errs.RecordNotFound):
+		uc.r.SignInFailed("unknown_user")
+	}
+	return u, err
+}
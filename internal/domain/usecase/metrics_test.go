@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	mockInventory "github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/inventory/mocks"
+	mockOrders "github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/order/mocks"
+	mockUsers "github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/user/mocks"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type recorderFake struct {
+	ordersCreated int
+	stockOuts     []string
+	signInFails   []string
+}
+
+func (r *recorderFake) OrderCreated() {
+	r.ordersCreated++
+}
+
+func (r *recorderFake) StockOutRejected(operation string) {
+	r.stockOuts = append(r.stockOuts, operation)
+}
+
+func (r *recorderFake) SignInFailed(reason string) {
+	r.signInFails = append(r.signInFails, reason)
+}
+
+func TestWithMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	outOfStock := errs.NewErrorWrapper(errs.Database, errs.NewErrorWrapper(errs.Logic, errs.OutOfStock, "not enough amount in stock"), "error from orders repo")
+
+	orders := mockOrders.NewMockUseCase(ctrl)
+	orders.EXPECT().Create(ctx, gomock.Any()).Return("id", true, nil)
+	orders.EXPECT().Create(ctx, gomock.Any()).Return("id", false, nil)
+	orders.EXPECT().Create(ctx, gomock.Any()).Return("", false, errors.New("boom"))
+	orders.EXPECT().Create(ctx, gomock.Any()).Return("", false, errs.NewErrorWrapper(errs.Database, outOfStock, "order is not created"))
+	orders.EXPECT().AddProduct(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(outOfStock)
+	orders.EXPECT().AddProduct(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errs.NewErrorWrapper(errs.Logic, errs.LogicalError, "product is archived"))
+
+	inventory := mockInventory.NewMockUseCase(ctrl)
+	inventory.EXPECT().Adjust(ctx, "p", "u", gomock.Any()).Return(nil, outOfStock)
+
+	users := mockUsers.NewMockUseCase(ctrl)
+	users.EXPECT().GetUserIfCredentialsValid(ctx, "alice", "wrong").
+		Return(nil, errs.NewErrorWrapper(errs.UserCredentials, errs.InvalidPassword, "invalid credentials"))
+	users.EXPECT().GetUserIfCredentialsValid(ctx, "bob", "secret").
+		Return(nil, errs.NewErrorWrapper(errs.Database, errs.NewErrorWrapper(errs.NotExist, errs.RecordNotFound, "not found"), "error from user repo"))
+	users.EXPECT().GetUserIfCredentialsValid(ctx, "alice", "secret").Return(&entity.User{ID: "id"}, nil)
+
+	r := &recorderFake{}
+	ucs := WithMetrics(UseCases{Orders: orders, Inventory: inventory, Users: users}, r)
+
+	for i := 0; i < 4; i++ {
+		_, _, _ = ucs.Orders.Create(ctx, entity.Order{})
+	}
+	_ = ucs.Orders.AddProduct(ctx, &entity.Product{}, nil, &entity.OrderProduct{})
+	_ = ucs.Orders.AddProduct(ctx, &entity.Product{}, nil, &entity.OrderProduct{})
+	_, _ = ucs.Inventory.Adjust(ctx, "p", "u", entity.StockAdjustmentInput{})
+	_, _ = ucs.Users.GetUserIfCredentialsValid(ctx, "alice", "wrong")
+	_, _ = ucs.Users.GetUserIfCredentialsValid(ctx, "bob", "secret")
+	_, _ = ucs.Users.GetUserIfCredentialsValid(ctx, "alice", "secret")
+
+	require.Equal(t, 1, r.ordersCreated, "failed and repeated creation isn't counted")
+	require.Equal(t, []string{"order", "order", "adjustment"}, r.stockOuts, "only out of stock is counted")
+	require.Equal(t, []string{"invalid_password", "unknown_user"}, r.signInFails)
+}
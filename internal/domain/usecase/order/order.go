@@ -2,20 +2,41 @@ package order
 
 import (
 	"context"
+	"errors"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/order"
 )
 
-type UseCase struct {
+type UseCase interface {
+	GetByID(ctx context.Context, orderID string) (*entity.Order, error)
+	GetAllByUserID(ctx context.Context, userID string) (*[]entity.Order, error)
+	GetAllOrderProducts(ctx context.Context, orderID string) (*[]entity.OrderProductView, error)
+	// Create stores the order with its products: lines are added and the stock is reserved in the same transaction,
+	// nothing is stored if any line fails. The order with the same number is returned as is, its lines aren't changed,
+	// created is false then.
+	Create(ctx context.Context, order entity.Order) (id string, created bool, err error)
+	// AddProduct adds order line of the product, v is the chosen variant or nil for simple product.
+	AddProduct(ctx context.Context, p *entity.Product, v *entity.Variant, op *entity.OrderProduct) error
+	// Remove checks the version of order if it's not 0.
+	Remove(ctx context.Context, id string, version int) error
+	// Update checks order.Version if it's not 0 and returns the new version.
+	Update(ctx context.Context, order entity.Order) (int, error)
+	RemoveProduct(ctx context.Context, orderID, productID string, variantID *string) error
+}
+
+type useCase struct {
 	repo order.Repository
+	uow  repository.UnitOfWork
 }
 
-func NewOrderUseCase(repo order.Repository) *UseCase {
-	return &UseCase{repo: repo}
+// NewOrderUseCase - uow is required to create orders with products.
+func NewOrderUseCase(repo order.Repository, uow repository.UnitOfWork) UseCase {
+	return &useCase{repo: repo, uow: uow}
 }
 
-func (uc *UseCase) GetByID(ctx context.Context, orderID string) (*entity.Order, error) {
+func (uc *useCase) GetByID(ctx context.Context, orderID string) (*entity.Order, error) {
 	res, err := uc.repo.Get(ctx, orderID)
 	if err != nil {
 		return nil, errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
@@ -23,7 +44,7 @@ func (uc *UseCase) GetByID(ctx context.Context, orderID string) (*entity.Order,
 	return res, nil
 }
 
-func (uc *UseCase) GetAllByUserID(ctx context.Context, userID string) (*[]entity.Order, error) {
+func (uc *useCase) GetAllByUserID(ctx context.Context, userID string) (*[]entity.Order, error) {
 	res, err := uc.repo.GetAllByUserID(ctx, userID)
 	if err != nil {
 		return nil, errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
@@ -31,7 +52,7 @@ func (uc *UseCase) GetAllByUserID(ctx context.Context, userID string) (*[]entity
 	return res, nil
 }
 
-func (uc *UseCase) GetAllOrderProducts(ctx context.Context, orderID string) (*[]entity.OrderProductView, error) {
+func (uc *useCase) GetAllOrderProducts(ctx context.Context, orderID string) (*[]entity.OrderProductView, error) {
 	res, err := uc.repo.GetProducts(ctx, orderID)
 	if err != nil {
 		return nil, errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
@@ -39,25 +60,69 @@ func (uc *UseCase) GetAllOrderProducts(ctx context.Context, orderID string) (*[]
 	return res, nil
 }
 
-func (uc *UseCase) Create(ctx context.Context, order entity.Order) (string, error) {
+func (uc *useCase) Create(ctx context.Context, order entity.Order) (string, bool, error) {
 	if err := order.Validate(); err != nil {
-		return "", errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
+		return "", false, errs.NewErrorWrapper(errs.Validation, err, "order validation error")
+	}
+
+	if len(order.Products) == 0 {
+		res, created, err := uc.repo.Store(ctx, &order)
+		if err != nil {
+			return "", false, errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
+		}
+		return res, created, nil
 	}
 
-	res, err := uc.repo.Store(ctx, &order)
+	if uc.uow == nil {
+		return "", false, errs.NewErrorWrapper(errs.Internal, errors.New("unit of work is not configured"), "")
+	}
+
+	var id string
+	var created bool
+	err := uc.uow.Do(ctx, func(repos repository.Repository) error {
+		var err error
+		if id, created, err = repos.Orders.Store(ctx, &order); err != nil {
+			return err
+		}
+		// the repeated request gets the stored order, its lines are already reserved
+		if !created {
+			return nil
+		}
+		for _, p := range order.Products {
+			// the repository checks the stock under the lock and decrements it
+			op := entity.OrderProduct{OrderID: id, ProductID: p.ID, VariantID: p.VariantID, Amount: p.Amount}
+			if err = repos.Orders.AddProduct(ctx, &op); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return "", errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
+		return "", false, errs.NewErrorWrapper(errs.Database, err, "order is not created")
 	}
-	return res, nil
+	return id, created, nil
 }
 
-func (uc *UseCase) AddProduct(ctx context.Context, p *entity.Product, op *entity.OrderProduct) error {
+func (uc *useCase) AddProduct(ctx context.Context, p *entity.Product, v *entity.Variant, op *entity.OrderProduct) error {
 	if err := op.Validate(); err != nil {
 		return errs.NewErrorWrapper(errs.Validation, err, "order product validation error")
 	}
 
-	if op.Amount > p.LeftInStock {
-		return errs.NewErrorWrapper(errs.Logic, errs.LogicalError, "not enough amount in stock")
+	if p.IsArchived() {
+		return errs.NewErrorWrapper(errs.Logic, errs.LogicalError, "product is archived")
+	}
+
+	leftInStock := p.LeftInStock
+	if v != nil {
+		if v.ProductID != p.ID {
+			return errs.NewErrorWrapper(errs.Validation, errs.LogicalError, "variant doesn't belong to the product")
+		}
+		leftInStock = v.LeftInStock
+		op.VariantID = &v.ID
+	}
+
+	if op.Amount > leftInStock {
+		return errs.NewErrorWrapper(errs.Logic, errs.OutOfStock, "not enough amount in stock")
 	}
 
 	if err := uc.repo.AddProduct(ctx, op); err != nil {
@@ -66,26 +131,33 @@ func (uc *UseCase) AddProduct(ctx context.Context, p *entity.Product, op *entity
 	return nil
 }
 
-func (uc *UseCase) Remove(ctx context.Context, id string) error {
-	if err := uc.repo.Remove(ctx, id); err != nil {
+func (uc *useCase) Remove(ctx context.Context, id string, version int) error {
+	if err := uc.repo.Remove(ctx, id, version); err != nil {
+		if errors.Is(err, errs.VersionChanged) {
+			return errs.NewErrorWrapper(errs.PreconditionFailed, err, "order is changed by another request")
+		}
 		return errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
 	}
 	return nil
 }
 
-func (uc *UseCase) Update(ctx context.Context, order entity.Order) error {
+func (uc *useCase) Update(ctx context.Context, order entity.Order) (int, error) {
 	if err := order.Validate(); err != nil {
-		return errs.NewErrorWrapper(errs.Validation, err, "order validation error")
+		return 0, errs.NewErrorWrapper(errs.Validation, err, "order validation error")
 	}
 
-	if err := uc.repo.Update(ctx, &order); err != nil {
-		return errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
+	version, err := uc.repo.Update(ctx, &order)
+	if err != nil {
+		if errors.Is(err, errs.VersionChanged) {
+			return 0, errs.NewErrorWrapper(errs.PreconditionFailed, err, "order is changed by another request")
+		}
+		return 0, errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
 	}
-	return nil
+	return version, nil
 }
 
-func (uc *UseCase) RemoveProduct(ctx context.Context, orderID, productID string) error {
-	if err := uc.repo.RemoveProduct(ctx, orderID, productID); err != nil {
+func (uc *useCase) RemoveProduct(ctx context.Context, orderID, productID string, variantID *string) error {
+	if err := uc.repo.RemoveProduct(ctx, orderID, productID, variantID); err != nil {
 		return errs.NewErrorWrapper(errs.Database, err, "error from orders repo")
 	}
 	return nil
@@ -0,0 +1,85 @@
+package order_test
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/memdb"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/order"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCreateWithProducts(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemoryRepository(memdb.New())
+
+	userID, err := repos.Users.Store(ctx, &entity.User{Username: "alice", PasswordHash: "hash"})
+	require.NoError(t, err)
+	milkID, err := repos.Products.StoreWithPrices(ctx, &entity.Product{Name: "milk", LeftInStock: 3})
+	require.NoError(t, err)
+	breadID, err := repos.Products.StoreWithPrices(ctx, &entity.Product{Name: "bread", LeftInStock: 1})
+	require.NoError(t, err)
+
+	useCase := order.NewOrderUseCase(repos.Orders, repos.UnitOfWork)
+
+	id, created, err := useCase.Create(ctx, entity.Order{UserID: userID, Number: 1, Products: []entity.OrderProductView{
+		{ID: milkID, Amount: 2},
+	}})
+	require.NoError(t, err)
+	require.True(t, created)
+
+	lines, err := useCase.GetAllOrderProducts(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, []entity.OrderProductView{{ID: milkID, Amount: 2}}, *lines)
+	requireStock(t, repos, milkID, 1)
+
+	// the retry gets the same order, the stock isn't reserved twice
+	sameID, created, err := useCase.Create(ctx, entity.Order{UserID: userID, Number: 1, Products: []entity.OrderProductView{
+		{ID: milkID, Amount: 1},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, id, sameID)
+	require.False(t, created)
+	lines, err = useCase.GetAllOrderProducts(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, []entity.OrderProductView{{ID: milkID, Amount: 2}}, *lines)
+	requireStock(t, repos, milkID, 1)
+
+	// the second line is out of stock: the order and the first line are rolled back
+	_, _, err = useCase.Create(ctx, entity.Order{UserID: userID, Number: 2, Products: []entity.OrderProductView{
+		{ID: milkID, Amount: 1},
+		{ID: breadID, Amount: 2},
+	}})
+	var ew errs.CustomErrorWrapper
+	require.True(t, errors.As(err, &ew))
+	require.Equal(t, errs.Logic, ew.Dig().Code)
+
+	orders, err := useCase.GetAllByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, *orders, 1)
+	requireStock(t, repos, milkID, 1)
+	requireStock(t, repos, breadID, 1)
+}
+
+func TestCreateInvalidProducts(t *testing.T) {
+	useCase := order.NewOrderUseCase(nil, nil)
+	_, _, err := useCase.Create(context.Background(), entity.Order{
+		UserID:   "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1",
+		Number:   1,
+		Products: []entity.OrderProductView{{ID: "milk", Amount: 1}},
+	})
+
+	var ew errs.CustomErrorWrapper
+	require.True(t, errors.As(err, &ew))
+	require.Equal(t, errs.Validation, ew.Dig().Code)
+}
+
+func requireStock(t *testing.T, repos repository.Repository, productID string, expected int) {
+	t.Helper()
+	p, err := repos.Products.Get(context.Background(), productID)
+	require.NoError(t, err)
+	require.Equal(t, expected, p.LeftInStock)
+}
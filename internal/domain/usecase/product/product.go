@@ -2,20 +2,46 @@ package product
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/product"
+	"io"
 )
 
-type UseCase struct {
+type UseCase interface {
+	GetByID(ctx context.Context, productID string) (*entity.Product, error)
+	GetAll(ctx context.Context, filter entity.ProductFilter) (*[]entity.Product, error)
+	Create(ctx context.Context, product entity.Product) (string, error)
+	CreateWithPrices(ctx context.Context, product entity.Product) (string, error)
+	// Remove checks the version of product if it's not 0.
+	Remove(ctx context.Context, id string, version int) error
+	// Archive hides product from listings and ordering, it's still available by ID for the orders history.
+	Archive(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	// Update checks the version of product if it's not 0 and returns the new one.
+	Update(ctx context.Context, id string, input entity.ProductUpdateInput, version int) (int, error)
+	// Import validates all rows and stores them in one transaction, if there are no errors and it's not a dry run.
+	// Storing stops on the first failed row, nothing is imported then.
+	Import(ctx context.Context, rows []ImportRow, dryRun bool) (*entity.ProductImportResult, error)
+	// Export writes not archived products in the format, output is flushed every exportFlushRows products
+	// (and to the client, if w is flusher).
+	Export(ctx context.Context, w io.Writer, format string) error
+}
+
+type useCase struct {
 	repo product.Repository
+	uow  repository.UnitOfWork
 }
 
-func NewProductUseCase(repo product.Repository) *UseCase {
-	return &UseCase{repo: repo}
+// NewProductUseCase - uow makes multistep operations (import) atomic, they fail without it (nil).
+func NewProductUseCase(repo product.Repository, uow repository.UnitOfWork) UseCase {
+	return &useCase{repo: repo, uow: uow}
 }
 
-func (uc *UseCase) GetByID(ctx context.Context, productID string) (*entity.Product, error) {
+func (uc *useCase) GetByID(ctx context.Context, productID string) (*entity.Product, error) {
 	res, err := uc.repo.Get(ctx, productID)
 	if err != nil {
 		return nil, errs.NewErrorWrapper(errs.Database, err, "error from product repo")
@@ -28,8 +54,8 @@ func (uc *UseCase) GetByID(ctx context.Context, productID string) (*entity.Produ
 	return res, nil
 }
 
-func (uc *UseCase) GetAll(ctx context.Context) (*[]entity.Product, error) {
-	res, err := uc.repo.GetAll(ctx)
+func (uc *useCase) GetAll(ctx context.Context, filter entity.ProductFilter) (*[]entity.Product, error) {
+	res, err := uc.repo.GetAll(ctx, filter)
 	if err != nil {
 		return nil, errs.NewErrorWrapper(errs.Database, err, "error from product repo")
 	}
@@ -43,7 +69,7 @@ func (uc *UseCase) GetAll(ctx context.Context) (*[]entity.Product, error) {
 	return res, nil
 }
 
-func (uc *UseCase) Create(ctx context.Context, product entity.Product) (string, error) {
+func (uc *useCase) Create(ctx context.Context, product entity.Product) (string, error) {
 	if err := product.Validate(); err != nil {
 		return "", errs.NewErrorWrapper(errs.Validation, err, "product validation error")
 	}
@@ -55,7 +81,7 @@ func (uc *UseCase) Create(ctx context.Context, product entity.Product) (string,
 	return res, nil
 }
 
-func (uc *UseCase) CreateWithPrices(ctx context.Context, product entity.Product) (string, error) {
+func (uc *useCase) CreateWithPrices(ctx context.Context, product entity.Product) (string, error) {
 	if err := product.Validate(); err != nil {
 		return "", errs.NewErrorWrapper(errs.Validation, err, "product validation error")
 	}
@@ -67,16 +93,149 @@ func (uc *UseCase) CreateWithPrices(ctx context.Context, product entity.Product)
 	return res, nil
 }
 
-func (uc *UseCase) Remove(ctx context.Context, id string) error {
-	if err := uc.repo.Remove(ctx, id); err != nil {
+func (uc *useCase) Remove(ctx context.Context, id string, version int) error {
+	if err := uc.repo.Remove(ctx, id, version); err != nil {
+		if errors.Is(err, errs.RecordInUse) {
+			return errs.NewErrorWrapper(errs.Logic, err, "product is used in orders, archive it instead")
+		}
+		if errors.Is(err, errs.VersionChanged) {
+			return errs.NewErrorWrapper(errs.PreconditionFailed, err, "product is changed by another request")
+		}
 		return errs.NewErrorWrapper(errs.Database, err, "error from product repo")
 	}
 	return nil
 }
 
-func (uc *UseCase) Update(ctx context.Context, id string, input entity.ProductUpdateInput) error {
-	if err := uc.repo.Update(ctx, id, &input); err != nil {
+func (uc *useCase) Archive(ctx context.Context, id string) error {
+	if err := uc.repo.Archive(ctx, id); err != nil {
 		return errs.NewErrorWrapper(errs.Database, err, "error from product repo")
 	}
 	return nil
 }
+
+func (uc *useCase) Restore(ctx context.Context, id string) error {
+	if err := uc.repo.Restore(ctx, id); err != nil {
+		return errs.NewErrorWrapper(errs.Database, err, "error from product repo")
+	}
+	return nil
+}
+
+func (uc *useCase) Update(ctx context.Context, id string, input entity.ProductUpdateInput, version int) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, errs.NewErrorWrapper(errs.Validation, err, "product validation error")
+	}
+
+	newVersion, err := uc.repo.Update(ctx, id, &input, version)
+	if err != nil {
+		if errors.Is(err, errs.VersionChanged) {
+			return 0, errs.NewErrorWrapper(errs.PreconditionFailed, err, "product is changed by another request")
+		}
+		return 0, errs.NewErrorWrapper(errs.Database, err, "error from product repo")
+	}
+	return newVersion, nil
+}
+
+// exportFlushRows - how often exported rows are flushed to the client.
+const exportFlushRows = 100
+
+// flusher is implemented by the response writer of the transport (http.Flusher), the use case doesn't depend on it.
+type flusher interface {
+	Flush()
+}
+
+func (uc *useCase) Import(ctx context.Context, rows []ImportRow, dryRun bool) (*entity.ProductImportResult, error) {
+	res := &entity.ProductImportResult{
+		DryRun: dryRun,
+		Total:  len(rows),
+		IDs:    []string{},
+		Errors: []entity.ProductImportRowError{},
+	}
+
+	for i := range rows {
+		err := rows[i].Err
+		if err == nil {
+			err = rows[i].Product.Validate()
+		}
+		if err != nil {
+			res.Errors = append(res.Errors, entity.ProductImportRowError{Row: rows[i].Line, Error: err.Error()})
+			continue
+		}
+		res.Valid++
+	}
+
+	if dryRun || len(res.Errors) > 0 || len(rows) == 0 {
+		return res, nil
+	}
+
+	if uc.uow == nil {
+		return res, errs.NewErrorWrapper(errs.Internal, errors.New("unit of work is not configured"), "")
+	}
+
+	err := uc.uow.Do(ctx, func(repos repository.Repository) error {
+		// the unit of work can repeat the transaction
+		res.IDs = []string{}
+		res.Imported = 0
+		for i := range rows {
+			id, err := repos.Products.StoreWithPrices(ctx, &rows[i].Product)
+			if err != nil {
+				return errs.NewErrorWrapper(errs.Database, err,
+					fmt.Sprintf("import stopped at row %d, %d products are stored before", rows[i].Line, res.Imported))
+			}
+			res.IDs = append(res.IDs, id)
+			res.Imported++
+		}
+		return nil
+	})
+	if err != nil {
+		res.IDs = []string{}
+		res.Imported = 0
+		return res, errs.NewErrorWrapper(errs.Database, err, "import is rolled back")
+	}
+	res.Committed = true
+
+	return res, nil
+}
+
+func (uc *useCase) Export(ctx context.Context, w io.Writer, format string) error {
+	currencies, err := uc.repo.GetCurrencies(ctx)
+	if err != nil {
+		return errs.NewErrorWrapper(errs.Database, err, "error from product repo")
+	}
+
+	enc, err := NewExportEncoder(w, format, currencies)
+	if err != nil {
+		return errs.NewErrorWrapper(errs.InvalidArgument, err, "unknown export format")
+	}
+
+	flush := func() error {
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(flusher); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	n := 0
+	err = uc.repo.Export(ctx, func(p *entity.Product) error {
+		if err := enc.Encode(p); err != nil {
+			return errs.NewErrorWrapper(errs.IO, err, "can't write exported product")
+		}
+		n++
+		if n%exportFlushRows == 0 {
+			if err := flush(); err != nil {
+				return errs.NewErrorWrapper(errs.IO, err, "can't write exported product")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errs.NewErrorWrapper(errs.Database, err, "error from product repo")
+	}
+
+	if err = flush(); err != nil {
+		return errs.NewErrorWrapper(errs.IO, err, "can't write exported products")
+	}
+	return nil
+}
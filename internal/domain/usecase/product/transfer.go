@@ -0,0 +1,248 @@
+package product
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formats of products import and export.
+// CSV has header with columns name, description, left_in_stock, category_id and price_<CURRENCY> per currency,
+// JSON Lines (NDJSON) has product object in each line.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const csvPricePrefix = "price_"
+
+var csvBaseColumns = []string{"name", "description", "left_in_stock", "category_id"}
+
+// ImportRow - decoded row of the import file. Err is set if the row can't be decoded.
+type ImportRow struct {
+	Line    int
+	Product entity.Product
+	Err     error
+}
+
+// DecodeImport reads all rows of the file, malformed rows are returned with Err.
+// Error is returned only when the file itself can't be read.
+func DecodeImport(r io.Reader, format string) ([]ImportRow, error) {
+	switch format {
+	case FormatCSV:
+		return decodeCSV(r)
+	case FormatNDJSON:
+		return decodeNDJSON(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func decodeCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return []ImportRow{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !isCSVColumn(name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicated column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("column \"name\" is required")
+	}
+
+	rows := []ImportRow{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, ImportRow{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := ImportRow{Line: line}
+		row.Product, row.Err = csvProduct(header, record)
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func isCSVColumn(name string) bool {
+	for _, c := range csvBaseColumns {
+		if name == c {
+			return true
+		}
+	}
+	return strings.HasPrefix(name, csvPricePrefix) && len(name) > len(csvPricePrefix)
+}
+
+func csvProduct(header, record []string) (entity.Product, error) {
+	p := entity.Product{Prices: []entity.Price{}}
+
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		name := strings.TrimSpace(header[i])
+
+		switch name {
+		case "name":
+			p.Name = value
+		case "description":
+			p.Description = value
+		case "left_in_stock":
+			if value == "" {
+				continue
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return p, fmt.Errorf("left_in_stock: %q is not a number", value)
+			}
+			p.LeftInStock = n
+		case "category_id":
+			if value != "" {
+				categoryID := value
+				p.CategoryID = &categoryID
+			}
+		default:
+			if value == "" {
+				continue
+			}
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return p, fmt.Errorf("%s: %q is not a number", name, value)
+			}
+			p.Prices = append(p.Prices, entity.Price{
+				Currency: strings.ToUpper(strings.TrimPrefix(name, csvPricePrefix)),
+				Price:    price,
+			})
+		}
+	}
+
+	return p, nil
+}
+
+func decodeNDJSON(r io.Reader) ([]ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	rows := []ImportRow{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		row := ImportRow{Line: line}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.Product); err != nil {
+			row.Err = fmt.Errorf("bad json: %w", err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// ExportEncoder writes products in the export format, output is buffered until Flush.
+type ExportEncoder interface {
+	Encode(product *entity.Product) error
+	Flush() error
+}
+
+// NewExportEncoder - currencies are columns of CSV prices.
+func NewExportEncoder(w io.Writer, format string, currencies []string) (ExportEncoder, error) {
+	switch format {
+	case FormatCSV:
+		enc := &csvEncoder{w: csv.NewWriter(w), currencies: currencies}
+		header := append([]string{}, csvBaseColumns...)
+		for _, c := range currencies {
+			header = append(header, csvPricePrefix+c)
+		}
+		if err := enc.w.Write(header); err != nil {
+			return nil, err
+		}
+		return enc, nil
+	case FormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonEncoder{w: bw, enc: json.NewEncoder(bw)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type csvEncoder struct {
+	w          *csv.Writer
+	currencies []string
+}
+
+func (e *csvEncoder) Encode(p *entity.Product) error {
+	record := make([]string, 0, len(csvBaseColumns)+len(e.currencies))
+	categoryID := ""
+	if p.CategoryID != nil {
+		categoryID = *p.CategoryID
+	}
+	record = append(record, p.Name, p.Description, strconv.Itoa(p.LeftInStock), categoryID)
+
+	for _, c := range e.currencies {
+		value := ""
+		for _, price := range p.Prices {
+			if price.Currency == c {
+				value = strconv.FormatFloat(price.Price, 'f', -1, 64)
+				break
+			}
+		}
+		record = append(record, value)
+	}
+
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(p *entity.Product) error {
+	return e.enc.Encode(p)
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return e.w.Flush()
+}
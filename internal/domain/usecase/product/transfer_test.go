@@ -0,0 +1,211 @@
+package product_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	mockProducts "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/product/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/product"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const categoryID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1"
+
+func TestDecodeImportCSV(t *testing.T) {
+	in := "name,description,left_in_stock,category_id,price_usd,price_EUR\n" +
+		"milk,fresh,10," + categoryID + ",1.05,\n" +
+		"bread,,x,,1,2\n" +
+		"only,two\n"
+
+	rows, err := product.DecodeImport(strings.NewReader(in), product.FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	require.NoError(t, rows[0].Err)
+	require.Equal(t, 2, rows[0].Line)
+	require.Equal(t, "milk", rows[0].Product.Name)
+	require.Equal(t, 10, rows[0].Product.LeftInStock)
+	require.Equal(t, categoryID, *rows[0].Product.CategoryID)
+	require.Equal(t, []entity.Price{{Currency: "USD", Price: 1.05}}, rows[0].Product.Prices)
+
+	require.Error(t, rows[1].Err)
+	require.Equal(t, 3, rows[1].Line)
+	require.Error(t, rows[2].Err)
+	require.Equal(t, 4, rows[2].Line)
+}
+
+func TestDecodeImportCSVUnknownColumn(t *testing.T) {
+	_, err := product.DecodeImport(strings.NewReader("name,color\nmilk,white\n"), product.FormatCSV)
+	require.Error(t, err)
+}
+
+func TestDecodeImportNDJSON(t *testing.T) {
+	in := `{"name":"milk","left_in_stock":1,"prices":[{"currency":"USD","price":1.05}]}` + "\n\n" +
+		`{"name":"bread","color":"white"}` + "\n" +
+		`{"name":`
+
+	rows, err := product.DecodeImport(strings.NewReader(in), product.FormatNDJSON)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	require.NoError(t, rows[0].Err)
+	require.Equal(t, "milk", rows[0].Product.Name)
+	require.Equal(t, 3, rows[1].Line)
+	require.Error(t, rows[1].Err)
+	require.Error(t, rows[2].Err)
+}
+
+func TestImportDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockProducts.NewMockRepository(ctrl)
+	repo.EXPECT().StoreWithPrices(gomock.Any(), gomock.Any()).Times(0)
+
+	rows := []product.ImportRow{
+		{Line: 1, Product: entity.Product{Name: "milk", LeftInStock: 1}},
+		{Line: 2, Product: entity.Product{Name: "bread", LeftInStock: 2}},
+	}
+
+	useCase := product.NewProductUseCase(repo, nil)
+	res, err := useCase.Import(context.Background(), rows, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, res.Valid)
+	require.False(t, res.Committed)
+	require.Empty(t, res.Errors)
+}
+
+func TestImportWithInvalidRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockProducts.NewMockRepository(ctrl)
+	repo.EXPECT().StoreWithPrices(gomock.Any(), gomock.Any()).Times(0)
+
+	rows := []product.ImportRow{
+		{Line: 2, Product: entity.Product{Name: "milk", LeftInStock: 1}},
+		{Line: 3, Product: entity.Product{LeftInStock: -1}},
+	}
+
+	useCase := product.NewProductUseCase(repo, nil)
+	res, err := useCase.Import(context.Background(), rows, false)
+	require.NoError(t, err)
+	require.False(t, res.Committed)
+	require.Equal(t, 1, res.Valid)
+	require.Len(t, res.Errors, 1)
+	require.Equal(t, 3, res.Errors[0].Row)
+}
+
+func TestImportWithoutUnitOfWork(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockProducts.NewMockRepository(ctrl)
+	repo.EXPECT().StoreWithPrices(gomock.Any(), gomock.Any()).Times(0)
+
+	rows := []product.ImportRow{{Line: 2, Product: entity.Product{Name: "milk", LeftInStock: 1}}}
+
+	useCase := product.NewProductUseCase(repo, nil)
+	res, err := useCase.Import(context.Background(), rows, false)
+	require.Error(t, err)
+	require.False(t, res.Committed)
+	require.Zero(t, res.Imported)
+}
+
+// fakeUnitOfWork runs fn on the same repositories, the transaction is emulated by the mocks.
+type fakeUnitOfWork struct {
+	repos repository.Repository
+}
+
+func (u fakeUnitOfWork) Do(ctx context.Context, fn func(repos repository.Repository) error) error {
+	return fn(u.repos)
+}
+
+func TestImportCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	rows := []product.ImportRow{
+		{Line: 2, Product: entity.Product{Name: "milk", LeftInStock: 1}},
+		{Line: 3, Product: entity.Product{Name: "bread", LeftInStock: 2}},
+	}
+
+	txRepo := mockProducts.NewMockRepository(ctrl)
+	gomock.InOrder(
+		txRepo.EXPECT().StoreWithPrices(ctx, &rows[0].Product).Return("id1", nil),
+		txRepo.EXPECT().StoreWithPrices(ctx, &rows[1].Product).Return("id2", nil),
+	)
+
+	useCase := product.NewProductUseCase(mockProducts.NewMockRepository(ctrl), fakeUnitOfWork{repos: repository.Repository{Products: txRepo}})
+	res, err := useCase.Import(ctx, rows, false)
+	require.NoError(t, err)
+	require.True(t, res.Committed)
+	require.Equal(t, 2, res.Imported)
+	require.Equal(t, []string{"id1", "id2"}, res.IDs)
+}
+
+func TestImportRollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	rows := []product.ImportRow{
+		{Line: 2, Product: entity.Product{Name: "milk", LeftInStock: 1}},
+		{Line: 3, Product: entity.Product{Name: "bread", LeftInStock: 2}},
+	}
+
+	txRepo := mockProducts.NewMockRepository(ctrl)
+	gomock.InOrder(
+		txRepo.EXPECT().StoreWithPrices(ctx, &rows[0].Product).Return("id1", nil),
+		txRepo.EXPECT().StoreWithPrices(ctx, &rows[1].Product).Return("", errs.HandleErrorDB(sql.ErrConnDone)),
+	)
+	repo := mockProducts.NewMockRepository(ctrl)
+	repo.EXPECT().StoreWithPrices(gomock.Any(), gomock.Any()).Times(0)
+
+	useCase := product.NewProductUseCase(repo, fakeUnitOfWork{repos: repository.Repository{Products: txRepo}})
+	res, err := useCase.Import(ctx, rows, false)
+	require.Error(t, err)
+	require.False(t, res.Committed)
+	require.Zero(t, res.Imported)
+	require.Empty(t, res.IDs)
+}
+
+func TestExportCSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	catID := categoryID
+
+	repo := mockProducts.NewMockRepository(ctrl)
+	repo.EXPECT().GetCurrencies(ctx).Return([]string{"EUR", "USD"}, nil)
+	repo.EXPECT().Export(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, fn func(*entity.Product) error) error {
+		if err := fn(&entity.Product{Name: "milk", Description: "fresh, 1l", LeftInStock: 1, CategoryID: &catID,
+			Prices: []entity.Price{{Currency: "USD", Price: 1.05}}}); err != nil {
+			return err
+		}
+		return fn(&entity.Product{Name: "bread", Prices: []entity.Price{}})
+	})
+
+	var out bytes.Buffer
+	useCase := product.NewProductUseCase(repo, nil)
+	require.NoError(t, useCase.Export(ctx, &out, product.FormatCSV))
+
+	expected := "name,description,left_in_stock,category_id,price_EUR,price_USD\n" +
+		`milk,"fresh, 1l",1,` + categoryID + ",,1.05\n" +
+		"bread,,0,,,\n"
+	require.Equal(t, expected, out.String())
+
+	// exported file can be imported back
+	rows, err := product.DecodeImport(&out, product.FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, "fresh, 1l", rows[0].Product.Description)
+}
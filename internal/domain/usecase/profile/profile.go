@@ -2,20 +2,37 @@ package profile
 
 import (
 	"context"
+	"errors"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/profile"
 )
 
-type UseCase struct {
+type UseCase interface {
+	GetByUserID(ctx context.Context, userID string) (*entity.Profile, error)
+	Create(ctx context.Context, profile entity.Profile) error
+	Remove(ctx context.Context, profile entity.Profile) error
+	// Update checks profile.Version if it's not 0 and returns the new version.
+	Update(ctx context.Context, profile entity.Profile) (int, error)
+}
+
+type useCase struct {
 	repo profile.Repository
 }
 
-func NewProfileUseCase(repo profile.Repository) *UseCase {
-	return &UseCase{repo: repo}
+func NewProfileUseCase(repo profile.Repository) UseCase {
+	return &useCase{repo: repo}
+}
+
+func (uc *useCase) GetByUserID(ctx context.Context, userID string) (*entity.Profile, error) {
+	res, err := uc.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from profile repo")
+	}
+	return res, nil
 }
 
-func (uc *UseCase) Create(ctx context.Context, profile entity.Profile) error {
+func (uc *useCase) Create(ctx context.Context, profile entity.Profile) error {
 	if err := profile.Validate(); err != nil {
 		return errs.NewErrorWrapper(errs.Validation, err, "profile validation error")
 	}
@@ -31,20 +48,24 @@ func (uc *UseCase) Create(ctx context.Context, profile entity.Profile) error {
 	return nil
 }
 
-func (uc *UseCase) Remove(ctx context.Context, profile entity.Profile) error {
+func (uc *useCase) Remove(ctx context.Context, profile entity.Profile) error {
 	if err := uc.repo.RemoveByUserID(ctx, profile.UserID); err != nil {
 		return errs.NewErrorWrapper(errs.Database, err, "error from profile repo")
 	}
 	return nil
 }
 
-func (uc *UseCase) Update(ctx context.Context, profile entity.Profile) error {
+func (uc *useCase) Update(ctx context.Context, profile entity.Profile) (int, error) {
 	if err := profile.Validate(); err != nil {
-		return errs.NewErrorWrapper(errs.Validation, err, "profile validation error")
+		return 0, errs.NewErrorWrapper(errs.Validation, err, "profile validation error")
 	}
 
-	if err := uc.repo.Update(ctx, &profile); err != nil {
-		return errs.NewErrorWrapper(errs.Database, err, "error from profile repo")
+	version, err := uc.repo.Update(ctx, &profile)
+	if err != nil {
+		if errors.Is(err, errs.VersionChanged) {
+			return 0, errs.NewErrorWrapper(errs.PreconditionFailed, err, "profile is changed by another request")
+		}
+		return 0, errs.NewErrorWrapper(errs.Database, err, "error from profile repo")
 	}
-	return nil
+	return version, nil
 }
@@ -0,0 +1,51 @@
+package tag
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/tag"
+)
+
+type UseCase interface {
+	GetByProductID(ctx context.Context, productID string) ([]string, error)
+	GetAll(ctx context.Context) ([]string, error)
+	// SetForProduct replaces tags of the product, returns normalized tags.
+	SetForProduct(ctx context.Context, productID string, input entity.ProductTagsInput) ([]string, error)
+}
+
+type useCase struct {
+	repo tag.Repository
+}
+
+func NewTagUseCase(repo tag.Repository) UseCase {
+	return &useCase{repo: repo}
+}
+
+func (uc *useCase) GetByProductID(ctx context.Context, productID string) ([]string, error) {
+	res, err := uc.repo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from tag repo")
+	}
+	return res, nil
+}
+
+func (uc *useCase) GetAll(ctx context.Context) ([]string, error) {
+	res, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from tag repo")
+	}
+	return res, nil
+}
+
+func (uc *useCase) SetForProduct(ctx context.Context, productID string, input entity.ProductTagsInput) ([]string, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, errs.NewErrorWrapper(errs.Validation, err, "tags validation error")
+	}
+
+	if err := uc.repo.SetForProduct(ctx, productID, input.Tags); err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from tag repo")
+	}
+	return input.Tags, nil
+}
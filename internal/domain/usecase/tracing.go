@@ -0,0 +1,441 @@
+package usecase
+
+import (
+	"context"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/category"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/idempotency"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/image"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/inventory"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/order"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/product"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/profile"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/tag"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/user"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/variant"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+)
+
+// WithTracing makes the span for every call of use cases, repositories and handlers have own spans.
+func WithTracing(ucs UseCases, tracer trace.Tracer) UseCases {
+	ucs.Users = usersWithTracing{UseCase: ucs.Users, tracer: tracer}
+	ucs.Profiles = profilesWithTracing{UseCase: ucs.Profiles, tracer: tracer}
+	ucs.Orders = ordersWithTracing{UseCase: ucs.Orders, tracer: tracer}
+	ucs.Products = productsWithTracing{UseCase: ucs.Products, tracer: tracer}
+	ucs.Inventory = inventoryWithTracing{UseCase: ucs.Inventory, tracer: tracer}
+	ucs.Categories = categoriesWithTracing{UseCase: ucs.Categories, tracer: tracer}
+	ucs.Tags = tagsWithTracing{UseCase: ucs.Tags, tracer: tracer}
+	ucs.Variants = variantsWithTracing{UseCase: ucs.Variants, tracer: tracer}
+	ucs.Images = imagesWithTracing{UseCase: ucs.Images, tracer: tracer}
+	ucs.Idempotency = idempotencyWithTracing{UseCase: ucs.Idempotency, tracer: tracer}
+	return ucs
+}
+
+type usersWithTracing struct {
+	user.UseCase
+	tracer trace.Tracer
+}
+
+func (uc usersWithTracing) GetUserIfCredentialsValid(ctx context.Context, username, password string) (*entity.User, error) {
+	ctx, span := uc.tracer.Start(ctx, "user.GetUserIfCredentialsValid")
+	res, err := uc.UseCase.GetUserIfCredentialsValid(ctx, username, password)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc usersWithTracing) Create(ctx context.Context, user entity.User) (string, error) {
+	ctx, span := uc.tracer.Start(ctx, "user.Create")
+	res, err := uc.UseCase.Create(ctx, user)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc usersWithTracing) Remove(ctx context.Context, user entity.User) error {
+	ctx, span := uc.tracer.Start(ctx, "user.Remove")
+	err := uc.UseCase.Remove(ctx, user)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc usersWithTracing) Update(ctx context.Context, user entity.User) error {
+	ctx, span := uc.tracer.Start(ctx, "user.Update")
+	err := uc.UseCase.Update(ctx, user)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc usersWithTracing) AddFollower(ctx context.Context, follower entity.Follower) error {
+	ctx, span := uc.tracer.Start(ctx, "user.AddFollower")
+	err := uc.UseCase.AddFollower(ctx, follower)
+	tracing.End(span, err)
+	return err
+}
+
+type profilesWithTracing struct {
+	profile.UseCase
+	tracer trace.Tracer
+}
+
+func (uc profilesWithTracing) GetByUserID(ctx context.Context, userID string) (*entity.Profile, error) {
+	ctx, span := uc.tracer.Start(ctx, "profile.GetByUserID")
+	res, err := uc.UseCase.GetByUserID(ctx, userID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc profilesWithTracing) Create(ctx context.Context, profile entity.Profile) error {
+	ctx, span := uc.tracer.Start(ctx, "profile.Create")
+	err := uc.UseCase.Create(ctx, profile)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc profilesWithTracing) Remove(ctx context.Context, profile entity.Profile) error {
+	ctx, span := uc.tracer.Start(ctx, "profile.Remove")
+	err := uc.UseCase.Remove(ctx, profile)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc profilesWithTracing) Update(ctx context.Context, profile entity.Profile) (int, error) {
+	ctx, span := uc.tracer.Start(ctx, "profile.Update")
+	res, err := uc.UseCase.Update(ctx, profile)
+	tracing.End(span, err)
+	return res, err
+}
+
+type ordersWithTracing struct {
+	order.UseCase
+	tracer trace.Tracer
+}
+
+func (uc ordersWithTracing) GetByID(ctx context.Context, orderID string) (*entity.Order, error) {
+	ctx, span := uc.tracer.Start(ctx, "order.GetByID")
+	res, err := uc.UseCase.GetByID(ctx, orderID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc ordersWithTracing) GetAllByUserID(ctx context.Context, userID string) (*[]entity.Order, error) {
+	ctx, span := uc.tracer.Start(ctx, "order.GetAllByUserID")
+	res, err := uc.UseCase.GetAllByUserID(ctx, userID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc ordersWithTracing) GetAllOrderProducts(ctx context.Context, orderID string) (*[]entity.OrderProductView, error) {
+	ctx, span := uc.tracer.Start(ctx, "order.GetAllOrderProducts")
+	res, err := uc.UseCase.GetAllOrderProducts(ctx, orderID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc ordersWithTracing) Create(ctx context.Context, order entity.Order) (string, bool, error) {
+	ctx, span := uc.tracer.Start(ctx, "order.Create")
+	res, created, err := uc.UseCase.Create(ctx, order)
+	tracing.End(span, err)
+	return res, created, err
+}
+
+func (uc ordersWithTracing) AddProduct(ctx context.Context, p *entity.Product, v *entity.Variant, op *entity.OrderProduct) error {
+	ctx, span := uc.tracer.Start(ctx, "order.AddProduct")
+	err := uc.UseCase.AddProduct(ctx, p, v, op)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc ordersWithTracing) Remove(ctx context.Context, id string, version int) error {
+	ctx, span := uc.tracer.Start(ctx, "order.Remove")
+	err := uc.UseCase.Remove(ctx, id, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc ordersWithTracing) Update(ctx context.Context, order entity.Order) (int, error) {
+	ctx, span := uc.tracer.Start(ctx, "order.Update")
+	res, err := uc.UseCase.Update(ctx, order)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc ordersWithTracing) RemoveProduct(ctx context.Context, orderID, productID string, variantID *string) error {
+	ctx, span := uc.tracer.Start(ctx, "order.RemoveProduct")
+	err := uc.UseCase.RemoveProduct(ctx, orderID, productID, variantID)
+	tracing.End(span, err)
+	return err
+}
+
+type productsWithTracing struct {
+	product.UseCase
+	tracer trace.Tracer
+}
+
+func (uc productsWithTracing) GetByID(ctx context.Context, productID string) (*entity.Product, error) {
+	ctx, span := uc.tracer.Start(ctx, "product.GetByID")
+	res, err := uc.UseCase.GetByID(ctx, productID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc productsWithTracing) GetAll(ctx context.Context, filter entity.ProductFilter) (*[]entity.Product, error) {
+	ctx, span := uc.tracer.Start(ctx, "product.GetAll")
+	res, err := uc.UseCase.GetAll(ctx, filter)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc productsWithTracing) Create(ctx context.Context, product entity.Product) (string, error) {
+	ctx, span := uc.tracer.Start(ctx, "product.Create")
+	res, err := uc.UseCase.Create(ctx, product)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc productsWithTracing) CreateWithPrices(ctx context.Context, product entity.Product) (string, error) {
+	ctx, span := uc.tracer.Start(ctx, "product.CreateWithPrices")
+	res, err := uc.UseCase.CreateWithPrices(ctx, product)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc productsWithTracing) Remove(ctx context.Context, id string, version int) error {
+	ctx, span := uc.tracer.Start(ctx, "product.Remove")
+	err := uc.UseCase.Remove(ctx, id, version)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc productsWithTracing) Archive(ctx context.Context, id string) error {
+	ctx, span := uc.tracer.Start(ctx, "product.Archive")
+	err := uc.UseCase.Archive(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc productsWithTracing) Restore(ctx context.Context, id string) error {
+	ctx, span := uc.tracer.Start(ctx, "product.Restore")
+	err := uc.UseCase.Restore(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc productsWithTracing) Update(ctx context.Context, id string, input entity.ProductUpdateInput, version int) (int, error) {
+	ctx, span := uc.tracer.Start(ctx, "product.Update")
+	res, err := uc.UseCase.Update(ctx, id, input, version)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc productsWithTracing) Import(ctx context.Context, rows []product.ImportRow, dryRun bool) (*entity.ProductImportResult, error) {
+	ctx, span := uc.tracer.Start(ctx, "product.Import")
+	res, err := uc.UseCase.Import(ctx, rows, dryRun)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc productsWithTracing) Export(ctx context.Context, w io.Writer, format string) error {
+	ctx, span := uc.tracer.Start(ctx, "product.Export")
+	err := uc.UseCase.Export(ctx, w, format)
+	tracing.End(span, err)
+	return err
+}
+
+type inventoryWithTracing struct {
+	inventory.UseCase
+	tracer trace.Tracer
+}
+
+func (uc inventoryWithTracing) GetMovements(ctx context.Context, productID string) (*[]entity.StockMovement, error) {
+	ctx, span := uc.tracer.Start(ctx, "inventory.GetMovements")
+	res, err := uc.UseCase.GetMovements(ctx, productID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc inventoryWithTracing) Adjust(ctx context.Context, productID, actorID string, input entity.StockAdjustmentInput) (*entity.StockMovement, error) {
+	ctx, span := uc.tracer.Start(ctx, "inventory.Adjust")
+	res, err := uc.UseCase.Adjust(ctx, productID, actorID, input)
+	tracing.End(span, err)
+	return res, err
+}
+
+type categoriesWithTracing struct {
+	category.UseCase
+	tracer trace.Tracer
+}
+
+func (uc categoriesWithTracing) GetByID(ctx context.Context, id string) (*entity.Category, error) {
+	ctx, span := uc.tracer.Start(ctx, "category.GetByID")
+	res, err := uc.UseCase.GetByID(ctx, id)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc categoriesWithTracing) GetAll(ctx context.Context) (*[]entity.Category, error) {
+	ctx, span := uc.tracer.Start(ctx, "category.GetAll")
+	res, err := uc.UseCase.GetAll(ctx)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc categoriesWithTracing) GetSubtreeIDs(ctx context.Context, idOrSlug string) ([]string, error) {
+	ctx, span := uc.tracer.Start(ctx, "category.GetSubtreeIDs")
+	res, err := uc.UseCase.GetSubtreeIDs(ctx, idOrSlug)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc categoriesWithTracing) Create(ctx context.Context, category entity.Category) (string, error) {
+	ctx, span := uc.tracer.Start(ctx, "category.Create")
+	res, err := uc.UseCase.Create(ctx, category)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc categoriesWithTracing) Update(ctx context.Context, category entity.Category) error {
+	ctx, span := uc.tracer.Start(ctx, "category.Update")
+	err := uc.UseCase.Update(ctx, category)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc categoriesWithTracing) Remove(ctx context.Context, id string) error {
+	ctx, span := uc.tracer.Start(ctx, "category.Remove")
+	err := uc.UseCase.Remove(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+type tagsWithTracing struct {
+	tag.UseCase
+	tracer trace.Tracer
+}
+
+func (uc tagsWithTracing) GetByProductID(ctx context.Context, productID string) ([]string, error) {
+	ctx, span := uc.tracer.Start(ctx, "tag.GetByProductID")
+	res, err := uc.UseCase.GetByProductID(ctx, productID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc tagsWithTracing) GetAll(ctx context.Context) ([]string, error) {
+	ctx, span := uc.tracer.Start(ctx, "tag.GetAll")
+	res, err := uc.UseCase.GetAll(ctx)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc tagsWithTracing) SetForProduct(ctx context.Context, productID string, input entity.ProductTagsInput) ([]string, error) {
+	ctx, span := uc.tracer.Start(ctx, "tag.SetForProduct")
+	res, err := uc.UseCase.SetForProduct(ctx, productID, input)
+	tracing.End(span, err)
+	return res, err
+}
+
+type variantsWithTracing struct {
+	variant.UseCase
+	tracer trace.Tracer
+}
+
+func (uc variantsWithTracing) GetByID(ctx context.Context, variantID string) (*entity.Variant, error) {
+	ctx, span := uc.tracer.Start(ctx, "variant.GetByID")
+	res, err := uc.UseCase.GetByID(ctx, variantID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc variantsWithTracing) GetByProductID(ctx context.Context, productID, variantID string) (*entity.Variant, error) {
+	ctx, span := uc.tracer.Start(ctx, "variant.GetByProductID")
+	res, err := uc.UseCase.GetByProductID(ctx, productID, variantID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc variantsWithTracing) GetAllByProductID(ctx context.Context, productID string) (*[]entity.Variant, error) {
+	ctx, span := uc.tracer.Start(ctx, "variant.GetAllByProductID")
+	res, err := uc.UseCase.GetAllByProductID(ctx, productID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc variantsWithTracing) Create(ctx context.Context, variant entity.Variant) (string, error) {
+	ctx, span := uc.tracer.Start(ctx, "variant.Create")
+	res, err := uc.UseCase.Create(ctx, variant)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc variantsWithTracing) Update(ctx context.Context, id string, input entity.VariantUpdateInput) error {
+	ctx, span := uc.tracer.Start(ctx, "variant.Update")
+	err := uc.UseCase.Update(ctx, id, input)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc variantsWithTracing) SetPrice(ctx context.Context, id string, price entity.Price) error {
+	ctx, span := uc.tracer.Start(ctx, "variant.SetPrice")
+	err := uc.UseCase.SetPrice(ctx, id, price)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc variantsWithTracing) Remove(ctx context.Context, id string) error {
+	ctx, span := uc.tracer.Start(ctx, "variant.Remove")
+	err := uc.UseCase.Remove(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+type imagesWithTracing struct {
+	image.UseCase
+	tracer trace.Tracer
+}
+
+func (uc imagesWithTracing) GetAllByProductID(ctx context.Context, productID string) (*[]entity.ProductImage, error) {
+	ctx, span := uc.tracer.Start(ctx, "image.GetAllByProductID")
+	res, err := uc.UseCase.GetAllByProductID(ctx, productID)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc imagesWithTracing) Upload(ctx context.Context, productID, contentType string, data []byte) (*entity.ProductImage, error) {
+	ctx, span := uc.tracer.Start(ctx, "image.Upload")
+	res, err := uc.UseCase.Upload(ctx, productID, contentType, data)
+	tracing.End(span, err)
+	return res, err
+}
+
+type idempotencyWithTracing struct {
+	idempotency.UseCase
+	tracer trace.Tracer
+}
+
+func (uc idempotencyWithTracing) Begin(ctx context.Context, userID, key, requestHash string) (*entity.IdempotencyRecord, error) {
+	ctx, span := uc.tracer.Start(ctx, "idempotency.Begin")
+	res, err := uc.UseCase.Begin(ctx, userID, key, requestHash)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (uc idempotencyWithTracing) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	ctx, span := uc.tracer.Start(ctx, "idempotency.Complete")
+	err := uc.UseCase.Complete(ctx, record)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc idempotencyWithTracing) Release(ctx context.Context, userID, key string) error {
+	ctx, span := uc.tracer.Start(ctx, "idempotency.Release")
+	err := uc.UseCase.Release(ctx, userID, key)
+	tracing.End(span, err)
+	return err
+}
+
+func (uc idempotencyWithTracing) RemoveExpired(ctx context.Context) (int64, error) {
+	ctx, span := uc.tracer.Start(ctx, "idempotency.RemoveExpired")
+	res, err := uc.UseCase.RemoveExpired(ctx)
+	tracing.End(span, err)
+	return res, err
+}
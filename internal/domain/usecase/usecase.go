@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/service"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/category"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/idempotency"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/image"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/inventory"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/order"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/product"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/profile"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/tag"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/user"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/variant"
+	"github.com/linkuha/test-golang-rest-orders-api/pkg/storage"
+	"time"
+)
+
+// UseCases are built once at startup and shared by handlers. Fields are interfaces,
+// so any of them can be decorated (logging, metrics, caching) or mocked in tests.
+type UseCases struct {
+	Users       user.UseCase
+	Profiles    profile.UseCase
+	Orders      order.UseCase
+	Products    product.UseCase
+	Inventory   inventory.UseCase
+	Categories  category.UseCase
+	Tags        tag.UseCase
+	Variants    variant.UseCase
+	Images      image.UseCase
+	Idempotency idempotency.UseCase
+	Tokens      service.TokenManager
+}
+
+// Deps are dependencies of use cases besides repositories.
+type Deps struct {
+	// Storage of uploaded files, uploads fail without it.
+	Storage        storage.Storage
+	Encryptor      service.PasswordEncryptor
+	Tokens         service.TokenManager
+	IdempotencyTTL time.Duration
+}
+
+func NewUseCases(repos repository.Repository, deps Deps) UseCases {
+	return UseCases{
+		Users:       user.NewUserUseCase(repos.Users, deps.Encryptor),
+		Profiles:    profile.NewProfileUseCase(repos.Profiles),
+		Orders:      order.NewOrderUseCase(repos.Orders, repos.UnitOfWork),
+		Products:    product.NewProductUseCase(repos.Products, repos.UnitOfWork),
+		Inventory:   inventory.NewInventoryUseCase(repos.Inventory),
+		Categories:  category.NewCategoryUseCase(repos.Categories),
+		Tags:        tag.NewTagUseCase(repos.Tags),
+		Variants:    variant.NewVariantUseCase(repos.Variants),
+		Images:      image.NewImageUseCase(repos.Images, deps.Storage),
+		Idempotency: idempotency.NewIdempotencyUseCase(repos.Idempotency, deps.IdempotencyTTL),
+		Tokens:      deps.Tokens,
+	}
+}
@@ -9,16 +9,24 @@ import (
 	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/service"
 )
 
-type UseCase struct {
+type UseCase interface {
+	GetUserIfCredentialsValid(ctx context.Context, username, password string) (*entity.User, error)
+	Create(ctx context.Context, user entity.User) (string, error)
+	Remove(ctx context.Context, user entity.User) error
+	Update(ctx context.Context, user entity.User) error
+	AddFollower(ctx context.Context, follower entity.Follower) error
+}
+
+type useCase struct {
 	repo      user.Repository
 	encryptor service.PasswordEncryptor
 }
 
-func NewUserUseCase(repo user.Repository, encryptor service.PasswordEncryptor) *UseCase {
-	return &UseCase{repo, encryptor}
+func NewUserUseCase(repo user.Repository, encryptor service.PasswordEncryptor) UseCase {
+	return &useCase{repo, encryptor}
 }
 
-func (uc *UseCase) GetUserIfCredentialsValid(ctx context.Context, username, password string) (*entity.User, error) {
+func (uc *useCase) GetUserIfCredentialsValid(ctx context.Context, username, password string) (*entity.User, error) {
 	u, err := uc.repo.GetByUsername(ctx, username)
 	if err != nil {
 		return nil, errs.NewErrorWrapper(errs.Database, err, "error from user repo")
@@ -30,7 +38,7 @@ func (uc *UseCase) GetUserIfCredentialsValid(ctx context.Context, username, pass
 	return u, nil
 }
 
-func (uc *UseCase) Create(ctx context.Context, user entity.User) (string, error) {
+func (uc *useCase) Create(ctx context.Context, user entity.User) (string, error) {
 	if err := user.Validate(); err != nil {
 		return "", errs.NewErrorWrapper(errs.Validation, err, "user validation error")
 	}
@@ -51,14 +59,14 @@ func (uc *UseCase) Create(ctx context.Context, user entity.User) (string, error)
 	return res, nil
 }
 
-func (uc *UseCase) Remove(ctx context.Context, user entity.User) error {
+func (uc *useCase) Remove(ctx context.Context, user entity.User) error {
 	if err := uc.repo.Remove(ctx, user.ID); err != nil {
 		return errs.NewErrorWrapper(errs.Database, err, "error from user repo")
 	}
 	return nil
 }
 
-func (uc *UseCase) Update(ctx context.Context, user entity.User) error {
+func (uc *useCase) Update(ctx context.Context, user entity.User) error {
 	if err := user.Validate(); err != nil {
 		return errs.NewErrorWrapper(errs.Validation, err, "user validation error")
 	}
@@ -72,7 +80,7 @@ func (uc *UseCase) Update(ctx context.Context, user entity.User) error {
 	return nil
 }
 
-func (uc *UseCase) AddFollower(ctx context.Context, follower entity.Follower) error {
+func (uc *useCase) AddFollower(ctx context.Context, follower entity.Follower) error {
 	if err := follower.Validate(); err != nil {
 		return errs.NewErrorWrapper(errs.Validation, err, "follower validation error")
 	}
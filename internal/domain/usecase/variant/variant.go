@@ -0,0 +1,112 @@
+package variant
+
+import (
+	"context"
+	"errors"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/variant"
+)
+
+type UseCase interface {
+	GetByID(ctx context.Context, variantID string) (*entity.Variant, error)
+	// GetByProductID returns the variant only if it belongs to the product.
+	GetByProductID(ctx context.Context, productID, variantID string) (*entity.Variant, error)
+	GetAllByProductID(ctx context.Context, productID string) (*[]entity.Variant, error)
+	Create(ctx context.Context, variant entity.Variant) (string, error)
+	Update(ctx context.Context, id string, input entity.VariantUpdateInput) error
+	// SetPrice adds the price in the currency or replaces it.
+	SetPrice(ctx context.Context, id string, price entity.Price) error
+	Remove(ctx context.Context, id string) error
+}
+
+type useCase struct {
+	repo variant.Repository
+}
+
+func NewVariantUseCase(repo variant.Repository) UseCase {
+	return &useCase{repo: repo}
+}
+
+func (uc *useCase) GetByID(ctx context.Context, variantID string) (*entity.Variant, error) {
+	res, err := uc.repo.Get(ctx, variantID)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from variant repo")
+	}
+	prices, err := uc.repo.GetPrices(ctx, variantID)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from variant repo")
+	}
+	res.Prices = *prices
+	return res, nil
+}
+
+func (uc *useCase) GetByProductID(ctx context.Context, productID, variantID string) (*entity.Variant, error) {
+	res, err := uc.GetByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if res.ProductID != productID {
+		return nil, errs.NewErrorWrapper(errs.NotExist, errs.RecordNotFound, "variant of product not found")
+	}
+	return res, nil
+}
+
+func (uc *useCase) GetAllByProductID(ctx context.Context, productID string) (*[]entity.Variant, error) {
+	res, err := uc.repo.GetAllByProductID(ctx, productID)
+	if err != nil {
+		return nil, errs.NewErrorWrapper(errs.Database, err, "error from variant repo")
+	}
+	for i := 0; i < len(*res); i++ {
+		prices, err := uc.repo.GetPrices(ctx, (*res)[i].ID)
+		if err != nil {
+			return nil, errs.NewErrorWrapper(errs.Database, err, "error from variant repo")
+		}
+		(*res)[i].Prices = *prices
+	}
+	return res, nil
+}
+
+func (uc *useCase) Create(ctx context.Context, variant entity.Variant) (string, error) {
+	if err := variant.Validate(); err != nil {
+		return "", errs.NewErrorWrapper(errs.Validation, err, "variant validation error")
+	}
+
+	res, err := uc.repo.StoreWithPrices(ctx, &variant)
+	if err != nil {
+		return "", errs.NewErrorWrapper(errs.Database, err, "error from variant repo")
+	}
+	return res, nil
+}
+
+func (uc *useCase) Update(ctx context.Context, id string, input entity.VariantUpdateInput) error {
+	if err := input.Validate(); err != nil {
+		return errs.NewErrorWrapper(errs.Validation, err, "variant validation error")
+	}
+
+	if err := uc.repo.Update(ctx, id, &input); err != nil {
+		return errs.NewErrorWrapper(errs.Database, err, "error from variant repo")
+	}
+	return nil
+}
+
+func (uc *useCase) SetPrice(ctx context.Context, id string, price entity.Price) error {
+	if err := price.Validate(); err != nil {
+		return errs.NewErrorWrapper(errs.Validation, err, "price validation error")
+	}
+
+	if err := uc.repo.AddPrice(ctx, id, &price); err != nil {
+		return errs.NewErrorWrapper(errs.Database, err, "error from variant repo")
+	}
+	return nil
+}
+
+func (uc *useCase) Remove(ctx context.Context, id string) error {
+	if err := uc.repo.Remove(ctx, id); err != nil {
+		if errors.Is(err, errs.RecordInUse) {
+			return errs.NewErrorWrapper(errs.Logic, err, "variant is used in orders")
+		}
+		return errs.NewErrorWrapper(errs.Database, err, "error from variant repo")
+	}
+	return nil
+}
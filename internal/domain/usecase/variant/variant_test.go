@@ -0,0 +1,105 @@
+package variant_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/entity"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/errs"
+	mockVariant "github.com/linkuha/test-golang-rest-orders-api/internal/domain/repository/variant/mocks"
+	"github.com/linkuha/test-golang-rest-orders-api/internal/domain/usecase/variant"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	productID      = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe1"
+	otherProductID = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe2"
+	variantID      = "c401f9dc-1e68-4b44-82d9-3a93b09e3fe7"
+)
+
+func TestGetByProductIDWithPrices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	prices := []entity.Price{{Currency: "USD", Price: 9.99}}
+
+	repo := mockVariant.NewMockRepository(ctrl)
+	repo.EXPECT().Get(ctx, variantID).Return(&entity.Variant{ID: variantID, ProductID: productID, SKU: "SKU-1"}, nil).Times(1)
+	repo.EXPECT().GetPrices(ctx, variantID).Return(&prices, nil).Times(1)
+
+	useCase := variant.NewVariantUseCase(repo)
+	v, err := useCase.GetByProductID(ctx, productID, variantID)
+	require.NoError(t, err)
+	require.Equal(t, prices, v.Prices)
+}
+
+func TestGetByProductIDOfOtherProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	repo := mockVariant.NewMockRepository(ctrl)
+	repo.EXPECT().Get(ctx, variantID).Return(&entity.Variant{ID: variantID, ProductID: otherProductID, SKU: "SKU-1"}, nil).Times(1)
+	repo.EXPECT().GetPrices(ctx, variantID).Return(&[]entity.Price{}, nil).Times(1)
+
+	useCase := variant.NewVariantUseCase(repo)
+	_, err := useCase.GetByProductID(ctx, productID, variantID)
+	require.Error(t, err)
+	var tmp errs.CustomErrorWrapper
+	errors.As(err, &tmp)
+	require.Equal(t, errs.NotExist, tmp.Code)
+}
+
+func TestCreateValidationError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	repo := mockVariant.NewMockRepository(ctrl)
+	repo.EXPECT().StoreWithPrices(gomock.Any(), gomock.Any()).Times(0)
+
+	useCase := variant.NewVariantUseCase(repo)
+	_, err := useCase.Create(ctx, entity.Variant{ProductID: productID, SKU: "bad sku"})
+	require.Error(t, err)
+	var tmp errs.CustomErrorWrapper
+	errors.As(err, &tmp)
+	require.Equal(t, errs.Validation, tmp.Code)
+}
+
+func TestRemoveOrdered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	repo := mockVariant.NewMockRepository(ctrl)
+	repo.EXPECT().Remove(ctx, variantID).
+		Return(errs.NewErrorWrapper(errs.Logic, errs.RecordInUse, "variant is used in orders")).Times(1)
+
+	useCase := variant.NewVariantUseCase(repo)
+	err := useCase.Remove(ctx, variantID)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, errs.RecordInUse))
+}
+
+func TestSetPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	repo := mockVariant.NewMockRepository(ctrl)
+	repo.EXPECT().AddPrice(ctx, variantID, &entity.Price{Currency: "USD", Price: 9.99}).Return(nil).Times(1)
+
+	useCase := variant.NewVariantUseCase(repo)
+	require.NoError(t, useCase.SetPrice(ctx, variantID, entity.Price{Currency: "USD", Price: 9.99}))
+
+	err := useCase.SetPrice(ctx, variantID, entity.Price{Currency: "dollar", Price: 1})
+	var tmp errs.CustomErrorWrapper
+	require.True(t, errors.As(err, &tmp))
+	require.Equal(t, errs.Validation, tmp.Code)
+}
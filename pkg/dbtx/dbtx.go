@@ -0,0 +1,92 @@
+// Package dbtx lets the same repository code run on the connection pool or inside a transaction.
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Querier is the part of *sql.DB and *sql.Tx used by repositories.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// DB is the connection pool or the transaction. BeginTx inside the transaction makes a savepoint,
+// so the code with its own transaction becomes the part of the outer one.
+type DB interface {
+	Querier
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+}
+
+type Tx interface {
+	DB
+	Commit() error
+	Rollback() error
+}
+
+// New wraps the connection pool.
+func New(db *sql.DB) DB {
+	return &pool{DB: db}
+}
+
+// IsTx reports whether db is already the transaction.
+func IsTx(db DB) bool {
+	_, ok := db.(Tx)
+	return ok
+}
+
+type pool struct {
+	*sql.DB
+}
+
+func (p *pool) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction{Tx: tx}, nil
+}
+
+type transaction struct {
+	*sql.Tx
+	savepoints int
+}
+
+// BeginTx creates the savepoint, options are ignored: isolation is set by the outer transaction.
+func (t *transaction) BeginTx(ctx context.Context, _ *sql.TxOptions) (Tx, error) {
+	t.savepoints++
+	name := fmt.Sprintf("sp_%d", t.savepoints)
+	if _, err := t.Tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &savepoint{transaction: t, ctx: ctx, name: name}, nil
+}
+
+type savepoint struct {
+	*transaction
+	ctx  context.Context
+	name string
+	done bool
+}
+
+func (s *savepoint) Commit() error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	_, err := s.Tx.ExecContext(s.ctx, "RELEASE SAVEPOINT "+s.name)
+	return err
+}
+
+func (s *savepoint) Rollback() error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	_, err := s.Tx.ExecContext(s.ctx, "ROLLBACK TO SAVEPOINT "+s.name)
+	return err
+}
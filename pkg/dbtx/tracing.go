@@ -0,0 +1,98 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// WithTracing makes the span for every query, transactions begun from db are traced too.
+// Arguments of queries aren't recorded, they may be personal data.
+func WithTracing(db DB, tracer trace.Tracer) DB {
+	return &tracedDB{DB: db, tracer: tracer}
+}
+
+type tracedDB struct {
+	DB
+	tracer trace.Tracer
+}
+
+func (d *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := d.start(ctx, query)
+	res, err := d.DB.ExecContext(ctx, query, args...)
+	end(span, err)
+	return res, err
+}
+
+// QueryContext - the span doesn't include reading of rows.
+func (d *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := d.start(ctx, query)
+	rows, err := d.DB.QueryContext(ctx, query, args...)
+	end(span, err)
+	return rows, err
+}
+
+func (d *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := d.start(ctx, query)
+	row := d.DB.QueryRowContext(ctx, query, args...)
+	end(span, row.Err())
+	return row
+}
+
+func (d *tracedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := d.start(ctx, query)
+	stmt, err := d.DB.PrepareContext(ctx, query)
+	end(span, err)
+	return stmt, err
+}
+
+func (d *tracedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	ctx, span := d.start(ctx, "BEGIN")
+	tx, err := d.DB.BeginTx(ctx, opts)
+	end(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{tracedDB: tracedDB{DB: tx, tracer: d.tracer}, tx: tx}, nil
+}
+
+type tracedTx struct {
+	tracedDB
+	tx Tx
+}
+
+func (t *tracedTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *tracedTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (d *tracedDB) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := query
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	return d.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			attribute.String(string(semconv.DBStatementKey), query),
+		),
+	)
+}
+
+func end(span trace.Span, err error) {
+	// no rows is the answer, not the failure
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	c := New(0)
+	c.Add("database", func(ctx context.Context) error { return nil })
+	require.Equal(t, Report{Status: StatusOK, Checks: map[string]string{"database": StatusOK}}, c.Ready(context.Background()))
+
+	c.Add("migrations", func(ctx context.Context) error { return errors.New("schema version 1 is older than expected 2") })
+	require.Equal(t, Report{Status: StatusFail, Checks: map[string]string{
+		"database":   StatusOK,
+		"migrations": "schema version 1 is older than expected 2",
+	}}, c.Ready(context.Background()))
+}
+
+func TestReadyTimeout(t *testing.T) {
+	c := New(50 * time.Millisecond)
+	c.Add("database", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	report := c.Ready(context.Background())
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, StatusFail, report.Status)
+	require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"])
+}
+
+func TestReadyShutdown(t *testing.T) {
+	c := New(0)
+	c.Add("database", func(ctx context.Context) error { return nil })
+	require.Equal(t, StatusOK, c.Ready(context.Background()).Status)
+
+	c.Shutdown()
+	require.True(t, c.ShuttingDown())
+	require.Equal(t, StatusFail, c.Ready(context.Background()).Status)
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Monitor runs the check in background, readiness gets its last result without waiting for the dependency.
+// The dependency is considered available until the first check fails.
+type Monitor struct {
+	check    Check
+	interval time.Duration
+	timeout  time.Duration
+	onChange func(err error)
+
+	mu  sync.RWMutex
+	err error
+}
+
+// NewMonitor - onChange is called, when the dependency is lost (err) or restored (nil); it may be nil.
+func NewMonitor(check Check, interval, timeout time.Duration, onChange func(err error)) *Monitor {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Monitor{check: check, interval: interval, timeout: timeout, onChange: onChange}
+}
+
+// Run checks every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.run(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	err := m.check(ctx)
+	cancel()
+
+	m.mu.Lock()
+	changed := (err == nil) != (m.err == nil)
+	m.err = err
+	m.mu.Unlock()
+
+	if changed && m.onChange != nil {
+		m.onChange(err)
+	}
+}
+
+// Check returns the last result, it's the Check of the Checker.
+func (m *Monitor) Check(context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.err
+}
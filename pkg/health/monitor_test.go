@@ -0,0 +1,34 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMonitor(t *testing.T) {
+	var (
+		pingErr error
+		changes []error
+	)
+	m := NewMonitor(func(ctx context.Context) error { return pingErr }, 0, 0, func(err error) {
+		changes = append(changes, err)
+	})
+	require.NoError(t, m.Check(context.Background()), "available before the first check")
+
+	m.run(context.Background())
+	require.Empty(t, changes)
+
+	lost := errors.New("connection refused")
+	pingErr = lost
+	m.run(context.Background())
+	m.run(context.Background())
+	require.Equal(t, lost, m.Check(context.Background()))
+	require.Equal(t, []error{lost}, changes, "the change is reported once")
+
+	pingErr = nil
+	m.run(context.Background())
+	require.NoError(t, m.Check(context.Background()))
+	require.Equal(t, []error{lost, nil}, changes)
+}
@@ -1,38 +1,167 @@
 package logger
 
 import (
+	"fmt"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/natefinch/lumberjack.v2"
 	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
-func InitLogger(logLvl string, customLog io.Writer) {
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+type Config struct {
+	// Level - trace, debug, info, warn or error; info by default.
+	Level string
+	// Format of stdout - FormatJSON (by default) or FormatConsole.
+	Format string
+	// File is written besides stdout, if its Path is set.
+	File FileConfig
+}
+
+type FileConfig struct {
+	Path string
+	// Format - FormatJSON (by default) or FormatConsole without colors.
+	Format string
+	// MaxSize in megabytes of the file before it's rotated, 0 - it isn't rotated by size.
+	MaxSize int
+	// MaxBackups - number of rotated files to keep, all by default.
+	MaxBackups int
+	// MaxAge in days of rotated files to keep, they aren't removed by age by default.
+	MaxAge int
+	// RotateEvery - the file is rotated by time too, if it's set.
+	RotateEvery time.Duration
+	Compress    bool
+}
+
+// Output owns the log file. Close it after the last line.
+type Output struct {
+	file *lumberjack.Logger
+	stop chan struct{}
+	once sync.Once
+}
+
+// Init sets the global logger, log.Ctx of the context without logger returns it too.
+func Init(cfg Config) (*Output, error) {
+	for _, format := range []string{cfg.Format, cfg.File.Format} {
+		if format != "" && format != FormatJSON && format != FormatConsole {
+			return nil, fmt.Errorf("unknown log format %q", format)
+		}
+	}
+
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	zerolog.SetGlobalLevel(parseLevel(cfg.Level))
 
-	var l zerolog.Level
-	switch strings.ToLower(logLvl) {
-	case "error":
-		l = zerolog.ErrorLevel
-	case "warn":
-		l = zerolog.WarnLevel
-	case "info":
-		l = zerolog.InfoLevel
-	case "debug":
-		l = zerolog.DebugLevel
-	default:
-		l = zerolog.InfoLevel
+	out := &Output{stop: make(chan struct{})}
+	var w io.Writer = formatWriter(os.Stdout, cfg.Format, true)
+
+	if cfg.File.Path != "" {
+		// lumberjack opens the file on the first line, but it's better to fail on start
+		if err := checkWritable(cfg.File.Path); err != nil {
+			return nil, err
+		}
+		// lumberjack takes 0 as its default of 100 MB
+		maxSize := cfg.File.MaxSize
+		if maxSize == 0 {
+			maxSize = math.MaxInt32
+		}
+		out.file = &lumberjack.Logger{
+			Filename:   cfg.File.Path,
+			MaxSize:    maxSize,
+			MaxBackups: cfg.File.MaxBackups,
+			MaxAge:     cfg.File.MaxAge,
+			Compress:   cfg.File.Compress,
+			LocalTime:  true,
+		}
+		w = zerolog.MultiLevelWriter(w, formatWriter(out.file, cfg.File.Format, false))
+
+		if cfg.File.RotateEvery > 0 {
+			go out.rotateEvery(cfg.File.RotateEvery)
+		}
 	}
 
-	zerolog.SetGlobalLevel(l)
+	log.Logger = zerolog.New(w).With().Timestamp().Logger()
+	zerolog.DefaultContextLogger = &log.Logger
+	return out, nil
+}
 
-	consoleWriter := zerolog.ConsoleWriter{Out: os.Stderr}
-	if customLog != nil {
-		multi := zerolog.MultiLevelWriter(consoleWriter, customLog)
-		log.Logger = zerolog.New(multi).With().Timestamp().Logger()
-	} else {
-		log.Logger = log.Output(os.Stdout)
+// Reopen closes the file, the next line opens it again by the path. It's for logrotate,
+// which has moved the file away, call it on SIGHUP.
+func (o *Output) Reopen() error {
+	if o.file == nil {
+		return nil
 	}
+	return o.file.Close()
+}
 
+// Rotate moves the file to the backup with timestamp in the name and starts the new one.
+func (o *Output) Rotate() error {
+	if o.file == nil {
+		return nil
+	}
+	return o.file.Rotate()
+}
+
+func (o *Output) Close() error {
+	o.once.Do(func() { close(o.stop) })
+	return o.Reopen()
+}
+
+func (o *Output) rotateEvery(d time.Duration) {
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := o.Rotate(); err != nil {
+				log.Error().Err(err).Msg("can't rotate log file")
+			}
+		case <-o.stop:
+			return
+		}
+	}
+}
+
+func checkWritable(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func formatWriter(w io.Writer, format string, colored bool) io.Writer {
+	if format == FormatConsole {
+		return zerolog.ConsoleWriter{Out: w, NoColor: !colored, TimeFormat: time.RFC3339}
+	}
+	return w
+}
+
+func parseLevel(lvl string) zerolog.Level {
+	switch strings.ToLower(lvl) {
+	case "error":
+		return zerolog.ErrorLevel
+	case "warn":
+		return zerolog.WarnLevel
+	case "info":
+		return zerolog.InfoLevel
+	case "debug":
+		return zerolog.DebugLevel
+	case "trace":
+		return zerolog.TraceLevel
+	}
+	return zerolog.InfoLevel
 }
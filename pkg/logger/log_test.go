@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var res []map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		line := map[string]interface{}{}
+		require.NoError(t, dec.Decode(&line))
+		res = append(res, line)
+	}
+	return res
+}
+
+// restoreGlobals puts back the globals changed by Init, so other tests keep their logger.
+func restoreGlobals(t *testing.T) {
+	logger, level, ctxLogger, timeFormat := log.Logger, zerolog.GlobalLevel(), zerolog.DefaultContextLogger, zerolog.TimeFieldFormat
+	t.Cleanup(func() {
+		log.Logger = logger
+		zerolog.SetGlobalLevel(level)
+		zerolog.DefaultContextLogger = ctxLogger
+		zerolog.TimeFieldFormat = timeFormat
+	})
+}
+
+func TestFileRotationAndReopen(t *testing.T) {
+	restoreGlobals(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "main.log")
+	out, err := Init(Config{Level: "trace", File: FileConfig{Path: path}})
+	require.NoError(t, err)
+	defer out.Close()
+
+	log.Trace().Msg("first")
+	lines := readLines(t, path)
+	require.Len(t, lines, 1)
+	require.Equal(t, "trace", lines[0]["level"])
+	require.Equal(t, "first", lines[0]["message"])
+
+	require.NoError(t, out.Rotate())
+	log.Info().Msg("second")
+	require.Len(t, readLines(t, path), 1, "the new file is started")
+	backups, err := filepath.Glob(filepath.Join(dir, "logs", "main-*.log"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	// logrotate moves the file, the process writes to the old one until SIGHUP
+	moved := filepath.Join(dir, "moved.log")
+	require.NoError(t, os.Rename(path, moved))
+	require.NoError(t, out.Reopen())
+	log.Info().Msg("third")
+	require.Len(t, readLines(t, moved), 1)
+	require.Equal(t, "third", readLines(t, path)[0]["message"])
+}
+
+func TestFileWithoutSizeRotation(t *testing.T) {
+	restoreGlobals(t)
+
+	out, err := Init(Config{File: FileConfig{Path: filepath.Join(t.TempDir(), "main.log")}})
+	require.NoError(t, err)
+	defer out.Close()
+	require.Greater(t, out.file.MaxSize, 100, "lumberjack would rotate by 100 MB")
+
+	out, err = Init(Config{File: FileConfig{Path: filepath.Join(t.TempDir(), "main.log"), MaxSize: 5}})
+	require.NoError(t, err)
+	defer out.Close()
+	require.Equal(t, 5, out.file.MaxSize)
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := Init(Config{Format: "xml"})
+	require.Error(t, err)
+	_, err = Init(Config{File: FileConfig{Path: filepath.Join(t.TempDir(), "main.log"), Format: "text"}})
+	require.Error(t, err)
+}
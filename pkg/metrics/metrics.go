@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "orders_api"
+
+// Metrics of the application in Prometheus format. Own registry is used instead of the default one,
+// so metrics of libraries aren't exposed occasionally and tests don't conflict.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	ordersCreated      prometheus.Counter
+	stockOutRejections *prometheus.CounterVec
+	signInFailures     *prometheus.CounterVec
+}
+
+// New registers metrics of HTTP requests, domain events, Go runtime and the process.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by route and status.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"method", "route", "status"}),
+		ordersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_created_total",
+			Help:      "Number of created orders.",
+		}),
+		stockOutRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stock_out_rejections_total",
+			Help:      "Number of operations rejected because of not enough amount in stock.",
+		}, []string{"operation"}),
+		signInFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sign_in_failures_total",
+			Help:      "Number of failed sign in attempts by reason.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.ordersCreated,
+		m.stockOutRejections,
+		m.signInFailures,
+	)
+	return m
+}
+
+// RegisterDB exposes stats of the connection pool as go_sql_* metrics with db_name label.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves metrics in Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest - route is the pattern of path, not the path itself, otherwise identifiers blow up the number of series.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.requestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) OrderCreated() {
+	m.ordersCreated.Inc()
+}
+
+// StockOutRejected - operation is "order" for lines of orders or "adjustment" for write-offs.
+func (m *Metrics) StockOutRejected(operation string) {
+	m.stockOutRejections.WithLabelValues(operation).Inc()
+}
+
+// SignInFailed - reason is "unknown_user" or "invalid_password".
+func (m *Metrics) SignInFailed(reason string) {
+	m.signInFailures.WithLabelValues(reason).Inc()
+}
@@ -15,6 +15,13 @@ func Port(port string) Option {
 	}
 }
 
+// Addr - host and port, e.g. "127.0.0.1:9090".
+func Addr(addr string) Option {
+	return func(s *Server) {
+		s.httpServer.Addr = addr
+	}
+}
+
 // ReadTimeout -.
 func ReadTimeout(timeout time.Duration) Option {
 	return func(s *Server) {
@@ -28,3 +35,54 @@ func WriteTimeout(timeout time.Duration) Option {
 		s.httpServer.WriteTimeout = timeout
 	}
 }
+
+// ReadHeaderTimeout - slow clients don't hold connections, while the headers aren't read.
+func ReadHeaderTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.httpServer.ReadHeaderTimeout = timeout
+	}
+}
+
+// IdleTimeout of keep-alive connections.
+func IdleTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.httpServer.IdleTimeout = timeout
+	}
+}
+
+// TLS serves HTTPS and HTTP/2. Files are loaded again, when they are changed, restart isn't needed.
+func TLS(certFile, keyFile string) Option {
+	return func(s *Server) {
+		s.tls.certFile = certFile
+		s.tls.keyFile = keyFile
+	}
+}
+
+// TLSMinVersion - tls.VersionTLS12 by default, see ParseTLSVersion.
+func TLSMinVersion(version uint16) Option {
+	return func(s *Server) {
+		s.tls.minVersion = version
+	}
+}
+
+// TLSCipherSuites for TLS 1.2 and older, suites of TLS 1.3 aren't configurable. See ParseCipherSuites.
+func TLSCipherSuites(suites ...uint16) Option {
+	return func(s *Server) {
+		s.tls.cipherSuites = suites
+	}
+}
+
+// ClientCA enables mTLS: clients must have the certificate signed by CA from the file.
+func ClientCA(caFile string) Option {
+	return func(s *Server) {
+		s.tls.clientCAFile = caFile
+	}
+}
+
+// H2C serves HTTP/2 without TLS (prior knowledge or upgrade), HTTP/1 is served too.
+// It's ignored with TLS, there HTTP/2 is negotiated.
+func H2C() Option {
+	return func(s *Server) {
+		s.h2c = true
+	}
+}
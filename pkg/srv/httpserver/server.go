@@ -2,33 +2,43 @@ package httpserver
 
 import (
 	"context"
+	"crypto/tls"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"net/http"
 	"time"
 )
 
 const (
-	defaultReadTimeout  = 10 * time.Second
-	defaultWriteTimeout = 10 * time.Second
-	defaultAddr         = ":80"
+	defaultReadTimeout       = 10 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultWriteTimeout      = 10 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
+	defaultAddr              = ":80"
 )
 
 type Server struct {
 	httpServer      *http.Server
 	notify          chan error
 	shutdownTimeout time.Duration
+	tls             tlsOptions
+	h2c             bool
 }
 
 func New(handler http.Handler, opts ...Option) *Server {
 	httpServer := &http.Server{
-		Addr:           defaultAddr,
-		Handler:        handler,
-		MaxHeaderBytes: 1 << 20, // 1 MB
-		ReadTimeout:    defaultReadTimeout,
-		WriteTimeout:   defaultWriteTimeout,
+		Addr:              defaultAddr,
+		Handler:           handler,
+		MaxHeaderBytes:    1 << 20, // 1 MB
+		ReadTimeout:       defaultReadTimeout,
+		ReadHeaderTimeout: defaultReadHeaderTimeout,
+		WriteTimeout:      defaultWriteTimeout,
+		IdleTimeout:       defaultIdleTimeout,
 	}
 	s := &Server{
 		httpServer: httpServer,
 		notify:     make(chan error, 1),
+		tls:        tlsOptions{minVersion: tls.VersionTLS12},
 	}
 
 	// Custom options
@@ -42,12 +52,31 @@ func New(handler http.Handler, opts ...Option) *Server {
 }
 
 func (s *Server) start() {
+	if s.h2c && s.tls.certFile == "" {
+		// HTTP/2 without TLS, for the internal traffic, e.g. between services in the cluster
+		s.httpServer.Handler = h2c.NewHandler(s.httpServer.Handler, &http2.Server{IdleTimeout: s.httpServer.IdleTimeout})
+	}
+
 	go func() {
-		s.notify <- s.httpServer.ListenAndServe()
+		s.notify <- s.serve()
 		close(s.notify)
 	}()
 }
 
+func (s *Server) serve() error {
+	if s.tls.certFile == "" {
+		return s.httpServer.ListenAndServe()
+	}
+
+	tlsConfig, err := s.tls.config()
+	if err != nil {
+		return err
+	}
+	// HTTP/2 is negotiated by ALPN, net/http adds "h2" to the config
+	s.httpServer.TLSConfig = tlsConfig
+	return s.httpServer.ListenAndServeTLS("", "")
+}
+
 func (s *Server) Notify() <-chan error {
 	return s.notify
 }
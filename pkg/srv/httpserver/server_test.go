@@ -0,0 +1,98 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func protoHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, r.Proto)
+}
+
+// startServer on the free port of localhost, it's stopped after the test.
+func startServer(t *testing.T, opts ...Option) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	s := New(http.HandlerFunc(protoHandler), append(opts, Addr(addr))...)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = s.Shutdown(ctx)
+	})
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+	return addr
+}
+
+func get(client *http.Client, url string) (string, error) {
+	res, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	buf := make([]byte, 64)
+	n, _ := res.Body.Read(buf)
+	return string(buf[:n]), nil
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	certFile, keyFile := newTestCert(t, "server", ca).writeFiles(t, dir, time.Now())
+	caFile, _ := ca.writeFiles(t, t.TempDir(), time.Now())
+
+	addr := startServer(t, TLS(certFile, keyFile), ClientCA(caFile), TLSMinVersion(tls.VersionTLS13))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs, ServerName: "localhost"},
+			ForceAttemptHTTP2: true,
+		}}
+	}
+
+	_, err := get(client(), "https://"+addr)
+	require.Error(t, err, "the client without certificate is rejected")
+
+	proto, err := get(client(newTestCert(t, "client", ca).tlsCertificate(t)), "https://"+addr)
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/2.0", proto)
+}
+
+func TestServerH2C(t *testing.T) {
+	addr := startServer(t, H2C())
+
+	// prior knowledge: HTTP/2 frames over the plain connection
+	h2 := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	proto, err := get(h2, "http://"+addr)
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/2.0", proto)
+
+	proto, err = get(http.DefaultClient, "http://"+addr)
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/1.1", proto)
+}
@@ -0,0 +1,154 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// certCheckInterval - files of the certificate are checked for changes not often than this on handshakes.
+const certCheckInterval = 10 * time.Second
+
+type tlsOptions struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	minVersion   uint16
+	cipherSuites []uint16
+}
+
+func (o tlsOptions) config() (*tls.Config, error) {
+	reloader, err := newCertReloader(o.certFile, o.keyFile, certCheckInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:     o.minVersion,
+		CipherSuites:   o.cipherSuites,
+		GetCertificate: reloader.getCertificate,
+	}
+
+	if o.clientCAFile != "" {
+		pem, err := os.ReadFile(o.clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in client CA %s", o.clientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// certReloader loads the certificate again, when its files are changed, e.g. renewed by cert-manager or certbot.
+// The broken new files are ignored, the previous certificate is served until they are fixed.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) >= r.interval {
+		r.checkedAt = time.Now()
+		if modTime, err := r.lastModified(); err == nil && !modTime.Equal(r.modTime) {
+			// the previous certificate is served, the files are loaded again on the next check
+			_ = r.reload()
+		}
+	}
+	return r.cert, nil
+}
+
+// reload is called under the lock or before the reloader is shared.
+func (r *certReloader) reload() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// lastModified of both files, they are replaced one by one.
+func (r *certReloader) lastModified() (time.Time, error) {
+	var res time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(res) {
+			res = info.ModTime()
+		}
+	}
+	return res, nil
+}
+
+// ParseTLSVersion - "1.2" or "1.3", older versions are deprecated (RFC 8996) and aren't allowed.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0", "1.1":
+		return 0, fmt.Errorf("TLS version %q is insecure, use 1.2 or 1.3", version)
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version %q", version)
+}
+
+// ParseCipherSuites - comma separated names, e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256". Insecure suites aren't allowed.
+func ParseCipherSuites(names string) ([]uint16, error) {
+	known := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	var res []uint16
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		res = append(res, id)
+	}
+	if len(res) == 0 {
+		return nil, errors.New("no cipher suites")
+	}
+	return res, nil
+}
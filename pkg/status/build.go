@@ -0,0 +1,38 @@
+package status
+
+import (
+	"runtime/debug"
+)
+
+// Build information is injected on build:
+//
+//	go build -ldflags "-X github.com/linkuha/test-golang-rest-orders-api/pkg/status.Version=1.2.0 \
+//		-X github.com/linkuha/test-golang-rest-orders-api/pkg/status.Commit=$(git rev-parse HEAD) \
+//		-X github.com/linkuha/test-golang-rest-orders-api/pkg/status.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// getBuildInfo - without ldflags the commit and time are taken from VCS stamp of go build, if there is one.
+func getBuildInfo() buildInfo {
+	res := buildInfo{Version: Version, Commit: Commit, BuildTime: BuildTime}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && res.Commit == "":
+				res.Commit = s.Value
+			case s.Key == "vcs.time" && res.BuildTime == "":
+				res.BuildTime = s.Value
+			}
+		}
+	}
+	return res
+}
@@ -18,6 +18,7 @@ type StatusInfo struct {
 	NumCgoCall   int64
 	GoVersion    string
 	Server       serverInfo
+	Build        buildInfo
 }
 
 type appMemoryUsage struct {
@@ -62,6 +63,7 @@ func GetStatusInfo() StatusInfo {
 			ServerStart: startTimeString,
 			Uptime:      time.Now().Sub(startTime).String(),
 		},
+		Build: getBuildInfo(),
 	}
 }
 
@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("object is not found")
+
+// Storage of binary objects (images, attachments) addressed by key like "products/<id>/<name>.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL returns public address of the object.
+	URL(key string) string
+}
@@ -0,0 +1,98 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+const jpegQuality = 85
+
+// MaxPixels - width x height of the largest image, which is decoded. A small file may declare a huge size,
+// the decoded image would take gigabytes of memory.
+const MaxPixels = 25_000_000
+
+var ErrTooLarge = errors.New("image is too large")
+
+// Make decodes JPEG, PNG or GIF image and returns JPEG thumbnail, which fits into maxSide x maxSide box.
+// Images that are smaller than the box are not upscaled, images larger than MaxPixels are refused with ErrTooLarge.
+func Make(r io.Reader, maxSide int) ([]byte, error) {
+	// the header is read twice: to check the size and to decode
+	var header bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels, max %d", ErrTooLarge, cfg.Width, cfg.Height, MaxPixels)
+	}
+
+	src, _, err := image.Decode(io.MultiReader(&header, r))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			w, h = maxSide, max(1, h*maxSide/w)
+		} else {
+			w, h = max(1, w*maxSide/h), maxSide
+		}
+	}
+
+	dst := resize(src, w, h)
+
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize scales image by averaging all source pixels covered by each destination pixel (box filter).
+func resize(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := b.Min.Y + max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := b.Min.X + max((x+1)*sw/w, x*sw/w+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			// colors are alpha-premultiplied, so transparent areas are blended on white background
+			r, g, bl, a = r/n, g/n, bl/n, a/n
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8((r + 0xffff - a) >> 8)
+			dst.Pix[i+1] = uint8((g + 0xffff - a) >> 8)
+			dst.Pix[i+2] = uint8((bl + 0xffff - a) >> 8)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
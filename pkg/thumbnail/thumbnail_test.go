@@ -0,0 +1,44 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 640, 320))
+	for i := range src.Pix {
+		src.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+
+	thumb, err := Make(&buf, 160)
+	require.NoError(t, err)
+
+	res, err := jpeg.Decode(bytes.NewReader(thumb))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 160, 80), res.Bounds())
+}
+
+func TestMakeDecompressionBomb(t *testing.T) {
+	// 1x1 GIF, which declares 60000x60000 logical screen
+	var buf bytes.Buffer
+	img := image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.White})
+	require.NoError(t, gif.Encode(&buf, img, nil))
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint16(data[6:], 60000)
+	binary.LittleEndian.PutUint16(data[8:], 60000)
+
+	_, err := Make(bytes.NewReader(data), 160)
+	assert.True(t, errors.Is(err, ErrTooLarge), err)
+}
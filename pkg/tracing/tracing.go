@@ -0,0 +1,131 @@
+// Package tracing sets up OpenTelemetry: the tracer provider with exporter and W3C propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const defaultServiceName = "orders-api"
+
+type Config struct {
+	// Exporter - ExporterNone (or empty), ExporterStdout or ExporterOTLP.
+	Exporter string
+	// Endpoint of OTLP/HTTP collector, e.g. http://localhost:4318, traces are sent to its /v1/traces.
+	// The exporter's default (https://localhost:4318) is used if empty.
+	Endpoint    string
+	ServiceName string
+	Version     string
+	// SampleRatio of traces started here, all of them if it's not in (0, 1).
+	// The decision of the caller from traceparent is respected anyway.
+	SampleRatio float64
+}
+
+// Init installs the global tracer provider and propagator. Spans aren't recorded, if the exporter is disabled,
+// but traceparent is still passed through. Shutdown flushes the spans left.
+func Init(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := newExporter(ctx, cfg, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	tp := NewProvider(cfg, exporter)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// NewProvider batches spans into the exporter.
+func NewProvider(cfg Config, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	name := cfg.ServiceName
+	if name == "" {
+		name = defaultServiceName
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(name),
+		semconv.ServiceVersion(cfg.Version),
+	)
+
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	)
+}
+
+func newExporter(ctx context.Context, cfg Config, stdout io.Writer) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(cfg.Exporter) {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(stdout), stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		opts, err := otlpOptions(cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("unknown traces exporter %q", cfg.Exporter)
+}
+
+func otlpOptions(endpoint string) ([]otlptracehttp.Option, error) {
+	if endpoint == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("bad OTLP endpoint %q, expected URL like http://localhost:4318", endpoint)
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(u.Path, "/") + "/v1/traces"),
+	}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return opts, nil
+}
+
+// Tracer of the application, it follows the global provider, even if it's installed later.
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/linkuha/test-golang-rest-orders-api")
+}
+
+// End records the error into the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}